@@ -1,28 +1,45 @@
 package main
 
 import (
+	"archive/zip"
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/tabwriter"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/term"
+	"gopkg.in/yaml.v2"
 
+	"github.com/dsablic/codemium/internal/activity"
+	"github.com/dsablic/codemium/internal/aidetect"
 	"github.com/dsablic/codemium/internal/aiestimate"
 	"github.com/dsablic/codemium/internal/analyzer"
 	"github.com/dsablic/codemium/internal/auth"
 	"github.com/dsablic/codemium/internal/churn"
+	"github.com/dsablic/codemium/internal/coauthor"
+	"github.com/dsablic/codemium/internal/complexity"
+	"github.com/dsablic/codemium/internal/conventional"
 	"github.com/dsablic/codemium/internal/health"
 	"github.com/dsablic/codemium/internal/history"
 	"github.com/dsablic/codemium/internal/license"
@@ -30,6 +47,7 @@ import (
 	"github.com/dsablic/codemium/internal/narrative"
 	"github.com/dsablic/codemium/internal/output"
 	"github.com/dsablic/codemium/internal/provider"
+	"github.com/dsablic/codemium/internal/serve"
 	"github.com/dsablic/codemium/internal/ui"
 	"github.com/dsablic/codemium/internal/worker"
 )
@@ -42,9 +60,9 @@ var (
 
 // errorEntry represents a diagnostic error for the error log.
 type errorEntry struct {
-	Category string
-	Repo     string
-	Message  string
+	Category string `json:"category"`
+	Repo     string `json:"repo"`
+	Message  string `json:"message"`
 }
 
 func main() {
@@ -58,6 +76,8 @@ func main() {
 	root.AddCommand(newAnalyzeCmd())
 	root.AddCommand(newMarkdownCmd())
 	root.AddCommand(newTrendsCmd())
+	root.AddCommand(newServeCmd())
+	root.AddCommand(newProvidersCmd())
 
 	if err := root.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -79,7 +99,15 @@ func newAuthCmd() *cobra.Command {
 	loginCmd.Flags().String("provider", "", "Provider to authenticate with (bitbucket, github, gitlab)")
 	loginCmd.MarkFlagRequired("provider")
 
-	cmd.AddCommand(loginCmd)
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check which credentials are available for a provider and what is missing",
+		RunE:  runAuthDoctor,
+	}
+	doctorCmd.Flags().String("provider", "", "Provider to check (bitbucket, github, gitlab, azure)")
+	doctorCmd.MarkFlagRequired("provider")
+
+	cmd.AddCommand(loginCmd, doctorCmd)
 	return cmd
 }
 
@@ -119,6 +147,9 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 	case "gitlab":
 		cred, err = loginGitLabPAT()
 
+	case "azure":
+		return fmt.Errorf("azure has no login flow; set CODEMIUM_AZURE_TOKEN to a personal access token with the Code (Read) scope")
+
 	default:
 		return fmt.Errorf("unsupported provider: %s (use bitbucket, github, or gitlab)", providerName)
 	}
@@ -135,22 +166,274 @@ func runAuthLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func newProvidersCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "providers",
+		Short: "List supported providers and the capabilities each implements",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeProviderCapabilities(os.Stdout)
+		},
+	}
+}
+
+// providerCapability is one column of the providers table: a capability,
+// how a provider is tested for it, and the flags that need it.
+type providerCapability struct {
+	name  string
+	has   func(prov provider.Provider, oauth bool) bool
+	flags string
+}
+
+var providerCapabilities = []providerCapability{
+	{"list", func(provider.Provider, bool) bool { return true }, "analyze, trends"},
+	{"projects", func(p provider.Provider, _ bool) bool { _, ok := p.(provider.ProjectLister); return ok }, "interactive project picker"},
+	{"commits", func(p provider.Provider, _ bool) bool { _, ok := p.(provider.CommitLister); return ok }, "--ai-estimate, --health, --conventional-commits, --activity-heatmap, --co-authorship"},
+	{"commit-stats", hasCommitStats, "--ai-estimate additions, --health-details churn"},
+	{"churn", func(p provider.Provider, _ bool) bool { _, ok := p.(provider.ChurnLister); return ok }, "--churn files and hotspots, --code-ownership"},
+	{"issues", func(p provider.Provider, _ bool) bool { _, ok := p.(provider.IssueCounter); return ok }, "--issues"},
+	{"oauth", func(_ provider.Provider, oauth bool) bool { return oauth }, "auth login browser/device flow"},
+}
+
+// hasCommitStats reports whether p returns per-commit line counts. Azure
+// DevOps implements CommitStats but its API has no line counts, so it always
+// reports 0/0.
+func hasCommitStats(p provider.Provider, _ bool) bool {
+	if _, azure := p.(*provider.AzureDevOps); azure {
+		return false
+	}
+	_, ok := p.(provider.CommitLister)
+	return ok
+}
+
+// writeProviderCapabilities prints which capabilities each provider
+// implements, checked against the provider interfaces so the table can't
+// drift from the code.
+func writeProviderCapabilities(w io.Writer) error {
+	providers := []struct {
+		name  string
+		prov  provider.Provider
+		oauth bool
+	}{
+		{"bitbucket", provider.NewBitbucket("", "", "", nil), true},
+		{"bitbucket (server)", provider.NewBitbucketServer("", "", "https://bitbucket.example.com", nil), false},
+		{"github", provider.NewGitHub("", "", nil), true},
+		{"gitlab", provider.NewGitLab("", "", nil), false},
+		{"azure", provider.NewAzureDevOps("", "", "", nil), false},
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "PROVIDER")
+	for _, c := range providerCapabilities {
+		fmt.Fprintf(tw, "\t%s", strings.ToUpper(c.name))
+	}
+	fmt.Fprintln(tw)
+	for _, p := range providers {
+		fmt.Fprint(tw, p.name)
+		for _, c := range providerCapabilities {
+			mark := "-"
+			if c.has(p.prov, p.oauth) {
+				mark = "yes"
+			}
+			fmt.Fprintf(tw, "\t%s", mark)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	for _, c := range providerCapabilities {
+		fmt.Fprintf(w, "%-13s %s\n", c.name+":", c.flags)
+	}
+	fmt.Fprintln(w, "\nBitbucket Server is used when CODEMIUM_BITBUCKET_URL points at a self-hosted instance.")
+	fmt.Fprintln(w, "Azure DevOps reports no line counts: AI additions are unavailable and churn counts changes, not lines.")
+	return nil
+}
+
+func runAuthDoctor(cmd *cobra.Command, args []string) error {
+	providerName, _ := cmd.Flags().GetString("provider")
+	store := auth.NewFileStore(auth.DefaultStorePath())
+	return authDoctor(os.Stdout, providerName, store, time.Now())
+}
+
+// authDoctor reports the credential sources analyze would try for
+// providerName, in the same order as FileStore.LoadWithEnv (env token, stored
+// credential, gh/glab CLI), and what each login path still needs. It returns
+// an error when no source would yield a usable credential.
+func authDoctor(w io.Writer, providerName string, store *auth.FileStore, now time.Time) error {
+	upper := strings.ToUpper(providerName)
+	tokenVar := "CODEMIUM_" + upper + "_TOKEN"
+	userVar := "CODEMIUM_" + upper + "_USERNAME"
+
+	var oauthVars []string
+	var cli string
+	switch providerName {
+	case "bitbucket":
+		oauthVars = []string{"CODEMIUM_BITBUCKET_CLIENT_ID", "CODEMIUM_BITBUCKET_CLIENT_SECRET"}
+	case "github":
+		oauthVars = []string{"CODEMIUM_GITHUB_CLIENT_ID"}
+		cli = "gh"
+	case "gitlab":
+		cli = "glab"
+	case "azure":
+		// Azure DevOps has no login flow or CLI fallback, only a PAT.
+	default:
+		return fmt.Errorf("unsupported provider: %s (use bitbucket, github, gitlab, or azure)", providerName)
+	}
+
+	check := func(ok bool, format string, a ...any) {
+		mark := "[ok]     "
+		if !ok {
+			mark = "[missing]"
+		}
+		fmt.Fprintf(w, "  %s %s\n", mark, fmt.Sprintf(format, a...))
+	}
+	isSet := func(name string) bool { return os.Getenv(name) != "" }
+
+	fmt.Fprintf(w, "Provider: %s\n\nEnvironment:\n", providerName)
+	vars := []string{tokenVar}
+	if providerName == "bitbucket" {
+		vars = append(vars, userVar)
+	}
+	vars = append(vars, oauthVars...)
+	for _, name := range vars {
+		check(isSet(name), "%s", name)
+	}
+	if urlVar := "CODEMIUM_" + upper + "_URL"; providerName != "github" && isSet(urlVar) {
+		fmt.Fprintf(w, "  [info]    %s=%s\n", urlVar, os.Getenv(urlVar))
+	} else if providerName == "azure" {
+		fmt.Fprintf(w, "  [info]    %s not set, using https://dev.azure.com\n", urlVar)
+	}
+
+	fmt.Fprintln(w, "\nStored credential:")
+	cred, err := store.Load(providerName)
+	stored := err == nil
+	var missingOAuth []string
+	for _, name := range oauthVars {
+		if !isSet(name) {
+			missingOAuth = append(missingOAuth, name)
+		}
+	}
+	switch {
+	case !stored:
+		check(false, "none saved")
+	case !cred.ExpiresAt.IsZero() && now.After(cred.ExpiresAt):
+		if cred.RefreshToken != "" && len(missingOAuth) == 0 {
+			check(true, "expired %s, will be refreshed on the next run", cred.ExpiresAt.Format(time.RFC3339))
+		} else if cred.RefreshToken != "" {
+			check(false, "expired %s; refreshing it needs %s", cred.ExpiresAt.Format(time.RFC3339), strings.Join(missingOAuth, " and "))
+			stored = false
+		} else {
+			check(false, "expired %s and has no refresh token", cred.ExpiresAt.Format(time.RFC3339))
+			stored = false
+		}
+	case cred.ExpiresAt.IsZero():
+		check(true, "saved, no expiry")
+	default:
+		check(true, "saved, expires %s", cred.ExpiresAt.Format(time.RFC3339))
+	}
+
+	cliToken := false
+	if cli != "" {
+		fmt.Fprintln(w, "\nCLI fallback:")
+		if _, err := exec.LookPath(cli); err != nil {
+			check(false, "%s CLI not found on PATH", cli)
+		} else {
+			var ok bool
+			if cli == "gh" {
+				_, ok = auth.GhCLIToken()
+			} else {
+				_, ok = auth.GlabCLIToken()
+			}
+			cliToken = ok
+			if ok {
+				check(true, "%s CLI found, token available", cli)
+			} else {
+				check(false, "%s CLI found, but it returned no token", cli)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\nLogin paths:")
+	login := fmt.Sprintf("codemium auth login --provider %s", providerName)
+	if len(oauthVars) > 0 {
+		if len(missingOAuth) == 0 {
+			fmt.Fprintf(w, "  OAuth: ready, run '%s'\n", login)
+		} else {
+			fmt.Fprintf(w, "  OAuth: set %s, then run '%s'\n", strings.Join(missingOAuth, " and "), login)
+		}
+	}
+	switch providerName {
+	case "bitbucket":
+		if isSet(tokenVar) && isSet(userVar) {
+			fmt.Fprintln(w, "  Token: ready")
+		} else {
+			fmt.Fprintf(w, "  Token: set %s and %s, or run '%s' without OAuth variables to save an API token\n", tokenVar, userVar, login)
+		}
+	case "github":
+		if isSet(tokenVar) || cliToken {
+			fmt.Fprintln(w, "  Token: ready")
+		} else {
+			fmt.Fprintf(w, "  Token: set %s, or install the gh CLI and run 'gh auth login'\n", tokenVar)
+		}
+	case "gitlab":
+		if isSet(tokenVar) || cliToken {
+			fmt.Fprintln(w, "  Token: ready")
+		} else {
+			fmt.Fprintf(w, "  Token: set %s, run '%s' to save a personal access token, or run 'glab auth login'\n", tokenVar, login)
+		}
+	case "azure":
+		if isSet(tokenVar) {
+			fmt.Fprintln(w, "  Token: ready")
+		} else {
+			fmt.Fprintf(w, "  Token: set %s to a personal access token with the Code (Read) scope (there is no 'auth login' for azure)\n", tokenVar)
+		}
+	}
+
+	var source string
+	switch {
+	case isSet(tokenVar):
+		source = tokenVar
+	case stored:
+		source = "stored credential"
+	case cliToken:
+		source = cli + " CLI"
+	default:
+		return fmt.Errorf("no usable %s credentials", providerName)
+	}
+	fmt.Fprintf(w, "\nanalyze will use: %s\n", source)
+	return nil
+}
+
 func loginBitbucketAPIToken() (auth.Credentials, error) {
-	fmt.Fprintln(os.Stderr, "Bitbucket API token login")
-	fmt.Fprintln(os.Stderr, "Create a scoped token at: https://id.atlassian.com/manage-profile/security/api-tokens")
-	fmt.Fprintln(os.Stderr, "  -> 'Create API token with scopes' -> Bitbucket -> Repository Read, Project Read")
+	serverURL := os.Getenv("CODEMIUM_BITBUCKET_URL")
+	server := provider.IsBitbucketServerURL(serverURL)
+
+	userLabel := "Email"
+	verifyURL := "https://api.bitbucket.org/2.0/user"
+	if server {
+		fmt.Fprintln(os.Stderr, "Bitbucket Server HTTP access token login")
+		fmt.Fprintln(os.Stderr, "Create a token under Manage account -> HTTP access tokens with Project read and Repository read")
+		userLabel = "Username"
+		verifyURL = strings.TrimRight(serverURL, "/") + "/rest/api/1.0/projects?limit=1"
+	} else {
+		fmt.Fprintln(os.Stderr, "Bitbucket API token login")
+		fmt.Fprintln(os.Stderr, "Create a scoped token at: https://id.atlassian.com/manage-profile/security/api-tokens")
+		fmt.Fprintln(os.Stderr, "  -> 'Create API token with scopes' -> Bitbucket -> Repository Read, Project Read")
+	}
 	fmt.Fprintln(os.Stderr)
 
 	reader := bufio.NewReader(os.Stdin)
 
-	fmt.Fprint(os.Stderr, "Email: ")
+	fmt.Fprintf(os.Stderr, "%s: ", userLabel)
 	username, err := reader.ReadString('\n')
 	if err != nil {
-		return auth.Credentials{}, fmt.Errorf("read email: %w", err)
+		return auth.Credentials{}, fmt.Errorf("read %s: %w", strings.ToLower(userLabel), err)
 	}
 	username = strings.TrimSpace(username)
 	if username == "" {
-		return auth.Credentials{}, fmt.Errorf("email is required")
+		return auth.Credentials{}, fmt.Errorf("%s is required", strings.ToLower(userLabel))
 	}
 
 	fmt.Fprint(os.Stderr, "API token: ")
@@ -164,8 +447,8 @@ func loginBitbucketAPIToken() (auth.Credentials, error) {
 		return auth.Credentials{}, fmt.Errorf("API token is required")
 	}
 
-	// Verify credentials by calling the Bitbucket user API
-	req, err := http.NewRequest(http.MethodGet, "https://api.bitbucket.org/2.0/user", nil)
+	// Verify credentials by calling the Bitbucket user (or Server projects) API
+	req, err := http.NewRequest(http.MethodGet, verifyURL, nil)
 	if err != nil {
 		return auth.Credentials{}, err
 	}
@@ -178,7 +461,7 @@ func loginBitbucketAPIToken() (auth.Credentials, error) {
 	resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return auth.Credentials{}, fmt.Errorf("invalid email or API token")
+		return auth.Credentials{}, fmt.Errorf("invalid %s or API token", strings.ToLower(userLabel))
 	}
 	if resp.StatusCode != http.StatusOK {
 		return auth.Credentials{}, fmt.Errorf("bitbucket API returned status %d", resp.StatusCode)
@@ -254,218 +537,928 @@ func newAnalyzeCmd() *cobra.Command {
 		RunE:  runAnalyze,
 	}
 
-	cmd.Flags().String("provider", "", "Provider (bitbucket, github, gitlab)")
-	cmd.Flags().String("workspace", "", "Bitbucket workspace slug")
-	cmd.Flags().String("org", "", "GitHub organization")
+	cmd.Flags().String("provider", "", "Provider (bitbucket, github, gitlab, azure, or all to analyze every target in --targets)")
+	cmd.Flags().StringSlice("workspace", nil, "Bitbucket workspace slug (repeatable; several are combined into one report)")
+	cmd.Flags().String("org", "", "GitHub organization, or Azure DevOps organization")
 	cmd.Flags().String("user", "", "GitHub user (alternative to --org for personal repos)")
-	cmd.Flags().String("group", "", "GitLab group path or ID")
-	cmd.Flags().StringSlice("projects", nil, "Filter by Bitbucket project keys")
+	cmd.Flags().String("github-url", "", "GitHub Enterprise Server URL, e.g. https://github.mycorp.com (env: CODEMIUM_GITHUB_URL; default: github.com)")
+	cmd.Flags().StringSlice("group", nil, "GitLab group path or ID (repeatable; several are combined into one report)")
+	cmd.Flags().String("project", "", "Azure DevOps project (default: every project in --org)")
+	cmd.Flags().String("targets", "", "YAML file listing provider targets to analyze and merge into one report (with --provider all)")
+	cmd.Flags().StringSlice("projects", nil, "Filter by Bitbucket project keys, or fetch GitLab projects by full path (group/sub/project) without listing a group")
 	cmd.Flags().StringSlice("repos", nil, "Filter to specific repo names")
+	cmd.Flags().Bool("strict-repos", false, "Fail when a --repos entry matches no listed repository instead of only warning")
 	cmd.Flags().StringSlice("exclude", nil, "Exclude specific repos")
+	cmd.Flags().StringSlice("exclude-project", nil, "Exclude repos whose Bitbucket project key or GitLab namespace matches (glob patterns)")
 	cmd.Flags().Bool("include-archived", false, "Include archived repos")
+	cmd.Flags().Bool("only-archived", false, "Only include archived repos (GitHub, GitLab and Bitbucket Server)")
+	cmd.Flags().String("bitbucket-role", "", "Bitbucket Cloud only: list just the repos the token has at least this role on (member, contributor, admin or owner)")
 	cmd.Flags().Bool("include-forks", false, "Include forked repos")
-	cmd.Flags().Int("concurrency", 5, "Number of parallel workers")
-	cmd.Flags().String("output", "output/report.json", "Write JSON to file")
+	cmd.Flags().Int("recent", 0, "Only analyze the N most recently pushed repos, by the listing's last-activity time (0 = all)")
+	cmd.Flags().Bool("at-latest-tag", false, "Analyze each repo at its highest semver release tag instead of the default branch, falling back to HEAD without one (uses a full clone)")
+	cmd.Flags().Bool("fork-diff-only", false, "For forks, only count files changed since diverging from the parent repo (implies --include-forks; uses a full clone)")
+	cmd.Flags().String("on-error", "skip", "How to handle repo failures: fail-fast (abort on the first error), skip (record and continue), or retry (retry with backoff, then record)")
+	cmd.Flags().Bool("skip-failed-pages", false, "Skip repository listing pages that fail twice instead of aborting (recorded in the error log)")
+	cmd.Flags().Int("concurrency", 0, "Number of parallel clone/analysis workers (0 = auto: 5)")
+	cmd.Flags().Int("api-concurrency", 0, "Number of parallel workers for API phases like --health and --ai-estimate (0 = same as --concurrency)")
+	cmd.Flags().String("output", "output/report.json", "Write JSON to file (supports {date}, {provider}, {org}, {workspace} placeholders)")
+	cmd.Flags().Bool("compact-json", false, "Write the JSON report without indentation")
+	cmd.Flags().String("markdown-out", "", "Also write the markdown rendering of the report to this file (supports the same placeholders as --output)")
+	cmd.Flags().String("output-format", "json", "Output format: json, or zip to bundle report.json, report.md and errors.json into one archive at --output (with a .zip extension)")
+	cmd.Flags().Bool("summary-only", false, "Leave the per-repository array out of the JSON report, keeping totals and aggregates")
+	cmd.Flags().Bool("stream-output", false, "Write each repository to the JSON output as soon as it is analyzed, so an interrupted run keeps finished repos (not with API phases like --health)")
+	cmd.Flags().Bool("anonymize", false, "Replace author names and emails with pseudonyms that are stable within the run")
+	cmd.Flags().Bool("redact-urls", false, "Replace repository URLs with the repo slug so reports don't reveal hostnames or group paths")
+	cmd.Flags().String("generated-at", "", "Override the report timestamp (RFC 3339, e.g. 2026-01-01T00:00:00Z; env: CODEMIUM_NOW)")
 	cmd.Flags().Bool("ai-estimate", false, "Estimate AI-written code percentage")
-	cmd.Flags().Int("ai-commit-limit", 500, "Max commits to scan per repo for AI estimation (0 = unlimited)")
+	cmd.Flags().Bool("ai-case-sensitive", false, "Match AI tool names and commit message patterns only in their canonical capitalization, e.g. Claude (default ignores case)")
+	cmd.Flags().String("ai-signals-file", "", "YAML/JSON file of custom AI detection rules (co-author emails, bot author and message patterns), reported as custom:<name> signals")
+	cmd.Flags().Int("ai-commit-limit", 500, "Max commits to scan per repo for AI estimation and --conventional-commits (0 = unlimited)")
+	cmd.Flags().Bool("conventional-commits", false, "Compute the percentage of commits following Conventional Commits per repo")
+	cmd.Flags().Bool("commit-counts", false, "Count commits per repo, up to --ai-commit-limit (a lightweight activity proxy)")
+	cmd.Flags().Bool("activity-heatmap", false, "Histogram commits by day of week and hour from the commits scanned per repo (see --ai-commit-limit)")
+	cmd.Flags().Bool("co-authorship", false, "Count commits shared by author pairs from Co-authored-by trailers")
 	cmd.Flags().Bool("health", false, "Classify repos by activity (active/maintained/abandoned)")
+	cmd.Flags().Bool("health-cheap", false, "Classify health from the listing's last-activity timestamp, listing commits only when it is missing (implies --health)")
 	cmd.Flags().Bool("health-details", false, "Deep health analysis: authors, churn, velocity per window (implies --health)")
+	cmd.Flags().IntSlice("health-windows", health.DefaultWindows, "Comma-separated month boundaries of the health details windows, e.g. 3,6,12 for 0-3/3-6/6-12/12+ months")
+	cmd.Flags().Float64("velocity-band", health.DefaultVelocityBand, "Tolerance around 1.0 within which the health details velocity trend is labeled steady")
+	cmd.Flags().Int("health-active-days", health.ActiveThresholdDays, "Days since the last commit below which a repo is active")
+	cmd.Flags().Int("health-maintained-days", health.MaintainedThresholdDays, "Days since the last commit below which a repo is maintained rather than abandoned")
+	cmd.Flags().String("author-map", "", "Path to a .mailmap-format file unifying author email aliases for health details, co-authorship and code ownership")
 	cmd.Flags().Int("health-commit-limit", 500, "Max commits to scan per repo for health details (0 = unlimited)")
 	cmd.Flags().Bool("churn", false, "Analyze code churn and hotspots")
 	cmd.Flags().Int("churn-limit", 500, "Max commits to scan per repo for churn analysis (0 = unlimited)")
+	cmd.Flags().String("churn-since", "", "Only count churn from commits on or after this date (YYYY-MM-DD, implies --churn)")
+	cmd.Flags().Bool("code-ownership", false, "Estimate the dominant author of each top churn file (implies --churn)")
+	cmd.Flags().Bool("issues", false, "Count open issues per repo")
+	cmd.Flags().Bool("large-files", false, "List files at or above --large-file-size per repo (Git LFS candidates)")
+	cmd.Flags().Int("large-file-size", 10, "Size threshold in MB for --large-files")
+	cmd.Flags().Int64("min-code", 0, "Leave repositories with fewer lines of code out of the report (counted in totals.filtered_repos)")
+	cmd.Flags().Bool("all-languages", false, "Keep languages with no code (only comments/blanks, or data formats) in the by-language totals")
+	cmd.Flags().Bool("trace-skips", false, fmt.Sprintf("Record up to %d skipped paths per repo (vendored, generated, binary, unknown language) in the report and error log", analyzer.MaxTracedSkips))
+	cmd.Flags().Bool("split-tests", false, "Count test files (_test.go, *.spec.ts, test/, spec/, ...) separately from production code totals")
+	cmd.Flags().StringSlice("complexity-threshold", nil, "Flag repos (and churn hotspot files) above N complexity, or a language within a repo with Language=N (e.g. 500,Go=300)")
 	cmd.Flags().Float64("rate-limit", 0, "Max API requests per second (0 = unlimited)")
+	cmd.Flags().Duration("http-timeout", provider.DefaultHTTPTimeout, "Deadline for each provider API request attempt, including reading the response (0 = none)")
+	cmd.Flags().String("keep-clones", "", "Clone into <dir>/<host>/<owner>/<repo> and keep the working trees after analysis")
+	cmd.Flags().String("clone-cache", "", "Keep bare clones in <dir> across runs and fetch into them instead of cloning again")
+	cmd.Flags().String("language-override", "", "File of \"pattern = Language\" lines (.ext or file name) overriding scc's language detection")
+	cmd.Flags().StringSlice("doc-extensions", nil, "File extensions to count as the Documentation pseudo-language (e.g. .mdx,.adoc,.md.tmpl)")
+	cmd.Flags().StringArray("exclude-path", nil, "Leave files matching this glob out of the counts (repeatable; ** matches any directories, e.g. **/*_test.go, docs/**, *.min.js)")
+	cmd.Flags().StringSlice("languages", nil, "Count only files in these languages (scc names, case-insensitive, e.g. Go,TypeScript)")
+	cmd.Flags().StringSlice("exclude-languages", nil, "Leave files in these languages out of the counts (e.g. JSON,Markdown)")
+	cmd.Flags().Bool("ignore-repo-config", false, "Ignore each repository's own .codemium.yaml (area, exclude_paths, ignore_languages)")
+	cmd.Flags().Bool("no-ignore-files", false, "Count files matched by the repository's .gitignore files and .codemiumignore")
+	cmd.Flags().String("changed-since", "", "Only count files changed on the default branch since this ref (branch or commit; uses a full clone)")
+	cmd.Flags().Bool("include-submodules", false, "Initialize and update git submodules after cloning so their code is counted")
+	cmd.Flags().Bool("cache-analysis", false, "Reuse the stats of repos whose default-branch HEAD is unchanged since a previous run with the same analysis flags")
+	cmd.Flags().String("analysis-cache-dir", "", "Directory for --cache-analysis entries (default: codemium/analysis under the user cache directory)")
 
 	cmd.MarkFlagRequired("provider")
 
 	return cmd
 }
 
+// analyzeTarget is one provider and scope to analyze. A single run comes
+// from the command-line flags; --provider all reads a list of them from the
+// --targets file.
+type analyzeTarget struct {
+	Provider        string     `yaml:"provider"`
+	Workspace       stringList `yaml:"workspace"`
+	Org             string     `yaml:"org"`
+	User            string     `yaml:"user"`
+	Group           stringList `yaml:"group"`
+	Project         string     `yaml:"project"`
+	Projects        []string   `yaml:"projects"`
+	Repos           []string   `yaml:"repos"`
+	Exclude         []string   `yaml:"exclude"`
+	ExcludeProjects []string   `yaml:"exclude_projects"`
+
+	// interactive allows the Bitbucket project picker when no projects are set.
+	interactive bool
+	// onRepo, when set, receives each repository's stats as soon as the
+	// clone+analyze phase finishes it (--stream-output).
+	onRepo func(model.RepoStats) error
+}
+
+// stringList is a list of strings that also accepts a single YAML scalar, so
+// a --targets file can give one workspace or group or several.
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var one string
+	if err := unmarshal(&one); err == nil {
+		*l = stringList{one}
+		return nil
+	}
+	var many []string
+	if err := unmarshal(&many); err != nil {
+		return err
+	}
+	*l = many
+	return nil
+}
+
+// targetFlags are the analyze flags that an analyzeTarget carries, rejected
+// with --provider all so each target states its own scope.
+var targetFlags = []string{"workspace", "org", "user", "group", "project", "projects", "repos", "exclude", "exclude-project"}
+
+// streamIncompatibleFlags add data to repositories after the clone+analyze
+// phase (or rewrite it), which --stream-output has already written.
+var streamIncompatibleFlags = []string{"ai-estimate", "conventional-commits", "co-authorship", "health", "commit-counts", "health-cheap", "health-details", "churn", "code-ownership", "issues", "activity-heatmap", "min-code", "summary-only", "anonymize", "redact-urls"}
+
 func runAnalyze(cmd *cobra.Command, args []string) error {
 	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
 	defer cancel()
 
-	providerName, _ := cmd.Flags().GetString("provider")
-	workspace, _ := cmd.Flags().GetString("workspace")
-	org, _ := cmd.Flags().GetString("org")
-	user, _ := cmd.Flags().GetString("user")
-	group, _ := cmd.Flags().GetString("group")
-	projects, _ := cmd.Flags().GetStringSlice("projects")
-	repos, _ := cmd.Flags().GetStringSlice("repos")
-	exclude, _ := cmd.Flags().GetStringSlice("exclude")
-	includeArchived, _ := cmd.Flags().GetBool("include-archived")
-	includeForks, _ := cmd.Flags().GetBool("include-forks")
-	concurrency, _ := cmd.Flags().GetInt("concurrency")
-	outputPath, _ := cmd.Flags().GetString("output")
-	rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
-
-	// Create rate-limited HTTP client
-	httpClient := &http.Client{Transport: &provider.RateLimitTransport{ReqPerSec: rateLimit}}
-
-	// Load credentials
-	store := auth.NewFileStore(auth.DefaultStorePath())
-	cred, err := store.LoadWithEnv(providerName)
+	now, err := reportClock(cmd)
 	if err != nil {
-		return fmt.Errorf("not authenticated with %s — run 'codemium auth login --provider %s' first", providerName, providerName)
-	}
-
-	// Refresh if expired (Bitbucket)
-	if cred.Expired() && cred.RefreshToken != "" {
-		clientID := os.Getenv("CODEMIUM_BITBUCKET_CLIENT_ID")
-		clientSecret := os.Getenv("CODEMIUM_BITBUCKET_CLIENT_SECRET")
-		bb := &auth.BitbucketOAuth{ClientID: clientID, ClientSecret: clientSecret}
-		cred, err = bb.RefreshToken(ctx, cred.RefreshToken)
-		if err != nil {
-			return fmt.Errorf("token refresh failed: %w", err)
-		}
-		store.Save(providerName, cred)
+		return err
 	}
 
-	// Create provider
-	var prov provider.Provider
-	switch providerName {
-	case "bitbucket":
-		if workspace == "" {
-			return fmt.Errorf("--workspace is required for bitbucket")
-		}
-		prov = provider.NewBitbucket(cred.AccessToken, cred.Username, "", httpClient)
-	case "github":
-		if org != "" && user != "" {
-			return fmt.Errorf("--org and --user are mutually exclusive for github")
-		}
-		if org == "" && user == "" {
-			return fmt.Errorf("--org or --user is required for github")
+	providerName, _ := cmd.Flags().GetString("provider")
+	streamOutput, _ := cmd.Flags().GetBool("stream-output")
+	outputFormat, _ := cmd.Flags().GetString("output-format")
+	switch outputFormat {
+	case "json":
+	case "zip":
+		if streamOutput {
+			return fmt.Errorf("--output-format zip cannot be combined with --stream-output")
 		}
-		prov = provider.NewGitHub(cred.AccessToken, "", httpClient)
-	case "gitlab":
-		if group == "" {
-			return fmt.Errorf("--group is required for gitlab")
+		if p, _ := cmd.Flags().GetString("output"); p == "" {
+			return fmt.Errorf("--output-format zip requires an --output path")
 		}
-		baseURL := os.Getenv("CODEMIUM_GITLAB_URL")
-		prov = provider.NewGitLab(cred.AccessToken, baseURL, httpClient)
 	default:
-		return fmt.Errorf("unsupported provider: %s", providerName)
+		return fmt.Errorf("--output-format must be 'json' or 'zip', got %q", outputFormat)
 	}
-
-	// Interactive project picker for Bitbucket
-	if providerName == "bitbucket" && len(projects) == 0 && ui.IsTTY() {
-		bb := prov.(*provider.Bitbucket)
-		fmt.Fprintln(os.Stderr, "Fetching projects...")
-		projectList, err := bb.ListProjects(ctx, workspace)
-		if err != nil {
-			return fmt.Errorf("list projects: %w", err)
+	var report model.Report
+	var diagErrors []errorEntry
+	var stream *output.JSONStream
+	if providerName == output.MergedProvider {
+		if streamOutput {
+			return fmt.Errorf("--stream-output cannot be used with --provider all")
 		}
-		if len(projectList) > 0 {
-			selected, err := ui.PickProjects(projectList)
+		report, diagErrors, err = analyzeAllTargets(ctx, cmd, now)
+	} else {
+		target := analyzeTarget{Provider: providerName, interactive: true}
+		target.Workspace, _ = cmd.Flags().GetStringSlice("workspace")
+		target.Org, _ = cmd.Flags().GetString("org")
+		target.User, _ = cmd.Flags().GetString("user")
+		target.Group, _ = cmd.Flags().GetStringSlice("group")
+		target.Project, _ = cmd.Flags().GetString("project")
+		target.Projects, _ = cmd.Flags().GetStringSlice("projects")
+		target.Repos, _ = cmd.Flags().GetStringSlice("repos")
+		target.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+		target.ExcludeProjects, _ = cmd.Flags().GetStringSlice("exclude-project")
+		if streamOutput {
+			var closeOutput func()
+			stream, closeOutput, err = openJSONStream(cmd, target, now)
 			if err != nil {
-				return fmt.Errorf("project picker: %w", err)
-			}
-			if len(selected) > 0 {
-				projects = selected
+				return err
 			}
+			defer closeOutput()
+			target.onRepo = stream.WriteRepo
 		}
+		report, diagErrors, err = analyzeOne(ctx, cmd, target, now)
 	}
-
-	// List repos
-	fmt.Fprintln(os.Stderr, "Listing repositories...")
-	// For GitLab, pass group as Organization
-	listOrg := org
-	if group != "" {
-		listOrg = group
-	}
-
-	repoList, err := prov.ListRepos(ctx, provider.ListOpts{
-		Workspace:       workspace,
-		Organization:    listOrg,
-		User:            user,
-		Projects:        projects,
-		Repos:           repos,
-		Exclude:         exclude,
-		IncludeArchived: includeArchived,
-		IncludeForks:    includeForks,
-	})
 	if err != nil {
-		return fmt.Errorf("list repos: %w", err)
+		return err
 	}
 
-	if len(repoList) == 0 {
-		return fmt.Errorf("no repositories found")
+	if anonymize, _ := cmd.Flags().GetBool("anonymize"); anonymize {
+		// A fresh salt per run keeps pseudonyms from linking reports together
+		salt := make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("generate anonymization salt: %w", err)
+		}
+		report = output.Anonymize(report, salt)
+	}
+	if redact, _ := cmd.Flags().GetBool("redact-urls"); redact {
+		report = output.RedactURLs(report)
 	}
 
-	fmt.Fprintf(os.Stderr, "Found %d repositories\n", len(repoList))
+	outputPath, _ := cmd.Flags().GetString("output")
+	outputPath = expandOutputPath(outputPath, report.Provider, report.Organization, report.Workspace, now)
 
-	// Set up progress
-	useTUI := ui.IsTTY()
-	var program *tea.Program
-	if useTUI {
-		program = ui.RunTUI(len(repoList))
-		go func() {
-			program.Run()
-		}()
+	var mdOpts []output.MarkdownOption
+	if allLanguages, _ := cmd.Flags().GetBool("all-languages"); allLanguages {
+		mdOpts = append(mdOpts, output.AllLanguages())
+	}
+	renderMarkdown := func(w io.Writer) error {
+		return output.WriteMarkdown(w, report, mdOpts...)
 	}
 
-	// Process repos
-	cloner := analyzer.NewCloner(cred.AccessToken, cred.Username)
-	codeAnalyzer := analyzer.New()
-
-	progressFn := func(completed, total int, repo model.Repo) {
-		if useTUI && program != nil {
-			program.Send(ui.ProgressMsg{
-				Completed: completed,
-				Total:     total,
-				RepoName:  repo.Slug,
-			})
-		} else {
-			fmt.Fprintf(os.Stderr, "[%d/%d] Analyzed %s\n", completed, total, repo.Slug)
+	if outputFormat == "zip" {
+		outputPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".zip"
+		if err := writeBundle(outputPath, report, diagErrors, jsonOptions(cmd), renderMarkdown); err != nil {
+			return err
 		}
+		fmt.Fprintf(os.Stderr, "Report bundle written to %s\n", outputPath)
+		return writeMarkdownOut(cmd, report.Provider, report.Organization, report.Workspace, now, renderMarkdown)
 	}
 
-	results := worker.RunWithProgress(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
-		var dir string
-		var cleanup func()
-		var err error
-		if repo.DownloadURL != "" {
-			dir, cleanup, err = cloner.Download(ctx, repo.DownloadURL)
-		} else {
-			dir, cleanup, err = cloner.Clone(ctx, repo.CloneURL)
+	// Write error.log if there were any diagnostic errors
+	if len(diagErrors) > 0 {
+		ext := filepath.Ext(outputPath)
+		errorLogPath := strings.TrimSuffix(outputPath, ext) + ".error.log"
+		if err := os.MkdirAll(filepath.Dir(errorLogPath), 0o755); err != nil {
+			return fmt.Errorf("create error log directory: %w", err)
 		}
+		f, err := os.Create(errorLogPath)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("create error log: %w", err)
 		}
-		defer cleanup()
+		for _, e := range diagErrors {
+			fmt.Fprintf(f, "[%s] %s | %s\n", e.Category, e.Repo, e.Message)
+		}
+		f.Close()
+		fmt.Fprintf(os.Stderr, "Error log written to %s (%d entries)\n", errorLogPath, len(diagErrors))
+	}
 
-		stats, err := codeAnalyzer.Analyze(ctx, dir)
-		if err != nil {
-			return nil, err
+	if stream != nil {
+		if err := stream.Close(report); err != nil {
+			return fmt.Errorf("write JSON: %w", err)
 		}
+		if outputPath != "" {
+			fmt.Fprintf(os.Stderr, "Report written to %s\n", outputPath)
+		}
+		return nil
+	}
 
-		stats.License = license.Detect(dir)
-		stats.Repository = repo.Slug
-		stats.Project = repo.Project
-		stats.Provider = repo.Provider
-		stats.URL = repo.URL
-		return stats, nil
-	}, progressFn)
+	// Write JSON output
+	var jsonWriter io.Writer = os.Stdout
+	if outputPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer f.Close()
+		jsonWriter = f
+	}
+	if err := output.WriteJSON(jsonWriter, report, jsonOptions(cmd)...); err != nil {
+		return fmt.Errorf("write JSON: %w", err)
+	}
 
-	if useTUI && program != nil {
-		program.Send(ui.DoneMsg{})
-		// Give TUI a moment to render the done message
-		time.Sleep(100 * time.Millisecond)
-		program.Quit()
-		program = nil
+	if outputPath != "" {
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", outputPath)
 	}
 
-	// Diagnostic error collection (written to error.log if non-empty)
-	var diagErrors []errorEntry
-	var diagMu sync.Mutex
+	return writeMarkdownOut(cmd, report.Provider, report.Organization, report.Workspace, now, renderMarkdown)
+}
 
-	// AI estimation phase
-	aiEstimateFlag, _ := cmd.Flags().GetBool("ai-estimate")
-	aiCommitLimit, _ := cmd.Flags().GetInt("ai-commit-limit")
+// writeBundle writes a zip archive at path holding report.json,
+// report.md and, when there were diagnostics, errors.json.
+func writeBundle(path string, report model.Report, diagErrors []errorEntry, jsonOpts []output.JSONOption, renderMarkdown func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	type entry struct {
+		name  string
+		write func(io.Writer) error
+	}
+	entries := []entry{
+		{"report.json", func(w io.Writer) error { return output.WriteJSON(w, report, jsonOpts...) }},
+		{"report.md", renderMarkdown},
+	}
+	if len(diagErrors) > 0 {
+		entries = append(entries, entry{"errors.json", func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(diagErrors)
+		}})
+	}
+
+	zw := zip.NewWriter(f)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			return fmt.Errorf("write %s: %w", e.name, err)
+		}
+		if err := e.write(w); err != nil {
+			return fmt.Errorf("write %s: %w", e.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("write zip: %w", err)
+	}
+	return f.Close()
+}
+
+// writeMarkdownOut writes the markdown rendering produced by render to the
+// --markdown-out file, if one was given, expanding its placeholders like
+// --output.
+func writeMarkdownOut(cmd *cobra.Command, providerName, org, workspace string, now time.Time, render func(io.Writer) error) error {
+	mdPath, _ := cmd.Flags().GetString("markdown-out")
+	if mdPath == "" {
+		return nil
+	}
+	mdPath = expandOutputPath(mdPath, providerName, org, workspace, now)
+	if err := os.MkdirAll(filepath.Dir(mdPath), 0o755); err != nil {
+		return fmt.Errorf("create markdown output directory: %w", err)
+	}
+	f, err := os.Create(mdPath)
+	if err != nil {
+		return fmt.Errorf("create markdown output file: %w", err)
+	}
+	if err := render(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write markdown: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write markdown: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Markdown written to %s\n", mdPath)
+	return nil
+}
+
+// checkoutLatestTag checks out the highest semver release tag of a full
+// clone and returns its name, or leaves the default branch checked out and
+// returns "HEAD" when the repository has no release tag.
+func checkoutLatestTag(ctx context.Context, cloner *analyzer.Cloner, repo *git.Repository, dir string) (string, error) {
+	if err := cloner.FetchTags(ctx, repo); err != nil {
+		return "", err
+	}
+	tag, hash, ok, err := analyzer.LatestReleaseTag(repo)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "HEAD", nil
+	}
+	if err := analyzer.Checkout(repo, dir, hash); err != nil {
+		return "", err
+	}
+	return tag, nil
+}
+
+// openJSONStream creates the --output file for --stream-output and writes the
+// report envelope, resolving the path placeholders from the target the same
+// way analyzeOne fills in the report's provider, workspace and organization.
+func openJSONStream(cmd *cobra.Command, target analyzeTarget, now time.Time) (*output.JSONStream, func(), error) {
+	// Not every flag is a bool (--min-code is an int), so compare each with
+	// its default instead of reading it with GetBool.
+	for _, name := range streamIncompatibleFlags {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Value.String() != f.DefValue {
+			return nil, nil, fmt.Errorf("--stream-output cannot be combined with --%s", name)
+		}
+	}
+	if mdPath, _ := cmd.Flags().GetString("markdown-out"); mdPath != "" {
+		return nil, nil, fmt.Errorf("--stream-output cannot be combined with --markdown-out")
+	}
+
+	header := model.Report{
+		GeneratedAt:  now.UTC().Format(time.RFC3339),
+		Provider:     target.Provider,
+		Workspace:    strings.Join(target.Workspace, ","),
+		Organization: target.Org,
+	}
+	if target.User != "" {
+		header.Organization = target.User
+	}
+	if len(target.Group) > 0 {
+		header.Organization = strings.Join(target.Group, ",")
+	}
+
+	outputPath, _ := cmd.Flags().GetString("output")
+	outputPath = expandOutputPath(outputPath, header.Provider, header.Organization, header.Workspace, now)
+	var w io.Writer = os.Stdout
+	closeOutput := func() {}
+	if outputPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+			return nil, nil, fmt.Errorf("create output directory: %w", err)
+		}
+		f, err := os.Create(outputPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create output file: %w", err)
+		}
+		w, closeOutput = f, func() { f.Close() }
+	}
+
+	stream, err := output.NewJSONStream(w, header, jsonOptions(cmd)...)
+	if err != nil {
+		closeOutput()
+		return nil, nil, fmt.Errorf("write JSON: %w", err)
+	}
+	return stream, closeOutput, nil
+}
+
+// analyzeAllTargets analyzes every target listed in the --targets file and
+// merges the results into one report. Diagnostic errors are prefixed with
+// the target's provider so the combined error log stays unambiguous.
+func analyzeAllTargets(ctx context.Context, cmd *cobra.Command, now time.Time) (model.Report, []errorEntry, error) {
+	for _, name := range targetFlags {
+		if cmd.Flags().Changed(name) {
+			return model.Report{}, nil, fmt.Errorf("--%s cannot be used with --provider all; set it per target in the --targets file", name)
+		}
+	}
+	targetsPath, _ := cmd.Flags().GetString("targets")
+	if targetsPath == "" {
+		return model.Report{}, nil, fmt.Errorf("--targets is required with --provider all")
+	}
+	targets, err := loadTargets(targetsPath)
+	if err != nil {
+		return model.Report{}, nil, err
+	}
+
+	var reports []model.Report
+	var diagErrors []errorEntry
+	for i, target := range targets {
+		fmt.Fprintf(os.Stderr, "Target %d/%d: %s\n", i+1, len(targets), target.Provider)
+		report, targetErrors, err := analyzeOne(ctx, cmd, target, now)
+		if err != nil {
+			return model.Report{}, nil, fmt.Errorf("target %d (%s): %w", i+1, target.Provider, err)
+		}
+		for _, e := range targetErrors {
+			e.Repo = target.Provider + ":" + e.Repo
+			diagErrors = append(diagErrors, e)
+		}
+		reports = append(reports, report)
+	}
+
+	report := output.Merge(reports...)
+	report.Filters.ChangedSince = reports[0].Filters.ChangedSince
+	report.Filters.MinCode = reports[0].Filters.MinCode
+	// Every target ran with the same flags; only the target differs.
+	if cfg := reports[0].RunConfig; cfg != nil {
+		merged := *cfg
+		merged.Provider, merged.Workspace, merged.Organization = output.MergedProvider, "", ""
+		report.RunConfig = &merged
+	}
+	return report, diagErrors, nil
+}
+
+// loadTargets reads a --targets YAML file: a "targets" list of providers
+// with the same scope settings as the analyze flags.
+func loadTargets(path string) ([]analyzeTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read targets file: %w", err)
+	}
+	var file struct {
+		Targets []analyzeTarget `yaml:"targets"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parse targets file %s: %w", path, err)
+	}
+	if len(file.Targets) == 0 {
+		return nil, fmt.Errorf("targets file %s lists no targets", path)
+	}
+	for i, t := range file.Targets {
+		if t.Provider == "" || t.Provider == output.MergedProvider {
+			return nil, fmt.Errorf("targets file %s: target %d needs a provider (bitbucket, github, gitlab, azure)", path, i+1)
+		}
+	}
+	return file.Targets, nil
+}
+
+// analyzeOne lists, clones and analyzes the repositories of one target and
+// returns its report with the diagnostic errors collected along the way.
+func analyzeOne(ctx context.Context, cmd *cobra.Command, target analyzeTarget, now time.Time) (model.Report, []errorEntry, error) {
+	providerName := target.Provider
+	workspaces := target.Workspace
+	org := target.Org
+	user := target.User
+	groups := target.Group
+	// Several workspaces or groups are listed in turn and reported together;
+	// the joined value is the report's workspace or organization.
+	workspace := strings.Join(workspaces, ",")
+	group := strings.Join(groups, ",")
+	projects := target.Projects
+	if target.Project != "" {
+		projects = append(projects, target.Project)
+	}
+	repos := target.Repos
+	exclude := target.Exclude
+	excludeProjects := target.ExcludeProjects
+	includeArchived, _ := cmd.Flags().GetBool("include-archived")
+	onlyArchived, _ := cmd.Flags().GetBool("only-archived")
+	bitbucketRole, _ := cmd.Flags().GetString("bitbucket-role")
+	includeForks, _ := cmd.Flags().GetBool("include-forks")
+	forkDiffOnly, _ := cmd.Flags().GetBool("fork-diff-only")
+	if forkDiffOnly {
+		if cmd.Flags().Changed("changed-since") {
+			return model.Report{}, nil, fmt.Errorf("--changed-since cannot be combined with --fork-diff-only")
+		}
+		includeForks = true // --fork-diff-only implies --include-forks
+	}
+	atLatestTag, _ := cmd.Flags().GetBool("at-latest-tag")
+	if atLatestTag && (forkDiffOnly || cmd.Flags().Changed("changed-since")) {
+		return model.Report{}, nil, fmt.Errorf("--at-latest-tag cannot be combined with --changed-since or --fork-diff-only")
+	}
+	recent, _ := cmd.Flags().GetInt("recent")
+	if recent < 0 {
+		return model.Report{}, nil, fmt.Errorf("--recent must not be negative")
+	}
+	concurrency := resolveConcurrency(cmd, "concurrency", worker.DefaultConcurrency(worker.NetworkBound))
+	apiConcurrency := resolveConcurrency(cmd, "api-concurrency", concurrency)
+	rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
+	onErrorFlag, _ := cmd.Flags().GetString("on-error")
+
+	onError, err := worker.ParseErrorPolicy(onErrorFlag)
+	if err != nil {
+		return model.Report{}, nil, err
+	}
+
+	thresholdSpecs, _ := cmd.Flags().GetStringSlice("complexity-threshold")
+	thresholds, err := complexity.ParseThresholds(thresholdSpecs)
+	if err != nil {
+		return model.Report{}, nil, err
+	}
+
+	codeAnalyzer, err := newAnalyzer(cmd)
+	if err != nil {
+		return model.Report{}, nil, err
+	}
+	analysisCache, err := newAnalysisCache(cmd)
+	if err != nil {
+		return model.Report{}, nil, err
+	}
+
+	// Load custom AI detection rules up front so a bad file fails before any cloning
+	var aiSignals *aidetect.Config
+	if path, _ := cmd.Flags().GetString("ai-signals-file"); path != "" {
+		aiSignals, err = aidetect.LoadConfig(path)
+		if err != nil {
+			return model.Report{}, nil, err
+		}
+	}
+	if caseSensitive, _ := cmd.Flags().GetBool("ai-case-sensitive"); caseSensitive {
+		if aiSignals == nil {
+			aiSignals = &aidetect.Config{}
+		}
+		aiSignals.CaseSensitive = true
+	}
+
+	// Load the author alias map up front so a bad file fails before any cloning
+	var authorMap *health.AuthorMap
+	if authorMapPath, _ := cmd.Flags().GetString("author-map"); authorMapPath != "" {
+		authorMap, err = health.LoadAuthorMap(authorMapPath)
+		if err != nil {
+			return model.Report{}, nil, err
+		}
+	}
+
+	// Create rate-limited HTTP client
+	httpTimeout, _ := cmd.Flags().GetDuration("http-timeout")
+	httpClient := &http.Client{Transport: &provider.RateLimitTransport{ReqPerSec: rateLimit, Timeout: httpTimeout}}
+
+	// Load credentials
+	store := auth.NewFileStore(auth.DefaultStorePath())
+	cred, err := store.LoadWithEnv(providerName)
+	if err != nil {
+		return model.Report{}, nil, notAuthenticated(providerName)
+	}
+
+	// Refresh if expired (Bitbucket)
+	if cred.Expired() && cred.RefreshToken != "" {
+		clientID := os.Getenv("CODEMIUM_BITBUCKET_CLIENT_ID")
+		clientSecret := os.Getenv("CODEMIUM_BITBUCKET_CLIENT_SECRET")
+		bb := &auth.BitbucketOAuth{ClientID: clientID, ClientSecret: clientSecret}
+		cred, err = bb.RefreshToken(ctx, cred.RefreshToken)
+		if err != nil {
+			return model.Report{}, nil, fmt.Errorf("token refresh failed: %w", err)
+		}
+		store.Save(providerName, cred)
+	}
+
+	// Create provider
+	var prov provider.Provider
+	switch providerName {
+	case "bitbucket":
+		prov, err = newBitbucketProvider(workspace, cred, httpClient)
+		if err != nil {
+			return model.Report{}, nil, err
+		}
+	case "github":
+		if org != "" && user != "" {
+			return model.Report{}, nil, fmt.Errorf("--org and --user are mutually exclusive for github")
+		}
+		if org == "" && user == "" {
+			return model.Report{}, nil, fmt.Errorf("--org or --user is required for github")
+		}
+		if len(excludeProjects) > 0 {
+			return model.Report{}, nil, fmt.Errorf("--exclude-project is not supported for github (repos have no project)")
+		}
+		baseURL, err := githubBaseURL(cmd)
+		if err != nil {
+			return model.Report{}, nil, err
+		}
+		prov = provider.NewGitHub(cred.AccessToken, baseURL, httpClient)
+	case "gitlab":
+		if group == "" && len(projects) == 0 {
+			return model.Report{}, nil, fmt.Errorf("--group or --projects is required for gitlab")
+		}
+		if group != "" && len(projects) > 0 {
+			return model.Report{}, nil, fmt.Errorf("--group and --projects are mutually exclusive for gitlab (--projects takes full project paths)")
+		}
+		baseURL := os.Getenv("CODEMIUM_GITLAB_URL")
+		prov = provider.NewGitLab(cred.AccessToken, baseURL, httpClient)
+	case "azure":
+		if org == "" {
+			return model.Report{}, nil, fmt.Errorf("--org is required for azure")
+		}
+		baseURL := os.Getenv("CODEMIUM_AZURE_URL")
+		prov = provider.NewAzureDevOps(cred.AccessToken, org, baseURL, httpClient)
+	default:
+		return model.Report{}, nil, fmt.Errorf("unsupported provider: %s", providerName)
+	}
+
+	if err := checkOnlyArchived(onlyArchived, prov); err != nil {
+		return model.Report{}, nil, err
+	}
+	if err := checkBitbucketRole(bitbucketRole, prov); err != nil {
+		return model.Report{}, nil, err
+	}
+
+	// Interactive project picker for Bitbucket
+	if providerName == "bitbucket" && target.interactive && len(projects) == 0 && len(workspaces) <= 1 && ui.IsTTY() {
+		bb := prov.(provider.ProjectLister)
+		fmt.Fprintln(os.Stderr, "Fetching projects...")
+		projectList, err := bb.ListProjects(ctx, workspace)
+		if err != nil {
+			return model.Report{}, nil, fmt.Errorf("list projects: %w", err)
+		}
+		if len(projectList) > 0 {
+			selected, err := ui.PickProjects(projectList)
+			if err != nil {
+				return model.Report{}, nil, fmt.Errorf("project picker: %w", err)
+			}
+			if len(selected) > 0 {
+				projects = selected
+			}
+		}
+	}
+
+	// Wall-clock timing per phase, reported in the JSON output
+	analyzeStart := time.Now()
+	timing := &model.Timing{}
+	recordPhase := func(name string, start time.Time) {
+		timing.Phases = append(timing.Phases, model.PhaseTiming{Phase: name, Seconds: roundSeconds(time.Since(start))})
+	}
+
+	// Diagnostic error collection (written to error.log if non-empty)
+	var diagErrors []errorEntry
+	var diagMu sync.Mutex
+
+	// List repos
+	fmt.Fprintln(os.Stderr, "Listing repositories...")
+	listOpts := provider.ListOpts{
+		Organization:    org,
+		User:            user,
+		Projects:        projects,
+		Repos:           repos,
+		Exclude:         exclude,
+		ExcludeProjects: excludeProjects,
+		IncludeArchived: includeArchived,
+		OnlyArchived:    onlyArchived,
+		IncludeForks:    includeForks,
+		Role:            bitbucketRole,
+	}
+	if skipFailedPages, _ := cmd.Flags().GetBool("skip-failed-pages"); skipFailedPages {
+		listOpts.OnPageError = func(pageURL string, err error) {
+			fmt.Fprintf(os.Stderr, "Warning: skipped repository listing page %s: %v\n", pageURL, err)
+			diagErrors = append(diagErrors, errorEntry{Category: "list", Repo: pageURL, Message: err.Error()})
+		}
+	}
+	repoList, err := listScopes(ctx, prov, listOpts, workspaces, groups)
+	if err != nil {
+		return model.Report{}, nil, listHint(err, prov, bitbucketRole)
+	}
+
+	if missing := missingRepos(repos, repoList); len(missing) > 0 {
+		if strict, _ := cmd.Flags().GetBool("strict-repos"); strict {
+			return model.Report{}, nil, fmt.Errorf("--repos entries not found in the listing (missing, excluded, archived or forks): %s", strings.Join(missing, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "Warning: --repos entries not found in the listing (missing, excluded, archived or forks): %s\n", strings.Join(missing, ", "))
+	}
+
+	if len(repoList) == 0 {
+		return model.Report{}, nil, fmt.Errorf("no repositories found")
+	}
+
+	fmt.Fprintf(os.Stderr, "Found %d repositories\n", len(repoList))
+	if recent > 0 && recent < len(repoList) {
+		var undated int
+		repoList, undated = mostRecent(repoList, recent)
+		if undated > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d repositories have no last-activity time from %s and sort last for --recent\n", undated, providerName)
+		}
+		fmt.Fprintf(os.Stderr, "Keeping the %d most recently pushed\n", len(repoList))
+	}
+	recordPhase("list", analyzeStart)
+
+	// Set up progress
+	useTUI := ui.IsTTY()
+	var program *tea.Program
+	if useTUI {
+		program = ui.RunTUI(len(repoList))
+		go func() {
+			program.Run()
+		}()
+	}
+
+	// Process repos
+	analyzePhaseStart := time.Now()
+	cloner := newCloner(cmd, cred)
+	changedSince, _ := cmd.Flags().GetString("changed-since")
+
+	progressFn := func(completed, total int, repo model.Repo) {
+		if useTUI && program != nil {
+			program.Send(ui.ProgressMsg{
+				Completed: completed,
+				Total:     total,
+				RepoName:  repo.Slug,
+			})
+		} else {
+			fmt.Fprintf(os.Stderr, "[%d/%d] Analyzed %s\n", completed, total, repo.Slug)
+		}
+	}
+
+	// With --stream-output, each finished repo is written right away, with
+	// the per-repo fields buildReport would otherwise fill in at the end
+	var onResult worker.ResultFunc
+	var streamErr error
+	if target.onRepo != nil {
+		onResult = func(r worker.Result) {
+			if r.Err != nil || r.Stats == nil || streamErr != nil {
+				return
+			}
+			stats := *r.Stats
+			stats.Structure = classifyStructure(&stats)
+			stats.IsInfraOnly = isInfraOnly(&stats)
+			setCodePercent(stats.Languages, stats.Totals.Code)
+			streamErr = target.onRepo(stats)
+		}
+	}
+
+	var cacheHits atomic.Int64
+	results := worker.RunWithResults(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		// Only plain default-branch analyses are cached; the other modes
+		// depend on more than the HEAD commit
+		useCache := analysisCache != nil && changedSince == "" && !forkDiffOnly && !atLatestTag && repo.CloneURL != ""
+		if useCache {
+			if sha, err := cloner.HeadSHA(ctx, repo.CloneURL, repo.DefaultBranch); err == nil {
+				repo.HeadSHA = sha
+				if cached, ok := analysisCache.Load(repo.URL, sha); ok {
+					cacheHits.Add(1)
+					setRepoFields(cached, repo)
+					return cached, nil
+				}
+			}
+		}
+
+		var dir string
+		var cleanup func()
+		var err error
+		var changed []string
+		var parentURL, analyzedRef string
+		forkDiff := forkDiffOnly && repo.Fork
+		switch {
+		case changedSince != "":
+			// Diffing needs history, so use a full clone instead of shallow/tarball
+			if repo.CloneURL == "" {
+				return nil, fmt.Errorf("--changed-since requires git clone access")
+			}
+			gitRepo, fullDir, fullCleanup, cloneErr := cloner.CloneFull(ctx, repo.CloneURL)
+			dir, cleanup, err = fullDir, fullCleanup, cloneErr
+			if err == nil {
+				changed, err = analyzer.ChangedFiles(gitRepo, changedSince)
+				if err != nil {
+					cleanup()
+				}
+			}
+		case forkDiff:
+			// Count only files the fork changed since it diverged from its parent
+			if repo.CloneURL == "" {
+				return nil, fmt.Errorf("--fork-diff-only requires git clone access")
+			}
+			parentURL = repo.ParentURL
+			if resolver, ok := prov.(provider.ForkParentResolver); ok && parentURL == "" {
+				if parentURL, err = resolver.ForkParent(ctx, repo); err != nil {
+					return nil, fmt.Errorf("resolve fork parent: %w", err)
+				}
+			}
+			if parentURL == "" {
+				return nil, fmt.Errorf("--fork-diff-only: parent repository of fork is unknown")
+			}
+			gitRepo, fullDir, fullCleanup, cloneErr := cloner.CloneFull(ctx, repo.CloneURL)
+			dir, cleanup, err = fullDir, fullCleanup, cloneErr
+			if err == nil {
+				var parentRef string
+				parentRef, err = cloner.FetchParent(ctx, gitRepo, parentURL)
+				if err == nil {
+					changed, err = analyzer.ChangedFiles(gitRepo, parentRef)
+				}
+				if err != nil {
+					cleanup()
+				}
+			}
+		case atLatestTag:
+			// Tags need a full clone; go-git clones without them
+			if repo.CloneURL == "" {
+				return nil, fmt.Errorf("--at-latest-tag requires git clone access")
+			}
+			gitRepo, fullDir, fullCleanup, cloneErr := cloner.CloneFull(ctx, repo.CloneURL)
+			dir, cleanup, err = fullDir, fullCleanup, cloneErr
+			if err == nil {
+				analyzedRef, err = checkoutLatestTag(ctx, cloner, gitRepo, dir)
+				if err != nil {
+					cleanup()
+				}
+			}
+		case repo.DownloadURL != "":
+			dir, cleanup, err = cloner.Download(ctx, repo.DownloadURL)
+		default:
+			dir, cleanup, err = cloner.Clone(ctx, repo.CloneURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+
+		var stats *model.RepoStats
+		if changedSince != "" || forkDiff {
+			stats, err = codeAnalyzer.AnalyzeFiles(ctx, dir, changed)
+		} else {
+			stats, err = codeAnalyzer.Analyze(ctx, dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		stats.License, stats.LicenseFile = license.Detect(dir)
+		if useCache && repo.HeadSHA != "" {
+			if err := analysisCache.Store(repo.URL, repo.HeadSHA, stats); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", repo.Slug, err)
+			}
+		}
+		setRepoFields(stats, repo)
+		if forkDiff {
+			stats.ForkParent = parentURL
+		}
+		stats.AnalyzedRef = analyzedRef
+		return stats, nil
+	}, progressFn, onResult, onError)
+	if n := cacheHits.Load(); n > 0 {
+		fmt.Fprintf(os.Stderr, "Reused cached analysis for %d unchanged repositories\n", n)
+	}
+
+	if useTUI && program != nil {
+		program.Send(ui.DoneMsg{})
+		// Give TUI a moment to render the done message
+		time.Sleep(100 * time.Millisecond)
+		program.Quit()
+		program = nil
+	}
+
+	recordPhase("clone+analyze", analyzePhaseStart)
+	if err := failFastError(onError, "clone+analyze", results); err != nil {
+		return model.Report{}, nil, err
+	}
+	if streamErr != nil {
+		return model.Report{}, nil, fmt.Errorf("write streamed output: %w", streamErr)
+	}
+	for _, r := range results {
+		if r.Stats == nil {
+			continue
+		}
+		for _, s := range r.Stats.SkippedFiles {
+			diagErrors = append(diagErrors, errorEntry{Category: "skip", Repo: r.Repo.Slug, Message: s.Path + ": " + s.Reason})
+		}
+		if more := r.Stats.SkippedTotal - int64(len(r.Stats.SkippedFiles)); more > 0 {
+			diagErrors = append(diagErrors, errorEntry{Category: "skip", Repo: r.Repo.Slug, Message: fmt.Sprintf("%d more skipped paths not listed", more)})
+		}
+	}
+
+	// The AI, health and churn phases share one lister so commit stats they
+	// have in common are fetched from the provider once.
+	commitLister, hasCommits := prov.(provider.CommitLister)
+	if cl, ok := prov.(provider.ChurnLister); ok {
+		commitLister = provider.NewCachingCommitLister(cl)
+	}
+
+	// AI estimation phase
+	aiEstimateFlag, _ := cmd.Flags().GetBool("ai-estimate")
+	aiCommitLimit, _ := cmd.Flags().GetInt("ai-commit-limit")
+	conventionalFlag, _ := cmd.Flags().GetBool("conventional-commits")
+	coAuthorFlag, _ := cmd.Flags().GetBool("co-authorship")
+	heatmapFlag, _ := cmd.Flags().GetBool("activity-heatmap")
+	commitCountsFlag, _ := cmd.Flags().GetBool("commit-counts")
 
 	if aiEstimateFlag {
-		commitLister, ok := prov.(provider.CommitLister)
-		if !ok {
-			return fmt.Errorf("provider %s does not support AI estimation", providerName)
+		phaseStart := time.Now()
+		if !hasCommits {
+			return model.Report{}, nil, fmt.Errorf("provider %s does not support AI estimation", providerName)
 		}
 
 		fmt.Fprintln(os.Stderr, "Estimating AI contribution...")
@@ -487,8 +1480,12 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		aiResults := worker.RunWithProgress(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
-			est, partialErrs, err := aiestimate.Estimate(ctx, commitLister, repo, aiCommitLimit)
+		aiResults := worker.RunWithProgress(ctx, repoList, apiConcurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+			commits, err := commitLister.ListCommits(ctx, repo, aiCommitLimit)
+			if err != nil {
+				return nil, err
+			}
+			est, partialErrs := aiestimate.EstimateFromCommits(ctx, commitLister, repo, commits, aiSignals)
 			if len(partialErrs) > 0 {
 				diagMu.Lock()
 				for _, pe := range partialErrs {
@@ -496,14 +1493,28 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 				}
 				diagMu.Unlock()
 			}
-			if err != nil {
-				return nil, err
-			}
-			return &model.RepoStats{
+			stats := &model.RepoStats{
 				Repository: repo.Slug,
 				AIEstimate: est,
-			}, nil
-		}, aiProgressFn)
+			}
+			// Reuse the same listing for commit message stats
+			if conventionalFlag {
+				pct := conventional.Percent(commits)
+				stats.ConventionalCommitPercent = &pct
+			}
+			if coAuthorFlag {
+				stats.CoAuthorship = coauthor.Pairs(commits, authorMap)
+			}
+			if heatmapFlag {
+				stats.ActivityHeatmap = activity.Heatmap(commits)
+			}
+			if commitCountsFlag {
+				n := len(commits)
+				stats.CommitCount = &n
+				stats.CommitCountTruncated = aiCommitLimit > 0 && n >= aiCommitLimit
+			}
+			return stats, nil
+		}, aiProgressFn, onError)
 
 		if useTUI && program != nil {
 			program.Send(ui.DoneMsg{})
@@ -512,40 +1523,132 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			program = nil
 		}
 
+		if err := failFastError(onError, "AI estimation", aiResults); err != nil {
+			return model.Report{}, nil, err
+		}
+
 		// Attach AI estimates to analysis results
-		aiByRepo := make(map[string]*model.AIEstimate)
+		aiByRepo := make(map[string]*model.RepoStats)
 		for _, r := range aiResults {
 			if r.Err != nil {
 				diagErrors = append(diagErrors, errorEntry{Category: "ai-estimate", Repo: r.Repo.Slug, Message: r.Err.Error()})
 				continue
 			}
 			if r.Stats != nil && r.Stats.AIEstimate != nil {
-				aiByRepo[r.Repo.Slug] = r.Stats.AIEstimate
+				aiByRepo[r.Repo.Slug] = r.Stats
 			}
 		}
 
 		for i := range results {
 			if results[i].Stats != nil {
-				if est, ok := aiByRepo[results[i].Repo.Slug]; ok {
-					results[i].Stats.AIEstimate = est
+				if as, ok := aiByRepo[results[i].Repo.Slug]; ok {
+					results[i].Stats.AIEstimate = as.AIEstimate
+					results[i].Stats.ConventionalCommitPercent = as.ConventionalCommitPercent
+					results[i].Stats.CoAuthorship = as.CoAuthorship
+					results[i].Stats.ActivityHeatmap = as.ActivityHeatmap
+					results[i].Stats.CommitCount = as.CommitCount
+					results[i].Stats.CommitCountTruncated = as.CommitCountTruncated
 				}
 			}
 		}
+		recordPhase("ai", phaseStart)
+	}
+
+	// Commit message phase: conventional commits, co-authorship, the
+	// activity heatmap and commit counts (only when the AI phase didn't
+	// already list commits)
+	if (conventionalFlag || coAuthorFlag || heatmapFlag || commitCountsFlag) && !aiEstimateFlag {
+		phaseStart := time.Now()
+		if !hasCommits {
+			return model.Report{}, nil, fmt.Errorf("provider %s does not support commit message analysis", providerName)
+		}
+
+		fmt.Fprintln(os.Stderr, "Scanning commit messages...")
+
+		commitsProgressFn, commitsDone := phaseProgress(useTUI, len(repoList), "Commits")
+		commitResults := worker.RunWithProgress(ctx, repoList, apiConcurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+			commits, err := commitLister.ListCommits(ctx, repo, aiCommitLimit)
+			if err != nil {
+				return nil, err
+			}
+			stats := &model.RepoStats{Repository: repo.Slug}
+			if conventionalFlag {
+				pct := conventional.Percent(commits)
+				stats.ConventionalCommitPercent = &pct
+			}
+			if coAuthorFlag {
+				stats.CoAuthorship = coauthor.Pairs(commits, authorMap)
+			}
+			if heatmapFlag {
+				stats.ActivityHeatmap = activity.Heatmap(commits)
+			}
+			if commitCountsFlag {
+				n := len(commits)
+				stats.CommitCount = &n
+				stats.CommitCountTruncated = aiCommitLimit > 0 && n >= aiCommitLimit
+			}
+			return stats, nil
+		}, commitsProgressFn, onError)
+		commitsDone()
+		if err := failFastError(onError, "commit messages", commitResults); err != nil {
+			return model.Report{}, nil, err
+		}
+
+		commitsByRepo := make(map[string]*model.RepoStats)
+		for _, r := range commitResults {
+			if r.Err != nil {
+				diagErrors = append(diagErrors, errorEntry{Category: "commits", Repo: r.Repo.Slug, Message: r.Err.Error()})
+				continue
+			}
+			if r.Stats != nil {
+				commitsByRepo[r.Repo.Slug] = r.Stats
+			}
+		}
+		for i := range results {
+			if results[i].Stats != nil {
+				if cs, ok := commitsByRepo[results[i].Repo.Slug]; ok {
+					results[i].Stats.ConventionalCommitPercent = cs.ConventionalCommitPercent
+					results[i].Stats.CoAuthorship = cs.CoAuthorship
+					results[i].Stats.ActivityHeatmap = cs.ActivityHeatmap
+					results[i].Stats.CommitCount = cs.CommitCount
+					results[i].Stats.CommitCountTruncated = cs.CommitCountTruncated
+				}
+			}
+		}
+		recordPhase("commits", phaseStart)
 	}
 
 	// Health classification phase
 	healthFlag, _ := cmd.Flags().GetBool("health")
 	healthDetailsFlag, _ := cmd.Flags().GetBool("health-details")
 	healthCommitLimit, _ := cmd.Flags().GetInt("health-commit-limit")
+	healthCheapFlag, _ := cmd.Flags().GetBool("health-cheap")
+	velocityBand, _ := cmd.Flags().GetFloat64("velocity-band")
+	healthWindows, _ := cmd.Flags().GetIntSlice("health-windows")
+	var healthThresholds health.Thresholds
+	healthThresholds.Active, _ = cmd.Flags().GetInt("health-active-days")
+	healthThresholds.Maintained, _ = cmd.Flags().GetInt("health-maintained-days")
 
-	if healthDetailsFlag {
-		healthFlag = true // --health-details implies --health
+	if healthCheapFlag && healthDetailsFlag {
+		return model.Report{}, nil, fmt.Errorf("--health-cheap cannot be combined with --health-details")
+	}
+	if velocityBand < 0 || velocityBand >= 1 {
+		return model.Report{}, nil, fmt.Errorf("--velocity-band must be at least 0 and below 1, got %g", velocityBand)
+	}
+	if err := health.ValidateWindows(healthWindows); err != nil {
+		return model.Report{}, nil, fmt.Errorf("--health-windows: %w", err)
+	}
+	if err := health.ValidateThresholds(healthThresholds); err != nil {
+		return model.Report{}, nil, fmt.Errorf("--health-active-days/--health-maintained-days: %w", err)
+	}
+	if healthDetailsFlag || healthCheapFlag {
+		healthFlag = true // --health-details and --health-cheap imply --health
 	}
 
 	if healthFlag {
-		commitLister, ok := prov.(provider.CommitLister)
-		if !ok {
-			return fmt.Errorf("provider %s does not support health classification", providerName)
+		phaseStart := time.Now()
+		if !hasCommits {
+			return model.Report{}, nil, fmt.Errorf("provider %s does not support health classification", providerName)
 		}
 
 		fmt.Fprintln(os.Stderr, "Classifying repository health...")
@@ -572,29 +1675,35 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		now := time.Now().UTC()
-		healthResults := worker.RunWithProgress(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		healthResults := worker.RunWithProgress(ctx, repoList, apiConcurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+			// Cheap mode: the listing already told us when the repo was last
+			// pushed to, so skip the per-repo commit request entirely.
+			if healthCheapFlag && !repo.LastActivity.IsZero() {
+				return &model.RepoStats{
+					Repository: repo.Slug,
+					Health:     health.Classify(repo.LastActivity, now, healthThresholds),
+				}, nil
+			}
+
 			commits, err := commitLister.ListCommits(ctx, repo, commitLimit)
 			if err != nil {
+				// fail-fast and retry act on the error; failures left after
+				// the phase are classified as Failed below.
+				if onError != worker.Skip {
+					return nil, err
+				}
 				diagMu.Lock()
 				diagErrors = append(diagErrors, errorEntry{Category: "health", Repo: repo.Slug, Message: err.Error()})
 				diagMu.Unlock()
-				return &model.RepoStats{
-					Repository: repo.Slug,
-					Health: &model.RepoHealth{
-						Category:        model.HealthFailed,
-						DaysSinceCommit: -1,
-						Error:           err.Error(),
-					},
-				}, nil
+				return failedHealth(repo, err), nil
 			}
 
-			h := health.ClassifyFromCommits(commits, now)
+			h := health.ClassifyFromCommits(commits, now, healthThresholds)
 
 			var details *model.RepoHealthDetails
 			if healthDetailsFlag && len(commits) > 0 {
 				var partialErrs []string
-				details, partialErrs, err = health.AnalyzeDetails(ctx, commitLister, repo, commits, now)
+				details, partialErrs, err = health.AnalyzeDetails(ctx, commitLister, repo, commits, now, authorMap, velocityBand, healthWindows)
 				if len(partialErrs) > 0 {
 					diagMu.Lock()
 					for _, pe := range partialErrs {
@@ -618,7 +1727,7 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 				Health:        h,
 				HealthDetails: details,
 			}, nil
-		}, healthProgressFn)
+		}, healthProgressFn, onError)
 
 		if useTUI && program != nil {
 			program.Send(ui.DoneMsg{})
@@ -627,10 +1736,19 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			program = nil
 		}
 
+		if err := failFastError(onError, "health", healthResults); err != nil {
+			return model.Report{}, nil, err
+		}
+
 		// Attach health data to analysis results
 		healthByRepo := make(map[string]*model.RepoStats)
 		for _, r := range healthResults {
-			if r.Err == nil && r.Stats != nil {
+			if r.Err != nil {
+				diagErrors = append(diagErrors, errorEntry{Category: "health", Repo: r.Repo.Slug, Message: r.Err.Error()})
+				healthByRepo[r.Repo.Slug] = failedHealth(r.Repo, r.Err)
+				continue
+			}
+			if r.Stats != nil {
 				healthByRepo[r.Repo.Slug] = r.Stats
 			}
 		}
@@ -640,19 +1758,38 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 				if hs, ok := healthByRepo[results[i].Repo.Slug]; ok {
 					results[i].Stats.Health = hs.Health
 					results[i].Stats.HealthDetails = hs.HealthDetails
+					if hs.Health != nil {
+						results[i].Stats.LastCommitDate = hs.Health.LastCommitDate
+					}
 				}
 			}
 		}
+		recordPhase("health", phaseStart)
 	}
 
 	// Churn analysis phase
 	churnFlag, _ := cmd.Flags().GetBool("churn")
 	churnLimit, _ := cmd.Flags().GetInt("churn-limit")
+	var churnSince time.Time
+	if s, _ := cmd.Flags().GetString("churn-since"); s != "" {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return model.Report{}, nil, fmt.Errorf("--churn-since: expected YYYY-MM-DD, got %q", s)
+		}
+		churnSince = t
+		churnFlag = true // --churn-since implies --churn
+	}
+	ownershipFlag, _ := cmd.Flags().GetBool("code-ownership")
+	if ownershipFlag {
+		churnFlag = true // --code-ownership implies --churn
+	}
 
 	if churnFlag {
-		churnLister, ok := prov.(provider.ChurnLister)
-		if !ok {
-			return fmt.Errorf("provider %s does not support churn analysis", providerName)
+		phaseStart := time.Now()
+		// Providers without per-file commit stats still get commit-level
+		// churn; churn.Analyze flags the reduced result.
+		if !hasCommits {
+			return model.Report{}, nil, fmt.Errorf("provider %s does not support churn analysis", providerName)
 		}
 
 		fmt.Fprintln(os.Stderr, "Analyzing code churn...")
@@ -670,13 +1807,23 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		churnResults := worker.RunWithProgress(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
-			stats, err := churn.Analyze(ctx, churnLister, repo, churnLimit)
+		churnResults := worker.RunWithProgress(ctx, repoList, apiConcurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+			stats, err := churn.Analyze(ctx, commitLister, repo, churnLimit, churnSince, ownershipFlag, authorMap)
 			if err != nil {
 				return nil, err
 			}
+			if stats.FileStatsUnavailable {
+				diagMu.Lock()
+				diagErrors = append(diagErrors, errorEntry{Category: "churn", Repo: repo.Slug, Message: fmt.Sprintf("provider %s does not report per-file commit stats; churn is limited to the commit count", providerName)})
+				diagMu.Unlock()
+			}
+			if stats.StatsUnavailable {
+				diagMu.Lock()
+				diagErrors = append(diagErrors, errorEntry{Category: "churn", Repo: repo.Slug, Message: "provider returned 0 additions and 0 deletions for every changed file; churn line counts are unavailable, not zero"})
+				diagMu.Unlock()
+			}
 			return &model.RepoStats{Repository: repo.Slug, Churn: stats}, nil
-		}, churnProgressFn)
+		}, churnProgressFn, onError)
 
 		if useTUI && program != nil {
 			program.Send(ui.DoneMsg{})
@@ -685,165 +1832,832 @@ func runAnalyze(cmd *cobra.Command, args []string) error {
 			program = nil
 		}
 
-		churnByRepo := make(map[string]*model.ChurnStats)
-		for _, r := range churnResults {
-			if r.Err == nil && r.Stats != nil && r.Stats.Churn != nil {
-				churnByRepo[r.Repo.Slug] = r.Stats.Churn
-			}
-		}
-		for i := range results {
-			if results[i].Stats != nil {
-				if cs, ok := churnByRepo[results[i].Repo.Slug]; ok {
-					results[i].Stats.Churn = cs
-				}
-			}
+		if err := failFastError(onError, "churn", churnResults); err != nil {
+			return model.Report{}, nil, err
+		}
+
+		churnByRepo := make(map[string]*model.ChurnStats)
+		for _, r := range churnResults {
+			if r.Err == nil && r.Stats != nil && r.Stats.Churn != nil {
+				churnByRepo[r.Repo.Slug] = r.Stats.Churn
+			}
+		}
+		for i := range results {
+			if results[i].Stats != nil {
+				if cs, ok := churnByRepo[results[i].Repo.Slug]; ok {
+					results[i].Stats.Churn = cs
+				}
+			}
+		}
+		recordPhase("churn", phaseStart)
+	}
+
+	// Open issues phase
+	issuesFlag, _ := cmd.Flags().GetBool("issues")
+
+	if issuesFlag {
+		phaseStart := time.Now()
+		issueCounter, ok := prov.(provider.IssueCounter)
+		if !ok {
+			return model.Report{}, nil, fmt.Errorf("provider %s does not support issue counts", providerName)
+		}
+
+		fmt.Fprintln(os.Stderr, "Counting open issues...")
+
+		issuesProgressFn, issuesDone := phaseProgress(useTUI, len(repoList), "Issues")
+		issueResults := worker.RunWithProgress(ctx, repoList, apiConcurrency, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+			count, err := issueCounter.OpenIssues(ctx, repo)
+			if errors.Is(err, provider.ErrIssuesDisabled) {
+				return &model.RepoStats{Repository: repo.Slug}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return &model.RepoStats{Repository: repo.Slug, OpenIssues: &count}, nil
+		}, issuesProgressFn, onError)
+		issuesDone()
+		if err := failFastError(onError, "issues", issueResults); err != nil {
+			return model.Report{}, nil, err
+		}
+
+		issuesByRepo := make(map[string]*int)
+		for _, r := range issueResults {
+			if r.Err != nil {
+				diagErrors = append(diagErrors, errorEntry{Category: "issues", Repo: r.Repo.Slug, Message: r.Err.Error()})
+				continue
+			}
+			if r.Stats != nil && r.Stats.OpenIssues != nil {
+				issuesByRepo[r.Repo.Slug] = r.Stats.OpenIssues
+			}
+		}
+		for i := range results {
+			if results[i].Stats != nil {
+				if n, ok := issuesByRepo[results[i].Repo.Slug]; ok {
+					results[i].Stats.OpenIssues = n
+				}
+			}
+		}
+		recordPhase("issues", phaseStart)
+	}
+
+	// Use user/group as organization in metadata when set
+	reportOrg := org
+	if user != "" {
+		reportOrg = user
+	}
+	if group != "" {
+		reportOrg = group
+	}
+
+	allLanguages, _ := cmd.Flags().GetBool("all-languages")
+	minCode, _ := cmd.Flags().GetInt64("min-code")
+	runConfig := newRunConfig(cmd, timing.Phases, concurrency, apiConcurrency)
+	report := buildReport(providerName, workspace, reportOrg, projects, repos, exclude, results, now, allLanguages, minCode, runConfig)
+	report.Filters.ExcludeProjects = excludeProjects
+	report.Filters.ChangedSince = changedSince
+	if len(thresholdSpecs) > 0 {
+		report.ComplexityWarnings = complexity.Warnings(report.Repositories, thresholds)
+	}
+	if coAuthorFlag {
+		report.CoAuthorship = coauthor.Merge(report.Repositories)
+	}
+	if heatmapFlag {
+		report.ActivityHeatmap = activity.Merge(report.Repositories)
+	}
+	timing.TotalSeconds = roundSeconds(time.Since(analyzeStart))
+	report.Timing = timing
+
+	return report, diagErrors, nil
+}
+
+func newMarkdownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "markdown [file]",
+		Short: "Convert JSON report to markdown",
+		Long:  "Reads a JSON report from a file argument or stdin and writes markdown (or another --format) to stdout.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runMarkdown,
+	}
+
+	cmd.Flags().String("format", "markdown", "Output format: markdown, prometheus, ndjson, csv, or html")
+	cmd.Flags().Bool("all-languages", false, "Show languages with no code in the Languages table")
+	cmd.Flags().Int("top-languages", 0, "Show only the N languages with the most code in the Languages table, rolling the rest into an Other row (0 = all)")
+	cmd.Flags().Bool("narrative", false, "Generate AI narrative analysis instead of tables")
+	cmd.Flags().String("ai-cli", "", "AI CLI to use (claude, codex, gemini). Default: auto-detect")
+	cmd.Flags().String("ai-prompt", "", "Additional instructions for the AI narrative")
+	cmd.Flags().String("ai-prompt-file", "", "Read additional AI instructions from file")
+
+	return cmd
+}
+
+func runMarkdown(cmd *cobra.Command, args []string) error {
+	var r io.Reader = os.Stdin
+	if len(args) == 1 {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+
+	useNarrative, _ := cmd.Flags().GetBool("narrative")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "markdown" && format != "prometheus" && format != "ndjson" && format != "csv" && format != "html" {
+		return fmt.Errorf("--format must be 'markdown', 'prometheus', 'ndjson', 'csv', or 'html'")
+	}
+
+	if err := checkReportShape(data); err != nil {
+		return err
+	}
+
+	if useNarrative {
+		if format != "markdown" {
+			return fmt.Errorf("--narrative only supports the markdown format")
+		}
+		return runNarrative(cmd, data)
+	}
+
+	// Auto-detect report type: try TrendsReport first
+	var trends model.TrendsReport
+	if err := json.Unmarshal(data, &trends); err == nil && len(trends.Snapshots) > 0 {
+		if format != "markdown" {
+			return fmt.Errorf("--format %s is not supported for trends reports", format)
+		}
+		return output.WriteTrendsMarkdown(os.Stdout, trends)
+	}
+
+	var report model.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("parse JSON report: %w", err)
+	}
+
+	switch format {
+	case "prometheus":
+		return output.WritePrometheus(os.Stdout, report)
+	case "ndjson":
+		return output.WriteNDJSON(os.Stdout, report)
+	case "csv":
+		return output.WriteCSV(os.Stdout, report)
+	case "html":
+		return output.WriteHTML(os.Stdout, report)
+	}
+	var mdOpts []output.MarkdownOption
+	if allLanguages, _ := cmd.Flags().GetBool("all-languages"); allLanguages {
+		mdOpts = append(mdOpts, output.AllLanguages())
+	}
+	if top, _ := cmd.Flags().GetInt("top-languages"); top > 0 {
+		mdOpts = append(mdOpts, output.TopLanguages(top))
+	}
+	return output.WriteMarkdown(os.Stdout, report, mdOpts...)
+}
+
+// checkReportShape returns a descriptive error when data is not a single
+// analyze or trends JSON report, naming what it looks like instead: an
+// error log, an NDJSON stream, a single repository's stats, or an array.
+func checkReportShape(data []byte) error {
+	const expected = "expected a JSON report from 'codemium analyze' or 'codemium trends'"
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return fmt.Errorf("input is empty; %s", expected)
+	}
+	switch trimmed[0] {
+	case '{':
+	case '[':
+		if errorLogLine.Match(firstLine(trimmed)) {
+			return fmt.Errorf("input looks like an .error.log file; %s (the .json file written next to it)", expected)
+		}
+		return fmt.Errorf("input is a JSON array; %s (a single object)", expected)
+	default:
+		return fmt.Errorf("input is not JSON; %s", expected)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	var first map[string]json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return fmt.Errorf("parse JSON report: %w", err)
+	}
+	if dec.More() {
+		if string(first["type"]) == `"metadata"` {
+			return fmt.Errorf("input is an NDJSON stream (--format ndjson); %s, not NDJSON", expected)
+		}
+		return fmt.Errorf("input holds several JSON values; %s (a single object)", expected)
+	}
+	if _, ok := first["repositories"]; ok {
+		return nil
+	}
+	if _, ok := first["by_language"]; ok {
+		return nil // an analyze report written with --summary-only
+	}
+	if _, ok := first["snapshots"]; ok {
+		return nil
+	}
+	if _, ok := first["repository"]; ok {
+		return fmt.Errorf("input is a single repository's stats; %s, whose \"repositories\" list holds these entries", expected)
+	}
+	return fmt.Errorf("input has neither \"repositories\" nor \"snapshots\"; %s", expected)
+}
+
+// errorLogLine matches an analyze .error.log entry: "[category] repo | message".
+var errorLogLine = regexp.MustCompile(`^\[[\w+-]+\] .* \| `)
+
+// firstLine returns data up to the first newline.
+func firstLine(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[:i]
+	}
+	return data
+}
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a report over HTTP",
+		Long:  "Serves a JSON report as JSON at /api/report and as a rendered page at /, re-reading the file whenever it changes on disk.",
+		RunE:  runServe,
+	}
+
+	cmd.Flags().String("report", "", "Path to the JSON report (analyze or trends)")
+	cmd.Flags().String("addr", ":8080", "Address to listen on")
+	cmd.MarkFlagRequired("report")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer cancel()
+
+	reportPath, _ := cmd.Flags().GetString("report")
+	addr, _ := cmd.Flags().GetString("addr")
+
+	handler, err := serve.New(reportPath)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving %s on %s\n", reportPath, addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+func runNarrative(cmd *cobra.Command, data []byte) error {
+	aiCLI, _ := cmd.Flags().GetString("ai-cli")
+	aiPrompt, _ := cmd.Flags().GetString("ai-prompt")
+	aiPromptFile, _ := cmd.Flags().GetString("ai-prompt-file")
+
+	if aiPrompt != "" && aiPromptFile != "" {
+		return fmt.Errorf("--ai-prompt and --ai-prompt-file are mutually exclusive")
+	}
+
+	if aiPromptFile != "" {
+		content, err := os.ReadFile(aiPromptFile)
+		if err != nil {
+			return fmt.Errorf("read prompt file: %w", err)
+		}
+		aiPrompt = string(content)
+	}
+
+	if aiCLI == "" {
+		detected, err := narrative.DetectCLI()
+		if err != nil {
+			return err
+		}
+		aiCLI = detected
+		fmt.Fprintf(os.Stderr, "Using %s for narrative generation\n", aiCLI)
+	}
+
+	ctx := cmd.Context()
+	result, err := narrative.Generate(ctx, aiCLI, data, aiPrompt)
+	if err != nil {
+		return fmt.Errorf("narrative generation: %w", err)
+	}
+
+	fmt.Fprint(os.Stdout, result)
+	return nil
+}
+
+// phaseProgress starts the progress display for an analysis phase. It returns
+// the per-repo progress callback and a function that stops the display once
+// the phase has finished. label is used for the plain-text progress lines.
+func phaseProgress(useTUI bool, total int, label string) (worker.ProgressFunc, func()) {
+	var program *tea.Program
+	if useTUI {
+		program = ui.RunTUI(total)
+		go func() { program.Run() }()
+	}
+
+	progressFn := func(completed, total int, repo model.Repo) {
+		if program != nil {
+			program.Send(ui.ProgressMsg{Completed: completed, Total: total, RepoName: repo.Slug})
+		} else {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s %s\n", completed, total, label, repo.Slug)
+		}
+	}
+
+	done := func() {
+		if program != nil {
+			program.Send(ui.DoneMsg{})
+			time.Sleep(100 * time.Millisecond)
+			program.Quit()
+		}
+	}
+
+	return progressFn, done
+}
+
+// reportClock returns the timestamp to stamp into reports: --generated-at, then
+// CODEMIUM_NOW (both RFC 3339), falling back to the current time. Pinning it
+// keeps golden files and report diffs stable.
+func reportClock(cmd *cobra.Command) (time.Time, error) {
+	value, _ := cmd.Flags().GetString("generated-at")
+	source := "--generated-at"
+	if value == "" {
+		value = os.Getenv("CODEMIUM_NOW")
+		source = "CODEMIUM_NOW"
+	}
+	if value == "" {
+		return time.Now().UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: expected RFC 3339 (e.g. 2026-01-01T00:00:00Z)", source, value)
+	}
+	return t.UTC(), nil
+}
+
+// roundSeconds converts a duration to seconds rounded to milliseconds.
+func roundSeconds(d time.Duration) float64 {
+	return d.Round(time.Millisecond).Seconds()
+}
+
+// resolveConcurrency returns the named flag's value, or fallback when it is unset (<= 0).
+func resolveConcurrency(cmd *cobra.Command, name string, fallback int) int {
+	n, _ := cmd.Flags().GetInt(name)
+	if n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// newCloner creates a Cloner for the given credentials, honoring --keep-clones,
+// --clone-cache and --include-submodules.
+func newCloner(cmd *cobra.Command, cred auth.Credentials) *analyzer.Cloner {
+	var opts []analyzer.ClonerOption
+	if keepDir, _ := cmd.Flags().GetString("keep-clones"); keepDir != "" {
+		opts = append(opts, analyzer.WithKeepDir(keepDir))
+	}
+	if cacheDir, _ := cmd.Flags().GetString("clone-cache"); cacheDir != "" {
+		opts = append(opts, analyzer.WithCacheDir(cacheDir))
+	}
+	if submodules, _ := cmd.Flags().GetBool("include-submodules"); submodules {
+		opts = append(opts, analyzer.WithSubmodules())
+	}
+	return analyzer.NewCloner(cred.AccessToken, cred.Username, opts...)
+}
+
+// setRepoFields copies the listing's fields for repo into its stats.
+func setRepoFields(stats *model.RepoStats, repo model.Repo) {
+	stats.RepoSizeKB = repo.SizeKB
+	stats.Repository = repo.Slug
+	stats.Project = repo.Project
+	stats.Owner = repo.Owner
+	stats.Provider = repo.Provider
+	stats.URL = repo.URL
+}
+
+// analysisCacheFlags are the analyze flags that change a repository's
+// stats; their values make up the --cache-analysis variant.
+var analysisCacheFlags = []string{"language-override", "doc-extensions", "large-files", "large-file-size", "split-tests", "trace-skips", "ignore-repo-config", "no-ignore-files", "exclude-path", "languages", "exclude-languages", "include-submodules"}
+
+// newAnalysisCache returns the --cache-analysis cache, or nil when caching
+// is off. The variant covers analysisCacheFlags and the contents of the
+// --language-override file, so changing either misses the old entries.
+func newAnalysisCache(cmd *cobra.Command) (*analyzer.AnalysisCache, error) {
+	if on, _ := cmd.Flags().GetBool("cache-analysis"); !on {
+		return nil, nil
+	}
+	dir, _ := cmd.Flags().GetString("analysis-cache-dir")
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("--cache-analysis: %w (set --analysis-cache-dir)", err)
+		}
+		dir = filepath.Join(base, "codemium", "analysis")
+	}
+
+	var variant []string
+	for _, name := range analysisCacheFlags {
+		variant = append(variant, name+"="+cmd.Flags().Lookup(name).Value.String())
+	}
+	if path, _ := cmd.Flags().GetString("language-override"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read language overrides: %w", err)
 		}
+		variant = append(variant, string(data))
 	}
+	return analyzer.NewAnalysisCache(dir, strings.Join(variant, "\n")), nil
+}
 
-	// Write error.log if there were any diagnostic errors
-	if len(diagErrors) > 0 {
-		ext := filepath.Ext(outputPath)
-		errorLogPath := strings.TrimSuffix(outputPath, ext) + ".error.log"
-		if err := os.MkdirAll(filepath.Dir(errorLogPath), 0o755); err != nil {
-			return fmt.Errorf("create error log directory: %w", err)
-		}
-		f, err := os.Create(errorLogPath)
+// newAnalyzer builds the analyzer from --language-override and
+// --doc-extensions and, for analyze, --large-files, --split-tests,
+// --trace-skips, --exclude-path, --languages and --exclude-languages.
+func newAnalyzer(cmd *cobra.Command) (*analyzer.Analyzer, error) {
+	var opts []analyzer.Option
+	if path, _ := cmd.Flags().GetString("language-override"); path != "" {
+		overrides, err := analyzer.LoadLanguageOverrides(path)
 		if err != nil {
-			return fmt.Errorf("create error log: %w", err)
+			return nil, err
 		}
-		for _, e := range diagErrors {
-			fmt.Fprintf(f, "[%s] %s | %s\n", e.Category, e.Repo, e.Message)
+		opts = append(opts, analyzer.WithLanguageOverrides(overrides))
+	}
+	if exts, _ := cmd.Flags().GetStringSlice("doc-extensions"); len(exts) > 0 {
+		opts = append(opts, analyzer.WithDocExtensions(exts))
+	}
+	if largeFiles, _ := cmd.Flags().GetBool("large-files"); largeFiles {
+		sizeMB, _ := cmd.Flags().GetInt("large-file-size")
+		if sizeMB <= 0 {
+			return nil, fmt.Errorf("--large-file-size must be positive, got %d", sizeMB)
 		}
-		f.Close()
-		fmt.Fprintf(os.Stderr, "Error log written to %s (%d entries)\n", errorLogPath, len(diagErrors))
+		opts = append(opts, analyzer.WithLargeFiles(int64(sizeMB)<<20))
 	}
-
-	// Build report — use user/group as organization in metadata when set
-	reportOrg := org
-	if user != "" {
-		reportOrg = user
+	if splitTests, _ := cmd.Flags().GetBool("split-tests"); splitTests {
+		opts = append(opts, analyzer.WithSplitTests())
 	}
-	if group != "" {
-		reportOrg = group
+	if traceSkips, _ := cmd.Flags().GetBool("trace-skips"); traceSkips {
+		opts = append(opts, analyzer.WithTraceSkips())
 	}
-	report := buildReport(providerName, workspace, reportOrg, projects, repos, exclude, results)
-
-	// Write JSON output
-	var jsonWriter io.Writer = os.Stdout
-	if outputPath != "" {
-		if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
-			return fmt.Errorf("create output directory: %w", err)
+	if patterns, _ := cmd.Flags().GetStringArray("exclude-path"); len(patterns) > 0 {
+		for _, p := range patterns {
+			if err := analyzer.ValidateExcludePath(p); err != nil {
+				return nil, fmt.Errorf("--exclude-path: %w", err)
+			}
 		}
-		f, err := os.Create(outputPath)
-		if err != nil {
-			return fmt.Errorf("create output file: %w", err)
+		opts = append(opts, analyzer.WithExcludePaths(patterns))
+	}
+	for _, f := range []struct {
+		flag   string
+		option func([]string) analyzer.Option
+	}{
+		{"languages", analyzer.WithIncludeLanguages},
+		{"exclude-languages", analyzer.WithExcludeLanguages},
+	} {
+		langs, _ := cmd.Flags().GetStringSlice(f.flag)
+		if len(langs) == 0 {
+			continue
 		}
-		defer f.Close()
-		jsonWriter = f
+		for _, l := range langs {
+			if _, err := analyzer.ValidateLanguage(l); err != nil {
+				return nil, fmt.Errorf("--%s: %w", f.flag, err)
+			}
+		}
+		opts = append(opts, f.option(langs))
 	}
-	if err := output.WriteJSON(jsonWriter, report); err != nil {
-		return fmt.Errorf("write JSON: %w", err)
+	if ignore, _ := cmd.Flags().GetBool("ignore-repo-config"); ignore {
+		opts = append(opts, analyzer.WithoutRepoConfig())
+	}
+	if noIgnore, _ := cmd.Flags().GetBool("no-ignore-files"); noIgnore {
+		opts = append(opts, analyzer.WithoutIgnoreFiles())
+	}
+	return analyzer.New(opts...), nil
+}
+
+// jsonOptions returns the output.JSONOption values selected by --compact-json
+// and --summary-only.
+func jsonOptions(cmd *cobra.Command) []output.JSONOption {
+	var opts []output.JSONOption
+	if compact, _ := cmd.Flags().GetBool("compact-json"); compact {
+		opts = append(opts, output.Compact())
 	}
+	if summaryOnly, _ := cmd.Flags().GetBool("summary-only"); summaryOnly {
+		opts = append(opts, output.SummaryOnly())
+	}
+	return opts
+}
 
-	if outputPath != "" {
-		fmt.Fprintf(os.Stderr, "Report written to %s\n", outputPath)
+// failedHealth is the health result of a repo whose commit history couldn't
+// be fetched.
+func failedHealth(repo model.Repo, err error) *model.RepoStats {
+	return &model.RepoStats{
+		Repository: repo.Slug,
+		Health: &model.RepoHealth{
+			Category:        model.HealthFailed,
+			DaysSinceCommit: -1,
+			Error:           err.Error(),
+		},
 	}
+}
 
+// failFastError returns the error that aborts the run after a phase under
+// --on-error fail-fast, or nil when the run should continue.
+func failFastError(policy worker.ErrorPolicy, phase string, results []worker.Result) error {
+	if policy != worker.FailFast {
+		return nil
+	}
+	if err := worker.FirstError(results); err != nil {
+		return fmt.Errorf("%s failed (--on-error fail-fast): %w", phase, err)
+	}
 	return nil
 }
 
-func newMarkdownCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "markdown [file]",
-		Short: "Convert JSON report to markdown",
-		Long:  "Reads a JSON report from a file argument or stdin and writes markdown to stdout.",
-		Args:  cobra.MaximumNArgs(1),
-		RunE:  runMarkdown,
+// githubBaseURL resolves --github-url, then CODEMIUM_GITHUB_URL, into the
+// GitHub API base URL ("" for GitHub.com, .../api/v3 for Enterprise Server).
+func githubBaseURL(cmd *cobra.Command) (string, error) {
+	raw, _ := cmd.Flags().GetString("github-url")
+	if raw == "" {
+		raw = os.Getenv("CODEMIUM_GITHUB_URL")
 	}
+	baseURL, err := provider.GitHubAPIURL(raw)
+	if err != nil {
+		return "", fmt.Errorf("--github-url: %w", err)
+	}
+	return baseURL, nil
+}
 
-	cmd.Flags().Bool("narrative", false, "Generate AI narrative analysis instead of tables")
-	cmd.Flags().String("ai-cli", "", "AI CLI to use (claude, codex, gemini). Default: auto-detect")
-	cmd.Flags().String("ai-prompt", "", "Additional instructions for the AI narrative")
-	cmd.Flags().String("ai-prompt-file", "", "Read additional AI instructions from file")
+// notAuthenticated is the error for a provider without stored or environment
+// credentials. Azure DevOps has no login flow, only a PAT in the environment.
+func notAuthenticated(providerName string) error {
+	if providerName == "azure" {
+		return fmt.Errorf("not authenticated with azure — set CODEMIUM_AZURE_TOKEN to a personal access token")
+	}
+	return fmt.Errorf("not authenticated with %s — run 'codemium auth login --provider %s' first", providerName, providerName)
+}
 
-	return cmd
+// checkOnlyArchived rejects --only-archived for Bitbucket Cloud, whose
+// repository listing has no archived state, so it would list nothing.
+func checkOnlyArchived(onlyArchived bool, prov provider.Provider) error {
+	if _, cloud := prov.(*provider.Bitbucket); onlyArchived && cloud {
+		return fmt.Errorf("--only-archived is not supported for Bitbucket Cloud (its repository listing has no archived state)")
+	}
+	return nil
 }
 
-func runMarkdown(cmd *cobra.Command, args []string) error {
-	var r io.Reader = os.Stdin
-	if len(args) == 1 {
-		f, err := os.Open(args[0])
-		if err != nil {
-			return fmt.Errorf("open file: %w", err)
+// checkBitbucketRole validates --bitbucket-role, which only Bitbucket
+// Cloud's repository listing supports.
+func checkBitbucketRole(role string, prov provider.Provider) error {
+	if role == "" {
+		return nil
+	}
+	if _, cloud := prov.(*provider.Bitbucket); !cloud {
+		return fmt.Errorf("--bitbucket-role is only supported for Bitbucket Cloud")
+	}
+	for _, r := range provider.BitbucketRoles {
+		if r == role {
+			return nil
 		}
-		defer f.Close()
-		r = f
 	}
+	return fmt.Errorf("--bitbucket-role must be one of %s, got %q", strings.Join(provider.BitbucketRoles, ", "), role)
+}
 
-	data, err := io.ReadAll(r)
-	if err != nil {
-		return fmt.Errorf("read input: %w", err)
+// listHint adds a suggestion to a Bitbucket Cloud listing that failed with
+// a 403: a restricted token can usually still list the repos it can read
+// with --bitbucket-role member.
+func listHint(err error, prov provider.Provider, role string) error {
+	if _, cloud := prov.(*provider.Bitbucket); cloud && role == "" && errors.Is(err, provider.ErrForbidden) {
+		return fmt.Errorf("%w (retry with --bitbucket-role member to list only repositories the token can read)", err)
 	}
+	return err
+}
 
-	useNarrative, _ := cmd.Flags().GetBool("narrative")
+// missingRepos returns the requested repo slugs that match no listed repo,
+// in the order they were requested.
+func missingRepos(requested []string, listed []model.Repo) []string {
+	found := make(map[string]bool, len(listed))
+	for _, r := range listed {
+		found[r.Slug] = true
+	}
+	var missing []string
+	for _, slug := range requested {
+		if !found[slug] {
+			missing = append(missing, slug)
+		}
+	}
+	return missing
+}
 
-	if useNarrative {
-		return runNarrative(cmd, data)
+// mostRecent returns the n repos with the latest LastActivity, newest
+// first, and how many repos had no activity time. Undated repos sort after
+// dated ones and keep their listing order.
+func mostRecent(repos []model.Repo, n int) ([]model.Repo, int) {
+	sorted := append([]model.Repo(nil), repos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastActivity.After(sorted[j].LastActivity)
+	})
+	var undated int
+	for _, r := range repos {
+		if r.LastActivity.IsZero() {
+			undated++
+		}
+	}
+	if n < len(sorted) {
+		sorted = sorted[:n]
 	}
+	return sorted, undated
+}
 
-	// Auto-detect report type: try TrendsReport first
-	var trends model.TrendsReport
-	if err := json.Unmarshal(data, &trends); err == nil && len(trends.Snapshots) > 0 {
-		return output.WriteTrendsMarkdown(os.Stdout, trends)
+// listScopes lists the repositories of each Bitbucket workspace or GitLab
+// group in turn (GitLab groups are passed as the organization) and combines
+// them. With more than one scope, each repo's Owner records where it was
+// listed from. Without any, the listing runs once with opts unchanged.
+func listScopes(ctx context.Context, prov provider.Provider, opts provider.ListOpts, workspaces, groups []string) ([]model.Repo, error) {
+	scopes := workspaces
+	if len(groups) > 0 {
+		scopes = groups
+	}
+	if len(scopes) == 0 {
+		repoList, err := prov.ListRepos(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list repos: %w", err)
+		}
+		return repoList, nil
 	}
 
-	var report model.Report
-	if err := json.Unmarshal(data, &report); err != nil {
-		return fmt.Errorf("parse JSON report: %w", err)
+	var all []model.Repo
+	for _, scope := range scopes {
+		scopeOpts := opts
+		if len(groups) > 0 {
+			scopeOpts.Organization = scope
+		} else {
+			scopeOpts.Workspace = scope
+		}
+		repoList, err := prov.ListRepos(ctx, scopeOpts)
+		if err != nil {
+			if len(scopes) == 1 {
+				return nil, fmt.Errorf("list repos: %w", err)
+			}
+			return nil, fmt.Errorf("list repos in %s: %w", scope, err)
+		}
+		if len(scopes) > 1 {
+			for i := range repoList {
+				repoList[i].Owner = scope
+			}
+		}
+		all = append(all, repoList...)
+	}
+	return all, nil
+}
+
+// newBitbucketProvider returns the Bitbucket Cloud provider, or the Bitbucket
+// Server provider when CODEMIUM_BITBUCKET_URL points at a self-hosted
+// instance. Workspaces only exist on Cloud, so --workspace is only required
+// there.
+func newBitbucketProvider(workspace string, cred auth.Credentials, client *http.Client) (provider.Provider, error) {
+	baseURL := os.Getenv("CODEMIUM_BITBUCKET_URL")
+	if provider.IsBitbucketServerURL(baseURL) {
+		return provider.NewBitbucketServer(cred.AccessToken, cred.Username, baseURL, client), nil
 	}
+	if workspace == "" {
+		return nil, fmt.Errorf("--workspace is required for bitbucket")
+	}
+	return provider.NewBitbucket(cred.AccessToken, cred.Username, "", client), nil
+}
 
-	return output.WriteMarkdown(os.Stdout, report)
+// expandOutputPath replaces the {date}, {provider}, {org} and {workspace}
+// placeholders in an output path. Path separators in substituted values are
+// replaced with dashes so nested GitLab groups don't create subdirectories.
+// Paths without placeholders are returned unchanged.
+func expandOutputPath(path, providerName, org, workspace string, now time.Time) string {
+	if !strings.Contains(path, "{") {
+		return path
+	}
+	clean := func(v string) string {
+		return strings.NewReplacer("/", "-", "\\", "-").Replace(v)
+	}
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{provider}", clean(providerName),
+		"{org}", clean(org),
+		"{workspace}", clean(workspace),
+	).Replace(path)
 }
 
-func runNarrative(cmd *cobra.Command, data []byte) error {
-	aiCLI, _ := cmd.Flags().GetString("ai-cli")
-	aiPrompt, _ := cmd.Flags().GetString("ai-prompt")
-	aiPromptFile, _ := cmd.Flags().GetString("ai-prompt-file")
+// Thresholds for classifying a repository as a monorepo. A language is
+// significant when it holds at least monorepoLanguageShare of the repo's code.
+const (
+	monorepoLanguageShare   = 0.05
+	monorepoMinLanguages    = 3
+	monorepoMinTopLevelDirs = 8
+)
 
-	if aiPrompt != "" && aiPromptFile != "" {
-		return fmt.Errorf("--ai-prompt and --ai-prompt-file are mutually exclusive")
+// classifyStructure labels a repo "monorepo" when it has several significant
+// languages spread over many top-level directories, and "focused" otherwise.
+func classifyStructure(stats *model.RepoStats) string {
+	if stats.Totals.Code == 0 {
+		return ""
 	}
-
-	if aiPromptFile != "" {
-		content, err := os.ReadFile(aiPromptFile)
-		if err != nil {
-			return fmt.Errorf("read prompt file: %w", err)
+	significant := 0
+	for _, lang := range stats.Languages {
+		if float64(lang.Code)/float64(stats.Totals.Code) >= monorepoLanguageShare {
+			significant++
 		}
-		aiPrompt = string(content)
 	}
+	if significant >= monorepoMinLanguages && stats.TopLevelDirs >= monorepoMinTopLevelDirs {
+		return "monorepo"
+	}
+	return "focused"
+}
 
-	if aiCLI == "" {
-		detected, err := narrative.DetectCLI()
-		if err != nil {
-			return err
+// infraLanguages are the config, build and markup languages (scc names) that
+// make up a CI/config-only repository.
+var infraLanguages = map[string]bool{
+	"YAML": true, "JSON": true, "TOML": true, "XML": true, "INI": true,
+	"HCL": true, "Terraform": true, "Dockerfile": true, "Makefile": true,
+	"Jsonnet": true, "Jinja": true, "Properties File": true,
+	"Markdown": true, model.DocumentationLanguage: true,
+}
+
+// infraOnlyMaxOtherShare is the largest share of a repo's code that may be
+// in other languages for it to still count as infra-only.
+const infraOnlyMaxOtherShare = 0.05
+
+// isInfraOnly reports whether a repo's code is almost entirely in
+// infraLanguages, with no more than infraOnlyMaxOtherShare in anything else.
+func isInfraOnly(stats *model.RepoStats) bool {
+	if stats.Totals.Code == 0 {
+		return false
+	}
+	var other int64
+	for _, lang := range stats.Languages {
+		if !infraLanguages[lang.Name] {
+			other += lang.Code
 		}
-		aiCLI = detected
-		fmt.Fprintf(os.Stderr, "Using %s for narrative generation\n", aiCLI)
 	}
+	return float64(other)/float64(stats.Totals.Code) <= infraOnlyMaxOtherShare
+}
 
-	ctx := cmd.Context()
-	result, err := narrative.Generate(ctx, aiCLI, data, aiPrompt)
-	if err != nil {
-		return fmt.Errorf("narrative generation: %w", err)
+// setCodePercent fills in each language's share of totalCode. It leaves the
+// percentages at zero when there is no code to divide by.
+func setCodePercent(langs []model.LanguageStats, totalCode int64) {
+	if totalCode <= 0 {
+		return
 	}
+	for i := range langs {
+		langs[i].CodePercent = float64(langs[i].Code) / float64(totalCode) * 100
+	}
+}
 
-	fmt.Fprint(os.Stdout, result)
-	return nil
+// newRunConfig records the phases that ran (in order, from the timing
+// entries), the limits and thresholds those phases used, and every flag set
+// on the command line. buildReport fills in the target.
+func newRunConfig(cmd *cobra.Command, phases []model.PhaseTiming, concurrency, apiConcurrency int) *model.RunConfig {
+	cfg := &model.RunConfig{
+		Phases:         []string{},
+		Concurrency:    concurrency,
+		APIConcurrency: apiConcurrency,
+	}
+	for _, p := range phases {
+		cfg.Phases = append(cfg.Phases, p.Phase)
+		switch p.Phase {
+		case "ai", "commits":
+			cfg.AICommitLimit, _ = cmd.Flags().GetInt("ai-commit-limit")
+		case "churn":
+			cfg.ChurnLimit, _ = cmd.Flags().GetInt("churn-limit")
+		case "health":
+			cfg.HealthActiveDays, _ = cmd.Flags().GetInt("health-active-days")
+			cfg.HealthMaintainedDays, _ = cmd.Flags().GetInt("health-maintained-days")
+			if details, _ := cmd.Flags().GetBool("health-details"); details {
+				cfg.VelocityBand, _ = cmd.Flags().GetFloat64("velocity-band")
+				cfg.HealthWindows, _ = cmd.Flags().GetIntSlice("health-windows")
+			}
+		}
+	}
+	cfg.ComplexityThresholds, _ = cmd.Flags().GetStringSlice("complexity-threshold")
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if cfg.Flags == nil {
+			cfg.Flags = map[string]string{}
+		}
+		cfg.Flags[f.Name] = f.Value.String()
+	})
+	return cfg
 }
 
-func buildReport(providerName, workspace, org string, projects, repos, exclude []string, results []worker.Result) model.Report {
+// buildReport aggregates worker results into a report. Languages with no
+// code (only comments/blanks, or data formats) are left out of ByLanguage
+// unless allLanguages is set; their files still count in Totals.
+// Repositories with fewer than minCode lines of code are dropped from the
+// report entirely and only counted in Totals.FilteredRepos.
+func buildReport(providerName, workspace, org string, projects, repos, exclude []string, results []worker.Result, now time.Time, allLanguages bool, minCode int64, runConfig *model.RunConfig) model.Report {
+	if runConfig != nil {
+		runConfig.Provider, runConfig.Workspace, runConfig.Organization = providerName, workspace, org
+	}
 	report := model.Report{
-		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+		GeneratedAt:  now.UTC().Format(time.RFC3339),
 		Provider:     providerName,
 		Workspace:    workspace,
 		Organization: org,
@@ -851,7 +2665,9 @@ func buildReport(providerName, workspace, org string, projects, repos, exclude [
 			Projects: projects,
 			Repos:    repos,
 			Exclude:  exclude,
+			MinCode:  minCode,
 		},
+		RunConfig: runConfig,
 	}
 
 	langTotals := map[string]*model.LanguageStats{}
@@ -864,7 +2680,13 @@ func buildReport(providerName, workspace, org string, projects, repos, exclude [
 			})
 			continue
 		}
+		if r.Stats.Totals.Code < minCode {
+			report.Totals.FilteredRepos++
+			continue
+		}
 
+		r.Stats.Structure = classifyStructure(r.Stats)
+		r.Stats.IsInfraOnly = isInfraOnly(r.Stats)
 		report.Repositories = append(report.Repositories, *r.Stats)
 		report.Totals.Repos++
 		report.Totals.Files += r.Stats.Totals.Files
@@ -891,6 +2713,9 @@ func buildReport(providerName, workspace, org string, projects, repos, exclude [
 	}
 
 	for _, lt := range langTotals {
+		if lt.Code == 0 && !allLanguages {
+			continue
+		}
 		report.ByLanguage = append(report.ByLanguage, *lt)
 	}
 
@@ -899,33 +2724,59 @@ func buildReport(providerName, workspace, org string, projects, repos, exclude [
 		return report.ByLanguage[i].Code > report.ByLanguage[j].Code
 	})
 
+	setCodePercent(report.ByLanguage, report.Totals.Code)
+	for i := range report.Repositories {
+		setCodePercent(report.Repositories[i].Languages, report.Repositories[i].Totals.Code)
+	}
+
 	// Aggregate AI estimates
 	var hasAI bool
-	var totalCommits, aiCommits, aiAdditions int64
-	for _, r := range results {
-		if r.Err != nil || r.Stats == nil || r.Stats.AIEstimate == nil {
+	var totalCommits, aiCommits, totalAdditions, aiAdditions int64
+	signalCounts := map[model.AISignal]int64{}
+	for _, r := range report.Repositories {
+		if r.AIEstimate == nil {
 			continue
 		}
 		hasAI = true
-		totalCommits += r.Stats.AIEstimate.TotalCommits
-		aiCommits += r.Stats.AIEstimate.AICommits
-		aiAdditions += r.Stats.AIEstimate.AIAdditions
+		totalCommits += r.AIEstimate.TotalCommits
+		aiCommits += r.AIEstimate.AICommits
+		aiAdditions += r.AIEstimate.AIAdditions
+		// A repo whose AI additions are unknown would skew the share.
+		if !r.AIEstimate.AdditionsUnavailable {
+			totalAdditions += r.AIEstimate.TotalAdditions
+		}
+		for _, c := range r.AIEstimate.Details {
+			for _, sig := range c.Signals {
+				signalCounts[sig]++
+			}
+		}
 	}
 	if hasAI {
 		var commitPct float64
 		if totalCommits > 0 {
 			commitPct = float64(aiCommits) / float64(totalCommits) * 100
 		}
+		var additionPct float64
+		if totalAdditions > 0 {
+			additionPct = float64(aiAdditions) / float64(totalAdditions) * 100
+		}
 		report.AIEstimate = &model.AIEstimate{
-			TotalCommits:  totalCommits,
-			AICommits:     aiCommits,
-			CommitPercent: commitPct,
-			AIAdditions:   aiAdditions,
+			TotalCommits:    totalCommits,
+			AICommits:       aiCommits,
+			CommitPercent:   commitPct,
+			TotalAdditions:  totalAdditions,
+			AIAdditions:     aiAdditions,
+			AdditionPercent: additionPct,
+		}
+		if len(signalCounts) > 0 {
+			report.AIEstimate.SignalCounts = signalCounts
 		}
 	}
 
-	// Aggregate health summary
+	// Aggregate health summary and decommission candidates
 	report.HealthSummary = health.Summarize(report.Repositories)
+	report.RiskRepos = health.RiskRepos(report.Repositories)
+	report.Distribution = complexity.Distribution(report.Repositories)
 
 	return report
 }
@@ -937,21 +2788,37 @@ func newTrendsCmd() *cobra.Command {
 		RunE:  runTrends,
 	}
 
-	cmd.Flags().String("provider", "", "Provider (bitbucket, github, gitlab)")
+	cmd.Flags().String("provider", "", "Provider (bitbucket, github, gitlab, azure)")
 	cmd.Flags().String("workspace", "", "Bitbucket workspace slug")
-	cmd.Flags().String("org", "", "GitHub organization")
+	cmd.Flags().String("org", "", "GitHub organization, or Azure DevOps organization")
 	cmd.Flags().String("user", "", "GitHub user (alternative to --org for personal repos)")
+	cmd.Flags().String("github-url", "", "GitHub Enterprise Server URL, e.g. https://github.mycorp.com (env: CODEMIUM_GITHUB_URL; default: github.com)")
 	cmd.Flags().String("group", "", "GitLab group path or ID")
-	cmd.Flags().String("since", "", "Start period (YYYY-MM for monthly, YYYY-MM-DD for weekly)")
-	cmd.Flags().String("until", "", "End period (YYYY-MM for monthly, YYYY-MM-DD for weekly)")
-	cmd.Flags().String("interval", "monthly", "Interval: monthly or weekly")
+	cmd.Flags().String("project", "", "Azure DevOps project (default: every project in --org)")
+	cmd.Flags().String("since", "", "Start period (YYYY-MM for monthly and quarterly, YYYY-MM-DD for weekly, YYYY for yearly), or \"auto\" to start each repo at its first commit")
+	cmd.Flags().String("until", "", "End period (YYYY-MM for monthly and quarterly, YYYY-MM-DD for weekly, YYYY for yearly)")
+	cmd.Flags().String("interval", "monthly", "Interval: monthly, weekly, quarterly or yearly")
+	cmd.Flags().String("week-anchor", "since", "Weekly period alignment: since (every 7 days from --since) or iso (ISO weeks ending Sunday, labeled 2025-W07)")
 	cmd.Flags().StringSlice("repos", nil, "Filter to specific repo names")
 	cmd.Flags().StringSlice("exclude", nil, "Exclude specific repos")
 	cmd.Flags().Bool("include-archived", false, "Include archived repos")
+	cmd.Flags().Bool("only-archived", false, "Only include archived repos (GitHub, GitLab and Bitbucket Server)")
+	cmd.Flags().String("bitbucket-role", "", "Bitbucket Cloud only: list just the repos the token has at least this role on (member, contributor, admin or owner)")
 	cmd.Flags().Bool("include-forks", false, "Include forked repos")
-	cmd.Flags().Int("concurrency", 5, "Number of parallel workers")
-	cmd.Flags().String("output", "output/report.json", "Write JSON to file")
+	cmd.Flags().Int("concurrency", 0, "Number of parallel workers (0 = auto: number of CPUs)")
+	cmd.Flags().Int("period-concurrency", 1, "Number of periods of one repository analyzed in parallel, each in its own temporary worktree")
+	cmd.Flags().String("output", "output/report.json", "Write JSON to file (supports {date}, {provider}, {org}, {workspace} placeholders)")
+	cmd.Flags().Bool("compact-json", false, "Write the JSON report without indentation")
+	cmd.Flags().String("markdown-out", "", "Also write the markdown rendering of the report to this file (supports the same placeholders as --output)")
+	cmd.Flags().String("generated-at", "", "Override the report timestamp (RFC 3339, e.g. 2026-01-01T00:00:00Z; env: CODEMIUM_NOW)")
 	cmd.Flags().Float64("rate-limit", 0, "Max API requests per second (0 = unlimited)")
+	cmd.Flags().Duration("http-timeout", provider.DefaultHTTPTimeout, "Deadline for each provider API request attempt, including reading the response (0 = none)")
+	cmd.Flags().String("keep-clones", "", "Clone into <dir>/<host>/<owner>/<repo> and keep the working trees after analysis")
+	cmd.Flags().String("clone-cache", "", "Keep bare clones in <dir> across runs and fetch into them instead of cloning again")
+	cmd.Flags().String("language-override", "", "File of \"pattern = Language\" lines (.ext or file name) overriding scc's language detection")
+	cmd.Flags().StringSlice("doc-extensions", nil, "File extensions to count as the Documentation pseudo-language (e.g. .mdx,.adoc,.md.tmpl)")
+	cmd.Flags().Bool("ignore-repo-config", false, "Ignore each repository's own .codemium.yaml (area, exclude_paths, ignore_languages)")
+	cmd.Flags().Bool("no-ignore-files", false, "Count files matched by the repository's .gitignore files and .codemiumignore")
 
 	cmd.MarkFlagRequired("provider")
 	cmd.MarkFlagRequired("since")
@@ -969,25 +2836,48 @@ func runTrends(cmd *cobra.Command, args []string) error {
 	org, _ := cmd.Flags().GetString("org")
 	user, _ := cmd.Flags().GetString("user")
 	group, _ := cmd.Flags().GetString("group")
+	project, _ := cmd.Flags().GetString("project")
 	since, _ := cmd.Flags().GetString("since")
 	until, _ := cmd.Flags().GetString("until")
 	interval, _ := cmd.Flags().GetString("interval")
 	repos, _ := cmd.Flags().GetStringSlice("repos")
 	exclude, _ := cmd.Flags().GetStringSlice("exclude")
 	includeArchived, _ := cmd.Flags().GetBool("include-archived")
+	onlyArchived, _ := cmd.Flags().GetBool("only-archived")
+	bitbucketRole, _ := cmd.Flags().GetString("bitbucket-role")
 	includeForks, _ := cmd.Flags().GetBool("include-forks")
-	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	// Trends checks out and scans every period locally, so it is CPU/disk bound
+	concurrency := resolveConcurrency(cmd, "concurrency", worker.DefaultConcurrency(worker.CPUBound))
+	periodConcurrency, _ := cmd.Flags().GetInt("period-concurrency")
 	outputPath, _ := cmd.Flags().GetString("output")
 	rateLimit, _ := cmd.Flags().GetFloat64("rate-limit")
 
-	if interval != "monthly" && interval != "weekly" {
-		return fmt.Errorf("--interval must be 'monthly' or 'weekly'")
+	if periodConcurrency < 1 {
+		return fmt.Errorf("--period-concurrency must be at least 1")
+	}
+	if interval != "monthly" && interval != "weekly" && interval != "quarterly" && interval != "yearly" {
+		return fmt.Errorf("--interval must be 'monthly', 'weekly', 'quarterly' or 'yearly'")
+	}
+	weekAnchor, _ := cmd.Flags().GetString("week-anchor")
+	if weekAnchor != "since" && weekAnchor != "iso" {
+		return fmt.Errorf("--week-anchor must be 'since' or 'iso'")
+	}
+	// periodInterval is the history interval the periods are generated with;
+	// ISO-anchored weeks are their own interval there.
+	periodInterval := interval
+	if interval == "weekly" && weekAnchor == "iso" {
+		periodInterval = history.ISOWeekly
+	}
+
+	now, err := reportClock(cmd)
+	if err != nil {
+		return err
 	}
 
 	store := auth.NewFileStore(auth.DefaultStorePath())
 	cred, err := store.LoadWithEnv(providerName)
 	if err != nil {
-		return fmt.Errorf("not authenticated with %s — run 'codemium auth login --provider %s' first", providerName, providerName)
+		return notAuthenticated(providerName)
 	}
 
 	if cred.Expired() && cred.RefreshToken != "" {
@@ -1001,15 +2891,16 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		store.Save(providerName, cred)
 	}
 
-	httpClient := &http.Client{Transport: &provider.RateLimitTransport{ReqPerSec: rateLimit}}
+	httpTimeout, _ := cmd.Flags().GetDuration("http-timeout")
+	httpClient := &http.Client{Transport: &provider.RateLimitTransport{ReqPerSec: rateLimit, Timeout: httpTimeout}}
 
 	var prov provider.Provider
 	switch providerName {
 	case "bitbucket":
-		if workspace == "" {
-			return fmt.Errorf("--workspace is required for bitbucket")
+		prov, err = newBitbucketProvider(workspace, cred, httpClient)
+		if err != nil {
+			return err
 		}
-		prov = provider.NewBitbucket(cred.AccessToken, cred.Username, "", httpClient)
 	case "github":
 		if org != "" && user != "" {
 			return fmt.Errorf("--org and --user are mutually exclusive for github")
@@ -1017,35 +2908,60 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		if org == "" && user == "" {
 			return fmt.Errorf("--org or --user is required for github")
 		}
-		prov = provider.NewGitHub(cred.AccessToken, "", httpClient)
+		baseURL, err := githubBaseURL(cmd)
+		if err != nil {
+			return err
+		}
+		prov = provider.NewGitHub(cred.AccessToken, baseURL, httpClient)
 	case "gitlab":
 		if group == "" {
 			return fmt.Errorf("--group is required for gitlab")
 		}
 		baseURL := os.Getenv("CODEMIUM_GITLAB_URL")
 		prov = provider.NewGitLab(cred.AccessToken, baseURL, httpClient)
+	case "azure":
+		if org == "" {
+			return fmt.Errorf("--org is required for azure")
+		}
+		baseURL := os.Getenv("CODEMIUM_AZURE_URL")
+		prov = provider.NewAzureDevOps(cred.AccessToken, org, baseURL, httpClient)
 	default:
 		return fmt.Errorf("unsupported provider: %s", providerName)
 	}
 
+	if err := checkOnlyArchived(onlyArchived, prov); err != nil {
+		return err
+	}
+	if err := checkBitbucketRole(bitbucketRole, prov); err != nil {
+		return err
+	}
+
 	// For GitLab, pass group as Organization
 	trendsOrg := org
 	if group != "" {
 		trendsOrg = group
 	}
 
+	var trendsProjects []string
+	if project != "" {
+		trendsProjects = []string{project}
+	}
+
 	fmt.Fprintln(os.Stderr, "Listing repositories...")
 	repoList, err := prov.ListRepos(ctx, provider.ListOpts{
 		Workspace:       workspace,
 		Organization:    trendsOrg,
 		User:            user,
+		Projects:        trendsProjects,
 		Repos:           repos,
 		Exclude:         exclude,
 		IncludeArchived: includeArchived,
+		OnlyArchived:    onlyArchived,
 		IncludeForks:    includeForks,
+		Role:            bitbucketRole,
 	})
 	if err != nil {
-		return fmt.Errorf("list repos: %w", err)
+		return fmt.Errorf("list repos: %w", listHint(err, prov, bitbucketRole))
 	}
 	if len(repoList) == 0 {
 		return fmt.Errorf("no repositories found")
@@ -1053,22 +2969,35 @@ func runTrends(cmd *cobra.Command, args []string) error {
 
 	// Trends requires full git clone for history — Bitbucket API tokens
 	// only support tarball downloads, not git operations.
-	if providerName == "bitbucket" && cred.Username != "" {
+	// Bitbucket Server clones over basic auth, so only Cloud is affected.
+	if _, server := prov.(*provider.BitbucketServer); providerName == "bitbucket" && !server && cred.Username != "" {
 		return fmt.Errorf("trends requires OAuth credentials for Bitbucket (API tokens cannot clone git history)\nSet CODEMIUM_BITBUCKET_CLIENT_ID and CODEMIUM_BITBUCKET_CLIENT_SECRET, then run: codemium auth login --provider bitbucket")
 	}
 
-	dates := history.GenerateDates(since, until, interval)
-	if len(dates) == 0 {
-		return fmt.Errorf("no periods generated for --since %s --until %s --interval %s", since, until, interval)
+	// With --since auto each repo gets its own dates, starting at its first
+	// commit; the report's periods are the union of all of them.
+	autoSince := since == "auto"
+	var dates []time.Time
+	var periods []string
+	if autoSince {
+		if len(history.GenerateDates(until, until, periodInterval)) == 0 {
+			return fmt.Errorf("no periods generated for --until %s --interval %s", until, interval)
+		}
+		fmt.Fprintf(os.Stderr, "Found %d repositories, analyzing %s periods from each repo's first commit\n", len(repoList), interval)
+	} else {
+		dates = history.GenerateDates(since, until, periodInterval)
+		if len(dates) == 0 {
+			return fmt.Errorf("no periods generated for --since %s --until %s --interval %s", since, until, interval)
+		}
+		periods = formatPeriods(dates, periodInterval)
+		fmt.Fprintf(os.Stderr, "Found %d repositories, analyzing %d %s periods\n", len(repoList), len(dates), interval)
 	}
 
-	periods := make([]string, len(dates))
-	for i, d := range dates {
-		periods[i] = history.FormatPeriod(d, interval)
+	codeAnalyzer, err := newAnalyzer(cmd)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "Found %d repositories, analyzing %d %s periods\n", len(repoList), len(dates), interval)
-
 	useTUI := ui.IsTTY()
 	var program *tea.Program
 	if useTUI {
@@ -1076,8 +3005,7 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		go func() { program.Run() }()
 	}
 
-	cloner := analyzer.NewCloner(cred.AccessToken, cred.Username)
-	codeAnalyzer := analyzer.New()
+	cloner := newCloner(cmd, cred)
 
 	progressFn := func(completed, total int, repo model.Repo) {
 		if useTUI && program != nil {
@@ -1092,30 +3020,47 @@ func runTrends(cmd *cobra.Command, args []string) error {
 	}
 
 	results := worker.RunTrends(ctx, repoList, concurrency, func(ctx context.Context, repo model.Repo) (map[string]*model.RepoStats, error) {
-		gitRepo, dir, cleanup, err := cloner.CloneFull(ctx, repo.CloneURL)
+		gitRepo, _, cleanup, err := cloner.CloneFull(ctx, repo.CloneURL)
 		if err != nil {
 			return nil, err
 		}
 		defer cleanup()
 
-		commitMap, err := history.FindCommits(gitRepo, dates)
+		repoDates, repoPeriods := dates, periods
+		if autoSince {
+			first, err := history.FirstCommit(gitRepo)
+			if err != nil {
+				return nil, fmt.Errorf("find first commit: %w", err)
+			}
+			repoDates = history.GenerateDates(history.AutoSince(first, until, periodInterval), until, periodInterval)
+			repoPeriods = formatPeriods(repoDates, periodInterval)
+		}
+
+		commitMap, err := history.FindCommits(gitRepo, repoDates)
 		if err != nil {
 			return nil, fmt.Errorf("find commits: %w", err)
 		}
 
-		snapshots := make(map[string]*model.RepoStats, len(dates))
-		for i, date := range dates {
-			hash, ok := commitMap[date]
-			if !ok {
-				continue
+		// Periods are analyzed in separate worktrees, up to
+		// --period-concurrency at a time; a commit shared by several periods
+		// is analyzed once.
+		hashes := make([]plumbing.Hash, 0, len(repoDates))
+		for _, date := range repoDates {
+			if hash, ok := commitMap[date]; ok {
+				hashes = append(hashes, hash)
 			}
+		}
+		analyzed, analyzeErr := codeAnalyzer.AnalyzeCommits(ctx, gitRepo, hashes, periodConcurrency)
 
-			if err := analyzer.Checkout(gitRepo, dir, hash); err != nil {
+		snapshots := make(map[string]*model.RepoStats, len(repoDates))
+		for i, date := range repoDates {
+			hash, ok := commitMap[date]
+			if !ok {
+				snapshots[repoPeriods[i]] = nil // no commit yet at this date
 				continue
 			}
-
-			stats, err := codeAnalyzer.Analyze(ctx, dir)
-			if err != nil {
+			stats, ok := analyzed[hash]
+			if !ok {
 				continue
 			}
 
@@ -1123,9 +3068,14 @@ func runTrends(cmd *cobra.Command, args []string) error {
 			stats.Project = repo.Project
 			stats.Provider = repo.Provider
 			stats.URL = repo.URL
-			snapshots[periods[i]] = stats
+			snapshots[repoPeriods[i]] = stats
 		}
 
+		// Periods that failed are missing from snapshots; the error says
+		// which commits, and buildTrendsReport keeps the rest.
+		if analyzeErr != nil {
+			return snapshots, fmt.Errorf("analyze periods: %w", analyzeErr)
+		}
 		return snapshots, nil
 	}, progressFn)
 
@@ -1142,7 +3092,14 @@ func runTrends(cmd *cobra.Command, args []string) error {
 	if group != "" {
 		reportOrg = group
 	}
-	report := buildTrendsReport(providerName, workspace, reportOrg, since, until, interval, periods, repos, exclude, results)
+	if autoSince {
+		periods = unionPeriods(results)
+	}
+	report := buildTrendsReport(providerName, workspace, reportOrg, since, until, interval, periods, repos, exclude, results, now)
+	if periodInterval == history.ISOWeekly {
+		report.WeekAnchor = weekAnchor
+	}
+	outputPath = expandOutputPath(outputPath, providerName, reportOrg, workspace, now)
 
 	var jsonWriter io.Writer = os.Stdout
 	if outputPath != "" {
@@ -1157,7 +3114,7 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		jsonWriter = f
 	}
 
-	if err := output.WriteTrendsJSON(jsonWriter, report); err != nil {
+	if err := output.WriteTrendsJSON(jsonWriter, report, jsonOptions(cmd)...); err != nil {
 		return err
 	}
 
@@ -1165,12 +3122,40 @@ func runTrends(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Report written to %s\n", outputPath)
 	}
 
-	return nil
+	return writeMarkdownOut(cmd, providerName, reportOrg, workspace, now, func(w io.Writer) error {
+		return output.WriteTrendsMarkdown(w, report)
+	})
+}
+
+// formatPeriods formats each date as a trends period label.
+func formatPeriods(dates []time.Time, interval string) []string {
+	periods := make([]string, len(dates))
+	for i, d := range dates {
+		periods[i] = history.FormatPeriod(d, interval)
+	}
+	return periods
+}
+
+// unionPeriods returns every period any repository has a snapshot for, in
+// order. Period labels sort chronologically as strings.
+func unionPeriods(results []worker.TrendsResult) []string {
+	seen := map[string]bool{}
+	var periods []string
+	for _, r := range results {
+		for p := range r.Snapshots {
+			if !seen[p] {
+				seen[p] = true
+				periods = append(periods, p)
+			}
+		}
+	}
+	sort.Strings(periods)
+	return periods
 }
 
-func buildTrendsReport(providerName, workspace, org, since, until, interval string, periods, repos, exclude []string, results []worker.TrendsResult) model.TrendsReport {
+func buildTrendsReport(providerName, workspace, org, since, until, interval string, periods, repos, exclude []string, results []worker.TrendsResult, now time.Time) model.TrendsReport {
 	report := model.TrendsReport{
-		GeneratedAt:  time.Now().UTC().Format(time.RFC3339),
+		GeneratedAt:  now.UTC().Format(time.RFC3339),
 		Provider:     providerName,
 		Workspace:    workspace,
 		Organization: org,
@@ -1195,11 +3180,43 @@ func buildTrendsReport(providerName, workspace, org, since, until, interval stri
 				Repository: r.Repo.Slug,
 				Error:      r.Err.Error(),
 			})
-			continue
+			// Periods analyzed before a failure still count.
+			if r.Snapshots == nil {
+				continue
+			}
+		}
+
+		// With --since auto a repo only has snapshots from its own first
+		// period on; earlier report periods predate it.
+		if since == "auto" {
+			first := ""
+			for p := range r.Snapshots {
+				if first == "" || p < first {
+					first = p
+				}
+			}
+			if first != "" {
+				if report.RepoSince == nil {
+					report.RepoSince = map[string]string{}
+				}
+				report.RepoSince[r.Repo.Slug] = first
+				for _, p := range periods {
+					if p < first {
+						snapshotMap[p].NotYetCreated = append(snapshotMap[p].NotYetCreated, r.Repo.Slug)
+					}
+				}
+			}
 		}
 
 		for period, stats := range r.Snapshots {
-			snap := snapshotMap[period]
+			snap, ok := snapshotMap[period]
+			if !ok {
+				continue
+			}
+			if stats == nil {
+				snap.NotYetCreated = append(snap.NotYetCreated, r.Repo.Slug)
+				continue
+			}
 			snap.Repositories = append(snap.Repositories, *stats)
 			snap.Totals.Repos++
 			snap.Totals.Files += stats.Totals.Files
@@ -1238,7 +3255,9 @@ func buildTrendsReport(providerName, workspace, org, since, until, interval stri
 	}
 
 	for _, p := range periods {
-		report.Snapshots = append(report.Snapshots, *snapshotMap[p])
+		snap := snapshotMap[p]
+		sort.Strings(snap.NotYetCreated)
+		report.Snapshots = append(report.Snapshots, *snap)
 	}
 
 	return report
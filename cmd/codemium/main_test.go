@@ -1,14 +1,22 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/analyzer"
+	"github.com/dsablic/codemium/internal/auth"
 	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
 	"github.com/dsablic/codemium/internal/worker"
 )
 
@@ -95,7 +103,12 @@ func TestBuildReport(t *testing.T) {
 		},
 	}
 
-	report := buildReport("bitbucket", "myworkspace", "", []string{"PROJ1"}, nil, nil, results)
+	now := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
+	report := buildReport("bitbucket", "myworkspace", "", []string{"PROJ1"}, nil, nil, results, now, false, 0, nil)
+
+	if report.GeneratedAt != "2026-02-18T12:00:00Z" {
+		t.Errorf("expected injected generated_at, got %s", report.GeneratedAt)
+	}
 
 	if report.Totals.Repos != 2 {
 		t.Errorf("expected 2 repos, got %d", report.Totals.Repos)
@@ -116,4 +129,714 @@ func TestBuildReport(t *testing.T) {
 	if report.ByLanguage[0].Code != 700 {
 		t.Errorf("expected Go total code 700, got %d", report.ByLanguage[0].Code)
 	}
+	if report.ByLanguage[0].CodePercent != 87.5 {
+		t.Errorf("expected Go to be 87.5%% of code, got %.2f", report.ByLanguage[0].CodePercent)
+	}
+	// repo-2: Go 200 of 300 lines
+	if pct := report.Repositories[1].Languages[0].CodePercent; pct < 66.6 || pct > 66.7 {
+		t.Errorf("expected per-repo Go share ~66.7%%, got %.2f", pct)
+	}
+
+	// Languages without code are dropped from ByLanguage but their files
+	// still count in the totals
+	results[0].Stats.Languages = append(results[0].Stats.Languages, model.LanguageStats{Name: "JSON", Files: 4, Blanks: 10})
+	results[0].Stats.Totals.Files += 4
+	report = buildReport("bitbucket", "myworkspace", "", nil, nil, nil, results, now, false, 0, nil)
+	if len(report.ByLanguage) != 2 {
+		t.Errorf("expected zero-code JSON to be omitted, got %+v", report.ByLanguage)
+	}
+	files := report.Totals.Files
+	report = buildReport("bitbucket", "myworkspace", "", nil, nil, nil, results, now, true, 0, nil)
+	if len(report.ByLanguage) != 3 || report.Totals.Files != files {
+		t.Errorf("expected JSON kept with allLanguages and unchanged file totals, got %+v (files %d vs %d)", report.ByLanguage, report.Totals.Files, files)
+	}
+
+	// Repos below minCode are only tallied
+	report = buildReport("bitbucket", "myworkspace", "", nil, nil, nil, results, now, false, 400, nil)
+	if report.Totals.Repos != 1 || report.Totals.FilteredRepos != 1 || report.Totals.Code != 500 {
+		t.Errorf("expected repo-2 filtered out, got %+v", report.Totals)
+	}
+	if len(report.Repositories) != 1 || report.Repositories[0].Repository != "repo-1" || report.Filters.MinCode != 400 {
+		t.Errorf("expected only repo-1 and min_code recorded, got %+v", report.Repositories)
+	}
+}
+
+func TestBuildReportSignalCounts(t *testing.T) {
+	aiRepo := func(slug string, signals ...[]model.AISignal) worker.Result {
+		est := &model.AIEstimate{TotalCommits: 10, AICommits: int64(len(signals))}
+		for _, s := range signals {
+			est.Details = append(est.Details, model.AICommit{Signals: s})
+		}
+		return worker.Result{
+			Repo:  model.Repo{Slug: slug},
+			Stats: &model.RepoStats{Repository: slug, AIEstimate: est},
+		}
+	}
+	results := []worker.Result{
+		aiRepo("repo-1", []model.AISignal{model.SignalCoAuthor, model.SignalCommitMessage}, []model.AISignal{model.SignalCoAuthor}),
+		aiRepo("repo-2", []model.AISignal{model.SignalBotAuthor}),
+	}
+
+	report := buildReport("github", "", "org", nil, nil, nil, results, time.Now(), false, 0, nil)
+	got := report.AIEstimate.SignalCounts
+	if got[model.SignalCoAuthor] != 2 || got[model.SignalCommitMessage] != 1 || got[model.SignalBotAuthor] != 1 {
+		t.Errorf("unexpected signal counts: %v", got)
+	}
+	if report.Repositories[0].AIEstimate.SignalCounts != nil {
+		t.Error("signal counts should only be set on the report-level estimate")
+	}
+}
+
+func TestBuildTrendsReportNotYetCreated(t *testing.T) {
+	periods := []string{"2025-01", "2025-02"}
+	results := []worker.TrendsResult{
+		{
+			Repo: model.Repo{Slug: "young"},
+			Snapshots: map[string]*model.RepoStats{
+				"2025-01": nil,
+				"2025-02": {Repository: "young", Totals: model.Stats{Code: 10}},
+			},
+		},
+		{
+			Repo:      model.Repo{Slug: "old"},
+			Snapshots: map[string]*model.RepoStats{"2025-01": {Repository: "old"}, "2025-02": {Repository: "old"}},
+		},
+	}
+
+	now := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
+	report := buildTrendsReport("github", "", "myorg", "2025-01", "2025-02", "monthly", periods, nil, nil, results, now)
+	first := report.Snapshots[0]
+	if len(first.NotYetCreated) != 1 || first.NotYetCreated[0] != "young" || first.Totals.Repos != 1 {
+		t.Errorf("expected young listed as not yet created in 2025-01, got %+v", first)
+	}
+	if second := report.Snapshots[1]; second.NotYetCreated != nil || second.Totals.Repos != 2 {
+		t.Errorf("expected both repos in 2025-02, got %+v", second)
+	}
+}
+
+func TestBuildTrendsReportAutoSince(t *testing.T) {
+	results := []worker.TrendsResult{
+		{
+			Repo:      model.Repo{Slug: "young"},
+			Snapshots: map[string]*model.RepoStats{"2025-02": {Repository: "young", Totals: model.Stats{Code: 10}}},
+		},
+		{
+			Repo:      model.Repo{Slug: "old"},
+			Snapshots: map[string]*model.RepoStats{"2025-01": {Repository: "old"}, "2025-02": {Repository: "old"}},
+		},
+	}
+
+	periods := unionPeriods(results)
+	if len(periods) != 2 || periods[0] != "2025-01" || periods[1] != "2025-02" {
+		t.Fatalf("expected union periods [2025-01 2025-02], got %v", periods)
+	}
+
+	now := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
+	report := buildTrendsReport("github", "", "myorg", "auto", "2025-02", "monthly", periods, nil, nil, results, now)
+	if report.RepoSince["young"] != "2025-02" || report.RepoSince["old"] != "2025-01" {
+		t.Errorf("unexpected repo_since: %v", report.RepoSince)
+	}
+	first := report.Snapshots[0]
+	if len(first.NotYetCreated) != 1 || first.NotYetCreated[0] != "young" || first.Totals.Repos != 1 {
+		t.Errorf("expected young listed as not yet created in 2025-01, got %+v", first)
+	}
+	if second := report.Snapshots[1]; second.Totals.Repos != 2 {
+		t.Errorf("expected both repos in 2025-02, got %+v", second)
+	}
+}
+
+func TestBuildTrendsReportPartialFailure(t *testing.T) {
+	periods := []string{"2025-01", "2025-02"}
+	results := []worker.TrendsResult{
+		{
+			Repo:      model.Repo{Slug: "flaky"},
+			Snapshots: map[string]*model.RepoStats{"2025-02": {Repository: "flaky", Totals: model.Stats{Code: 10}}},
+			Err:       errors.New("analyze periods: 1 of 2 commits failed"),
+		},
+		{Repo: model.Repo{Slug: "broken"}, Err: errors.New("git clone: not found")},
+	}
+
+	now := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
+	report := buildTrendsReport("github", "", "myorg", "2025-01", "2025-02", "monthly", periods, nil, nil, results, now)
+	if len(report.Errors) != 2 {
+		t.Fatalf("expected both failures recorded, got %+v", report.Errors)
+	}
+	if first := report.Snapshots[0]; first.Totals.Repos != 0 {
+		t.Errorf("expected no repos in the failed period, got %+v", first)
+	}
+	if second := report.Snapshots[1]; second.Totals.Repos != 1 || second.Totals.Code != 10 {
+		t.Errorf("expected flaky's analyzed period kept, got %+v", second)
+	}
+}
+
+func TestExpandOutputPath(t *testing.T) {
+	now := time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"literal path unchanged", "output/report.json", "output/report.json"},
+		{"date placeholder", "output/report-{date}.json", "output/report-2026-02-18.json"},
+		{"provider and org", "{provider}-{org}.json", "github-myorg.json"},
+		{"workspace", "out/{workspace}/{date}.json", "out/myws/2026-02-18.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandOutputPath(tt.path, "github", "myorg", "myws", now)
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpandOutputPathNestedGroup(t *testing.T) {
+	now := time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC)
+	got := expandOutputPath("{org}.json", "gitlab", "myorg/sub", "", now)
+	if got != "myorg-sub.json" {
+		t.Errorf("expected path separators to be replaced, got %q", got)
+	}
+}
+
+func TestClassifyStructure(t *testing.T) {
+	focused := &model.RepoStats{
+		Languages:    []model.LanguageStats{{Name: "Go", Code: 950}, {Name: "YAML", Code: 50}},
+		Totals:       model.Stats{Code: 1000},
+		TopLevelDirs: 10,
+	}
+	if got := classifyStructure(focused); got != "focused" {
+		t.Errorf("expected focused, got %q", got)
+	}
+
+	mono := &model.RepoStats{
+		Languages: []model.LanguageStats{
+			{Name: "Go", Code: 400}, {Name: "TypeScript", Code: 400}, {Name: "Python", Code: 200},
+		},
+		Totals:       model.Stats{Code: 1000},
+		TopLevelDirs: 12,
+	}
+	if got := classifyStructure(mono); got != "monorepo" {
+		t.Errorf("expected monorepo, got %q", got)
+	}
+
+	if got := classifyStructure(&model.RepoStats{}); got != "" {
+		t.Errorf("expected empty structure for repo without code, got %q", got)
+	}
+}
+
+func TestIsInfraOnly(t *testing.T) {
+	infra := &model.RepoStats{
+		Languages: []model.LanguageStats{
+			{Name: "YAML", Code: 600}, {Name: "HCL", Code: 300}, {Name: "Dockerfile", Code: 60}, {Name: "Shell", Code: 40},
+		},
+		Totals: model.Stats{Code: 1000},
+	}
+	if !isInfraOnly(infra) {
+		t.Error("expected YAML/HCL/Dockerfile repo with 4% shell to be infra-only")
+	}
+
+	app := &model.RepoStats{
+		Languages: []model.LanguageStats{{Name: "YAML", Code: 900}, {Name: "Go", Code: 100}},
+		Totals:    model.Stats{Code: 1000},
+	}
+	if isInfraOnly(app) {
+		t.Error("expected repo with 10% Go not to be infra-only")
+	}
+
+	if isInfraOnly(&model.RepoStats{}) {
+		t.Error("expected repo without code not to be infra-only")
+	}
+}
+
+func TestReportClock(t *testing.T) {
+	t.Setenv("CODEMIUM_NOW", "2026-01-01T00:00:00Z")
+
+	cmd := newAnalyzeCmd()
+	got, err := reportClock(cmd)
+	if err != nil {
+		t.Fatalf("reportClock: %v", err)
+	}
+	if !got.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected CODEMIUM_NOW to be used, got %s", got)
+	}
+
+	// The flag takes precedence over the environment
+	cmd.Flags().Set("generated-at", "2026-03-01T09:30:00+02:00")
+	got, err = reportClock(cmd)
+	if err != nil {
+		t.Fatalf("reportClock: %v", err)
+	}
+	if !got.Equal(time.Date(2026, 3, 1, 7, 30, 0, 0, time.UTC)) {
+		t.Errorf("expected --generated-at to win, got %s", got)
+	}
+
+	cmd.Flags().Set("generated-at", "yesterday")
+	if _, err := reportClock(cmd); err == nil {
+		t.Error("expected error for non-RFC 3339 timestamp")
+	}
+}
+
+func TestSetCodePercentZeroTotal(t *testing.T) {
+	langs := []model.LanguageStats{{Name: "Markdown", Code: 0}}
+	setCodePercent(langs, 0)
+	if langs[0].CodePercent != 0 {
+		t.Errorf("expected 0%% with no code, got %.2f", langs[0].CodePercent)
+	}
+}
+
+func TestFailFastError(t *testing.T) {
+	results := []worker.Result{
+		{Repo: model.Repo{Slug: "ok"}},
+		{Repo: model.Repo{Slug: "bad"}, Err: fmt.Errorf("git clone: 404")},
+	}
+	if err := failFastError(worker.Skip, "clone+analyze", results); err != nil {
+		t.Errorf("expected nil under skip policy, got %v", err)
+	}
+	err := failFastError(worker.FailFast, "clone+analyze", results)
+	if err == nil || err.Error() != "clone+analyze failed (--on-error fail-fast): bad: git clone: 404" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := failFastError(worker.FailFast, "clone+analyze", results[:1]); err != nil {
+		t.Errorf("expected nil without failures, got %v", err)
+	}
+}
+
+// scopedProvider returns one repo per listing, named after the workspace or
+// organization it was asked for.
+type scopedProvider struct{}
+
+func (scopedProvider) ListRepos(ctx context.Context, opts provider.ListOpts) ([]model.Repo, error) {
+	scope := opts.Workspace + opts.Organization
+	if scope == "broken" {
+		return nil, fmt.Errorf("403 forbidden")
+	}
+	return []model.Repo{{Slug: "repo-" + scope}}, nil
+}
+
+func TestListScopes(t *testing.T) {
+	ctx := context.Background()
+
+	repos, err := listScopes(ctx, scopedProvider{}, provider.ListOpts{}, []string{"team-a", "team-b"}, nil)
+	if err != nil {
+		t.Fatalf("listScopes: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Slug != "repo-team-a" || repos[1].Owner != "team-b" {
+		t.Errorf("unexpected workspace repos: %+v", repos)
+	}
+
+	repos, err = listScopes(ctx, scopedProvider{}, provider.ListOpts{}, nil, []string{"platform"})
+	if err != nil {
+		t.Fatalf("listScopes: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Slug != "repo-platform" || repos[0].Owner != "" {
+		t.Errorf("single group should not set Owner: %+v", repos)
+	}
+
+	repos, err = listScopes(ctx, scopedProvider{}, provider.ListOpts{Organization: "myorg"}, nil, nil)
+	if err != nil || len(repos) != 1 || repos[0].Slug != "repo-myorg" {
+		t.Errorf("unexpected unscoped listing: %+v, %v", repos, err)
+	}
+
+	_, err = listScopes(ctx, scopedProvider{}, provider.ListOpts{}, nil, []string{"platform", "broken"})
+	if err == nil || !strings.Contains(err.Error(), "list repos in broken") {
+		t.Errorf("expected scoped listing error, got %v", err)
+	}
+}
+
+func TestLoadTargets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "targets.yaml")
+	os.WriteFile(path, []byte(`targets:
+  - provider: github
+    org: myorg
+    exclude: [sandbox]
+  - provider: gitlab
+    group: platform/backend
+  - provider: bitbucket
+    workspace: [team-a, team-b]
+`), 0644)
+
+	targets, err := loadTargets(path)
+	if err != nil {
+		t.Fatalf("loadTargets: %v", err)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("expected 3 targets, got %d", len(targets))
+	}
+	if targets[0].Provider != "github" || targets[0].Org != "myorg" || len(targets[0].Exclude) != 1 {
+		t.Errorf("unexpected first target: %+v", targets[0])
+	}
+	if len(targets[1].Group) != 1 || targets[1].Group[0] != "platform/backend" || targets[1].interactive {
+		t.Errorf("unexpected second target: %+v", targets[1])
+	}
+	if len(targets[2].Workspace) != 2 || targets[2].Workspace[1] != "team-b" {
+		t.Errorf("unexpected third target: %+v", targets[2])
+	}
+
+	for name, content := range map[string]string{
+		"empty":            "targets: []\n",
+		"missing provider": "targets:\n  - org: myorg\n",
+		"nested all":       "targets:\n  - provider: all\n",
+		"unknown key":      "targets:\n  - provider: github\n    organisation: myorg\n",
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := loadTargets(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCheckReportShape(t *testing.T) {
+	valid := []string{
+		`{"generated_at":"2026-01-01T00:00:00Z","provider":"github","repositories":[]}`,
+		`{"provider":"github","snapshots":[{"period":"2026-01"}]}`,
+		`{"provider":"github","totals":{"code":10},"by_language":[]}`, // --summary-only
+	}
+	for _, in := range valid {
+		if err := checkReportShape([]byte(in)); err != nil {
+			t.Errorf("%s: unexpected error: %v", in, err)
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		input string
+		want  string
+	}{
+		"empty":      {"  \n", "input is empty"},
+		"error log":  {"[health] org/api | 404 not found\n[clone] org/web | timeout\n", ".error.log"},
+		"ndjson":     {`{"type":"metadata","provider":"github"}` + "\n" + `{"type":"repo","repository":"api"}` + "\n", "NDJSON stream"},
+		"repo stats": {`{"repository":"api","provider":"github","totals":{"code":10}}`, "single repository's stats"},
+		"array":      {`[{"repository":"api"}]`, "JSON array"},
+		"not json":   {"hello", "not JSON"},
+		"unknown":    {`{"name":"x"}`, `neither "repositories" nor "snapshots"`},
+	} {
+		err := checkReportShape([]byte(tc.input))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestWriteProviderCapabilities(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeProviderCapabilities(&buf); err != nil {
+		t.Fatalf("writeProviderCapabilities: %v", err)
+	}
+	rows := map[string][]string{}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == len(providerCapabilities)+1 {
+			rows[fields[0]] = fields[1:]
+		}
+	}
+	// github: list projects commits commit-stats churn issues oauth
+	if got := strings.Join(rows["github"], " "); got != "yes - yes yes yes yes yes" {
+		t.Errorf("unexpected github capabilities: %s", got)
+	}
+	if got := strings.Join(rows["gitlab"], " "); got != "yes - yes yes yes yes -" {
+		t.Errorf("unexpected gitlab capabilities: %s", got)
+	}
+	if got := strings.Join(rows["azure"], " "); got != "yes - yes - yes - -" {
+		t.Errorf("unexpected azure capabilities: %s", got)
+	}
+}
+
+func TestCheckBitbucketRole(t *testing.T) {
+	cloud := provider.NewBitbucket("t", "", "", nil)
+	if err := checkBitbucketRole("member", cloud); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkBitbucketRole("reader", cloud); err == nil {
+		t.Error("expected unknown role to be rejected")
+	}
+	if err := checkBitbucketRole("member", provider.NewGitHub("t", "", nil)); err == nil {
+		t.Error("expected --bitbucket-role to be rejected for GitHub")
+	}
+
+	forbidden := fmt.Errorf("list: %w", provider.ErrForbidden)
+	if err := listHint(forbidden, cloud, ""); !strings.Contains(err.Error(), "--bitbucket-role member") || !errors.Is(err, provider.ErrForbidden) {
+		t.Errorf("expected role hint wrapping the error, got %v", err)
+	}
+	if err := listHint(forbidden, cloud, "member"); err != forbidden {
+		t.Errorf("expected no hint when a role is already set, got %v", err)
+	}
+}
+
+func TestMissingRepos(t *testing.T) {
+	listed := []model.Repo{{Slug: "api"}, {Slug: "web"}}
+	got := missingRepos([]string{"web", "apii", "api", "docs"}, listed)
+	if strings.Join(got, ",") != "apii,docs" {
+		t.Errorf("expected [apii docs], got %v", got)
+	}
+	if got := missingRepos(nil, listed); got != nil {
+		t.Errorf("expected nothing missing without --repos, got %v", got)
+	}
+}
+
+func TestMostRecent(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repos := []model.Repo{
+		{Slug: "old", LastActivity: day},
+		{Slug: "undated"},
+		{Slug: "newest", LastActivity: day.AddDate(0, 2, 0)},
+		{Slug: "newer", LastActivity: day.AddDate(0, 1, 0)},
+	}
+
+	got, undated := mostRecent(repos, 2)
+	if len(got) != 2 || got[0].Slug != "newest" || got[1].Slug != "newer" {
+		t.Errorf("unexpected repos: %+v", got)
+	}
+	if undated != 1 {
+		t.Errorf("expected 1 undated repo, got %d", undated)
+	}
+	if repos[0].Slug != "old" {
+		t.Error("mostRecent reordered the input")
+	}
+
+	got, _ = mostRecent(repos, 10)
+	if len(got) != 4 || got[3].Slug != "undated" {
+		t.Errorf("undated repos should sort last: %+v", got)
+	}
+}
+
+func TestAuthDoctor(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no gh/glab CLI
+	for _, name := range []string{"CODEMIUM_GITHUB_TOKEN", "CODEMIUM_GITHUB_CLIENT_ID", "CODEMIUM_BITBUCKET_TOKEN", "CODEMIUM_BITBUCKET_USERNAME", "CODEMIUM_BITBUCKET_CLIENT_ID", "CODEMIUM_BITBUCKET_CLIENT_SECRET"} {
+		t.Setenv(name, "")
+	}
+	store := auth.NewFileStore(filepath.Join(t.TempDir(), "credentials.json"))
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf strings.Builder
+	if err := authDoctor(&buf, "github", store, now); err == nil {
+		t.Error("expected error with no github credentials")
+	}
+	for _, want := range []string{"[missing] CODEMIUM_GITHUB_TOKEN", "gh CLI not found", "OAuth: set CODEMIUM_GITHUB_CLIENT_ID"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+
+	t.Setenv("CODEMIUM_GITHUB_TOKEN", "tok")
+	buf.Reset()
+	if err := authDoctor(&buf, "github", store, now); err != nil {
+		t.Fatalf("authDoctor: %v", err)
+	}
+	if !strings.Contains(buf.String(), "analyze will use: CODEMIUM_GITHUB_TOKEN") {
+		t.Errorf("expected env token to be used:\n%s", buf.String())
+	}
+
+	// An expired Bitbucket credential can only be refreshed with the OAuth client
+	store.Save("bitbucket", auth.Credentials{AccessToken: "a", RefreshToken: "r", ExpiresAt: now.Add(-time.Hour)})
+	buf.Reset()
+	if err := authDoctor(&buf, "bitbucket", store, now); err == nil {
+		t.Errorf("expected error for unrefreshable credential:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "refreshing it needs CODEMIUM_BITBUCKET_CLIENT_ID and CODEMIUM_BITBUCKET_CLIENT_SECRET") {
+		t.Errorf("expected refresh hint:\n%s", buf.String())
+	}
+	t.Setenv("CODEMIUM_BITBUCKET_CLIENT_ID", "id")
+	t.Setenv("CODEMIUM_BITBUCKET_CLIENT_SECRET", "secret")
+	buf.Reset()
+	if err := authDoctor(&buf, "bitbucket", store, now); err != nil {
+		t.Errorf("authDoctor: %v\n%s", err, buf.String())
+	}
+
+	t.Setenv("CODEMIUM_AZURE_TOKEN", "")
+	t.Setenv("CODEMIUM_AZURE_URL", "")
+	buf.Reset()
+	if err := authDoctor(&buf, "azure", store, now); err == nil {
+		t.Error("expected error with no azure credentials")
+	}
+	for _, want := range []string{"[missing] CODEMIUM_AZURE_TOKEN", "CODEMIUM_AZURE_URL not set", "no 'auth login' for azure"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+	t.Setenv("CODEMIUM_AZURE_TOKEN", "pat")
+	t.Setenv("CODEMIUM_AZURE_URL", "https://ado.example.com/tfs")
+	buf.Reset()
+	if err := authDoctor(&buf, "azure", store, now); err != nil {
+		t.Errorf("authDoctor: %v\n%s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "CODEMIUM_AZURE_URL=https://ado.example.com/tfs") {
+		t.Errorf("expected the server URL to be reported:\n%s", buf.String())
+	}
+
+	if err := authDoctor(&buf, "svn", store, now); err == nil {
+		t.Error("expected error for unsupported provider")
+	}
+}
+
+func TestOpenJSONStream(t *testing.T) {
+	cmd := newAnalyzeCmd()
+	dir := t.TempDir()
+	cmd.Flags().Set("output", filepath.Join(dir, "{provider}-{org}.json"))
+	cmd.Flags().Set("compact-json", "true")
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	target := analyzeTarget{Provider: "gitlab", Group: stringList{"a", "b"}}
+
+	stream, closeOutput, err := openJSONStream(cmd, target, now)
+	if err != nil {
+		t.Fatalf("openJSONStream: %v", err)
+	}
+	stream.WriteRepo(model.RepoStats{Repository: "svc", Totals: model.Stats{Code: 5}})
+	closeOutput() // an interrupted run leaves the repos written so far
+
+	data, err := os.ReadFile(filepath.Join(dir, "gitlab-a,b.json"))
+	if err != nil {
+		t.Fatalf("read streamed output: %v", err)
+	}
+	want := `{"generated_at":"2026-03-01T00:00:00Z","provider":"gitlab","organization":"a,b","repositories":[{"repository":"svc"`
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("unexpected partial output:\n%s", data)
+	}
+
+	cmd.Flags().Set("health", "true")
+	if _, _, err := openJSONStream(cmd, target, now); err == nil || !strings.Contains(err.Error(), "--health") {
+		t.Errorf("expected --health to be rejected, got %v", err)
+	}
+	cmd.Flags().Set("health", "false")
+
+	cmd.Flags().Set("min-code", "100")
+	if _, _, err := openJSONStream(cmd, target, now); err == nil || !strings.Contains(err.Error(), "--min-code") {
+		t.Errorf("expected --min-code to be rejected, got %v", err)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "report.zip")
+	report := model.Report{Provider: "github", Totals: model.Stats{Repos: 1}}
+	diag := []errorEntry{{Category: "clone", Repo: "broken", Message: "auth failed"}}
+	render := func(w io.Writer) error {
+		_, err := io.WriteString(w, "# Report\n")
+		return err
+	}
+	if err := writeBundle(path, report, diag, nil, render); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer zr.Close()
+	contents := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+	if !strings.Contains(contents["report.json"], `"provider": "github"`) {
+		t.Errorf("unexpected report.json: %q", contents["report.json"])
+	}
+	if contents["report.md"] != "# Report\n" {
+		t.Errorf("unexpected report.md: %q", contents["report.md"])
+	}
+	if !strings.Contains(contents["errors.json"], `"message": "auth failed"`) {
+		t.Errorf("unexpected errors.json: %q", contents["errors.json"])
+	}
+
+	if err := writeBundle(path, report, nil, nil, render); err != nil {
+		t.Fatalf("writeBundle: %v", err)
+	}
+	zr2, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer zr2.Close()
+	if len(zr2.File) != 2 {
+		t.Errorf("expected no errors.json without diagnostics, got %d entries", len(zr2.File))
+	}
+}
+
+func TestWriteMarkdownOut(t *testing.T) {
+	cmd := newAnalyzeCmd()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	render := func(w io.Writer) error {
+		_, err := io.WriteString(w, "# Report\n")
+		return err
+	}
+
+	// Without --markdown-out nothing is rendered
+	if err := writeMarkdownOut(cmd, "github", "myorg", "", now, func(io.Writer) error {
+		t.Error("render should not be called without --markdown-out")
+		return nil
+	}); err != nil {
+		t.Fatalf("writeMarkdownOut: %v", err)
+	}
+
+	dir := t.TempDir()
+	cmd.Flags().Set("markdown-out", filepath.Join(dir, "md", "{org}-{date}.md"))
+	if err := writeMarkdownOut(cmd, "github", "myorg", "", now, render); err != nil {
+		t.Fatalf("writeMarkdownOut: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "md", "myorg-2026-03-01.md"))
+	if err != nil || string(data) != "# Report\n" {
+		t.Errorf("unexpected markdown output %q (%v)", data, err)
+	}
+
+	cmd.Flags().Set("output", filepath.Join(dir, "report.json"))
+	if _, _, err := openJSONStream(cmd, analyzeTarget{Provider: "github"}, now); err == nil || !strings.Contains(err.Error(), "--markdown-out") {
+		t.Errorf("expected --markdown-out to be rejected with --stream-output, got %v", err)
+	}
+}
+
+func TestNewRunConfig(t *testing.T) {
+	cmd := newAnalyzeCmd()
+	cmd.Flags().Set("health-details", "true")
+	cmd.Flags().Set("churn-limit", "200")
+	cmd.Flags().Set("complexity-threshold", "500")
+
+	phases := []model.PhaseTiming{{Phase: "list"}, {Phase: "clone+analyze"}, {Phase: "health"}}
+	cfg := newRunConfig(cmd, phases, 5, 10)
+	report := buildReport("github", "", "myorg", nil, nil, nil, nil, time.Now(), false, 0, cfg)
+
+	got := report.RunConfig
+	if got == nil || got.Provider != "github" || got.Organization != "myorg" {
+		t.Fatalf("expected the run config to carry the target, got %+v", got)
+	}
+	if len(got.Phases) != 3 || got.Phases[2] != "health" {
+		t.Errorf("expected phases list, clone+analyze, health, got %v", got.Phases)
+	}
+	if got.Concurrency != 5 || got.APIConcurrency != 10 {
+		t.Errorf("expected concurrency 5/10, got %d/%d", got.Concurrency, got.APIConcurrency)
+	}
+	if got.VelocityBand != 0.2 || len(got.HealthWindows) != 2 {
+		t.Errorf("expected health details thresholds, got band %g windows %v", got.VelocityBand, got.HealthWindows)
+	}
+	if got.ChurnLimit != 0 || got.AICommitLimit != 0 {
+		t.Errorf("expected no limits for phases that didn't run, got churn %d ai %d", got.ChurnLimit, got.AICommitLimit)
+	}
+	if got.Flags["churn-limit"] != "200" || got.Flags["health-details"] != "true" || len(got.Flags) != 3 {
+		t.Errorf("expected the three set flags, got %v", got.Flags)
+	}
+	if len(got.ComplexityThresholds) != 1 || got.ComplexityThresholds[0] != "500" {
+		t.Errorf("expected complexity threshold 500, got %v", got.ComplexityThresholds)
+	}
+}
+
+func TestGitHubBaseURL(t *testing.T) {
+	t.Setenv("CODEMIUM_GITHUB_URL", "")
+	cmd := newAnalyzeCmd()
+	if got, err := githubBaseURL(cmd); err != nil || got != "" {
+		t.Errorf("expected github.com by default, got %q (%v)", got, err)
+	}
+
+	t.Setenv("CODEMIUM_GITHUB_URL", "https://ghe.example.com")
+	if got, _ := githubBaseURL(cmd); got != "https://ghe.example.com/api/v3" {
+		t.Errorf("expected the environment URL, got %q", got)
+	}
+
+	cmd.Flags().Set("github-url", "https://github.com")
+	if got, _ := githubBaseURL(cmd); got != "" {
+		t.Errorf("expected the flag to win over the environment, got %q", got)
+	}
 }
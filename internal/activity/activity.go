@@ -0,0 +1,65 @@
+// Package activity builds commit time-of-day / day-of-week histograms.
+package activity
+
+import (
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+// Heatmap counts commits by weekday and hour in the time zone each commit
+// date carries (UTC when the provider only reports UTC). Commits without a
+// date are skipped. It returns nil when no commit has a date.
+func Heatmap(commits []provider.CommitInfo) *model.ActivityHeatmap {
+	var h model.ActivityHeatmap
+	for _, c := range commits {
+		if c.Date.IsZero() {
+			continue
+		}
+		h.Counts[c.Date.Weekday()][c.Date.Hour()]++
+		h.Commits++
+	}
+	if h.Commits == 0 {
+		return nil
+	}
+	return &h
+}
+
+// Merge sums the per-repository heatmaps, or returns nil when none has one.
+func Merge(repos []model.RepoStats) *model.ActivityHeatmap {
+	var total *model.ActivityHeatmap
+	for _, r := range repos {
+		if r.ActivityHeatmap == nil {
+			continue
+		}
+		if total == nil {
+			total = &model.ActivityHeatmap{}
+		}
+		total.Commits += r.ActivityHeatmap.Commits
+		for d := range total.Counts {
+			for h := range total.Counts[d] {
+				total.Counts[d][h] += r.ActivityHeatmap.Counts[d][h]
+			}
+		}
+	}
+	return total
+}
+
+// Shares returns the percentage of h's commits made on weekends and outside
+// 08:00-19:59 on weekdays.
+func Shares(h *model.ActivityHeatmap) (weekend, offHours float64) {
+	if h == nil || h.Commits == 0 {
+		return 0, 0
+	}
+	var we, off int
+	for d, hours := range h.Counts {
+		for hour, n := range hours {
+			switch {
+			case d == 0 || d == 6:
+				we += n
+			case hour < 8 || hour >= 20:
+				off += n
+			}
+		}
+	}
+	return float64(we) / float64(h.Commits) * 100, float64(off) / float64(h.Commits) * 100
+}
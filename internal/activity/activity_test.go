@@ -0,0 +1,69 @@
+package activity_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dsablic/codemium/internal/activity"
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func TestHeatmap(t *testing.T) {
+	cet := time.FixedZone("CET", 3600)
+	commits := []provider.CommitInfo{
+		{Date: time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)}, // Monday 09:xx
+		{Date: time.Date(2026, 3, 2, 9, 45, 0, 0, time.UTC)}, // Monday 09:xx
+		{Date: time.Date(2026, 3, 7, 23, 30, 0, 0, cet)},     // Saturday 23:xx local
+		{Message: "no date"},
+	}
+
+	h := activity.Heatmap(commits)
+	if h == nil {
+		t.Fatal("expected a heatmap")
+	}
+	if h.Commits != 3 {
+		t.Errorf("expected 3 commits, got %d", h.Commits)
+	}
+	if h.Counts[time.Monday][9] != 2 {
+		t.Errorf("expected 2 commits on Monday 09:00, got %d", h.Counts[time.Monday][9])
+	}
+	if h.Counts[time.Saturday][23] != 1 {
+		t.Errorf("expected the commit counted in its own time zone, got %v", h.Counts[time.Saturday])
+	}
+}
+
+func TestHeatmapWithoutDates(t *testing.T) {
+	if h := activity.Heatmap([]provider.CommitInfo{{Message: "x"}}); h != nil {
+		t.Errorf("expected nil heatmap, got %+v", h)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := &model.ActivityHeatmap{Commits: 2}
+	a.Counts[time.Tuesday][10] = 2
+	b := &model.ActivityHeatmap{Commits: 1}
+	b.Counts[time.Tuesday][10] = 1
+
+	total := activity.Merge([]model.RepoStats{{ActivityHeatmap: a}, {}, {ActivityHeatmap: b}})
+	if total == nil || total.Commits != 3 || total.Counts[time.Tuesday][10] != 3 {
+		t.Errorf("unexpected merged heatmap: %+v", total)
+	}
+	if activity.Merge([]model.RepoStats{{}}) != nil {
+		t.Error("expected nil when no repository has a heatmap")
+	}
+}
+
+func TestShares(t *testing.T) {
+	h := &model.ActivityHeatmap{Commits: 4}
+	h.Counts[time.Sunday][12] = 1
+	h.Counts[time.Wednesday][7] = 1
+	h.Counts[time.Wednesday][20] = 1
+	h.Counts[time.Wednesday][14] = 1
+
+	weekend, offHours := activity.Shares(h)
+	if math.Abs(weekend-25) > 0.01 || math.Abs(offHours-50) > 0.01 {
+		t.Errorf("expected 25%% weekend and 50%% off-hours, got %.1f and %.1f", weekend, offHours)
+	}
+}
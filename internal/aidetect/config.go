@@ -0,0 +1,160 @@
+package aidetect
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// CustomSignalPrefix prefixes the model.AISignal of a custom rule, so a rule
+// named "cursor" produces "custom:cursor".
+const CustomSignalPrefix = "custom:"
+
+// Config holds AI detection rules on top of the built-in heuristics. A nil
+// *Config detects with the built-ins only.
+type Config struct {
+	Rules []Rule
+	// CaseSensitive makes the built-in tool names and message patterns
+	// match only in their canonical capitalization, e.g. "Claude" but not
+	// "CLAUDE" (--ai-case-sensitive); custom rules are unaffected.
+	CaseSensitive bool
+}
+
+// Rule is one custom detection rule. A commit matching any of its criteria
+// gets the rule's Signal once.
+type Rule struct {
+	Signal            model.AISignal
+	CoAuthorEmails    []string // lower-cased
+	BotAuthors        []*regexp.Regexp
+	MessageSubstrings []string // lower-cased
+	MessagePatterns   []*regexp.Regexp
+}
+
+// signalsFile is the on-disk format read by LoadConfig.
+type signalsFile struct {
+	Signals []struct {
+		Name              string   `yaml:"name"`
+		CoAuthorEmails    []string `yaml:"co_author_emails"`
+		BotAuthors        []string `yaml:"bot_authors"`
+		MessageSubstrings []string `yaml:"message_substrings"`
+		MessagePatterns   []string `yaml:"message_patterns"`
+	} `yaml:"signals"`
+}
+
+// LoadConfig reads custom detection rules from a YAML (or JSON) file:
+//
+//	signals:
+//	  - name: cursor
+//	    co_author_emails: [cursoragent@cursor.com]
+//	    bot_authors: ["^cursor-agent"]
+//	    message_substrings: ["made with cursor"]
+//	    message_patterns: ["(?i)\\bcursor agent\\b"]
+//
+// Co-author emails and message substrings match ignoring case; bot author
+// and message patterns are Go regular expressions. Unknown keys, unnamed or
+// duplicate rules, rules without criteria and invalid patterns are errors.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read AI signals file: %w", err)
+	}
+
+	var f signalsFile
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("parse AI signals file: %w", err)
+	}
+
+	cfg := &Config{}
+	seen := map[string]bool{}
+	for i, s := range f.Signals {
+		name := strings.TrimSpace(s.Name)
+		if name == "" {
+			return nil, fmt.Errorf("AI signals file: rule %d has no name", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("AI signals file: duplicate rule %q", name)
+		}
+		seen[name] = true
+
+		rule := Rule{Signal: model.AISignal(CustomSignalPrefix + name)}
+		for _, e := range s.CoAuthorEmails {
+			rule.CoAuthorEmails = append(rule.CoAuthorEmails, strings.ToLower(strings.TrimSpace(e)))
+		}
+		for _, sub := range s.MessageSubstrings {
+			rule.MessageSubstrings = append(rule.MessageSubstrings, strings.ToLower(sub))
+		}
+		if rule.BotAuthors, err = compilePatterns(name, s.BotAuthors); err != nil {
+			return nil, err
+		}
+		if rule.MessagePatterns, err = compilePatterns(name, s.MessagePatterns); err != nil {
+			return nil, err
+		}
+		if len(rule.CoAuthorEmails)+len(rule.BotAuthors)+len(rule.MessageSubstrings)+len(rule.MessagePatterns) == 0 {
+			return nil, fmt.Errorf("AI signals file: rule %q has no criteria", name)
+		}
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+	return cfg, nil
+}
+
+func compilePatterns(rule string, patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("AI signals file: rule %q: %w", rule, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matches reports whether a commit matches any of the rule's criteria.
+func (r Rule) matches(author, message string) bool {
+	if len(r.CoAuthorEmails) > 0 {
+		for _, c := range CoAuthors(message) {
+			if email := coAuthorEmail(c); email != "" && containsString(r.CoAuthorEmails, email) {
+				return true
+			}
+		}
+	}
+	for _, re := range r.BotAuthors {
+		if re.MatchString(author) {
+			return true
+		}
+	}
+	if len(r.MessageSubstrings) > 0 {
+		lower := strings.ToLower(message)
+		for _, sub := range r.MessageSubstrings {
+			if strings.Contains(lower, sub) {
+				return true
+			}
+		}
+	}
+	return matchesAny(r.MessagePatterns, message)
+}
+
+// coAuthorEmail returns the lower-cased email of a "Name <email>" identity,
+// or "" when it has none.
+func coAuthorEmail(identity string) string {
+	start := strings.LastIndex(identity, "<")
+	end := strings.LastIndex(identity, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(identity[start+1 : end]))
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package aidetect_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dsablic/codemium/internal/aidetect"
+	"github.com/dsablic/codemium/internal/model"
+)
+
+func writeSignalsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "signals.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDetectCustomSignals(t *testing.T) {
+	cfg, err := aidetect.LoadConfig(writeSignalsFile(t, `
+signals:
+  - name: copilot
+    co_author_emails: [198982749+GH-Agent@users.noreply.github.com]
+  - name: internal-bot
+    bot_authors: ["^release-robot "]
+    message_patterns: ["^\\[ai\\] "]
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		author  string
+		message string
+		want    []model.AISignal
+	}{
+		{
+			name:    "custom Copilot co-author",
+			message: "fix: bug\n\nCo-authored-by: gh-agent <198982749+gh-agent@users.noreply.github.com>",
+			want:    []model.AISignal{"custom:copilot"},
+		},
+		{
+			name:    "custom message regex",
+			message: "[ai] regenerate client",
+			want:    []model.AISignal{"custom:internal-bot"},
+		},
+		{
+			name:    "custom bot author",
+			author:  "release-robot <robot@example.com>",
+			message: "chore: release",
+			want:    []model.AISignal{"custom:internal-bot"},
+		},
+		{
+			name:    "built-in and custom signals",
+			message: "[ai] sync\n\nCo-Authored-By: Claude <noreply@anthropic.com>",
+			want:    []model.AISignal{model.SignalCoAuthor, "custom:internal-bot"},
+		},
+		{
+			name:    "no match",
+			message: "feat: [ai] in the middle",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aidetect.Detect(tt.author, tt.message, cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, content := range []string{
+		"signals:\n  - co_author_emails: [a@example.com]\n",                                // unnamed
+		"signals:\n  - name: x\n",                                                          // no criteria
+		"signals:\n  - name: x\n    message_patterns: [\"(\"]\n",                           // bad regexp
+		"signals:\n  - name: x\n    bot_authors: [a]\n  - name: x\n    bot_authors: [b]\n", // duplicate
+		"signals:\n  - name: x\n    emails: [a@example.com]\n",                             // unknown key
+	} {
+		if _, err := aidetect.LoadConfig(writeSignalsFile(t, content)); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
@@ -1,67 +1,136 @@
 package aidetect
 
 import (
+	"regexp"
 	"strings"
 
 	"github.com/dsablic/codemium/internal/model"
 )
 
+// aiToolNames and aiMessagePatterns are spelled with their canonical
+// capitalization, which case-sensitive matching requires.
 var aiToolNames = []string{
-	"claude", "copilot", "cursor", "codeium", "gemini",
-	"chatgpt", "openai", "anthropic", "windsurf", "aider",
-	"amazon q",
+	"Claude", "Copilot", "Cursor", "Codeium", "Gemini",
+	"ChatGPT", "OpenAI", "Anthropic", "Windsurf", "Aider",
+	"Amazon Q", "Cline",
 }
 
 var aiMessagePatterns = []string{
-	"generated by", "ai-generated", "ai-assisted", "auto-generated",
+	"Generated by", "AI-generated", "AI-assisted", "Auto-generated",
 }
 
-// Detect inspects a commit author and message and returns matching AI signals.
-func Detect(author, message string) []model.AISignal {
+// Tool names and message patterns only match as whole words, so "cline"
+// doesn't match inside "included". Matching ignores case unless the Config
+// passed to Detect sets CaseSensitive; then tool names must be spelled as
+// above, and message patterns too except for their first letter, so they
+// match both at the start of a sentence and inside one.
+var (
+	toolMatchers         = wordMatchers(aiToolNames, false, false)
+	messageMatchers      = wordMatchers(aiMessagePatterns, false, false)
+	exactToolMatchers    = wordMatchers(aiToolNames, true, false)
+	exactMessageMatchers = wordMatchers(aiMessagePatterns, true, true)
+)
+
+// wordMatchers compiles each word into a regexp matching it between word
+// boundaries. With anyInitial, a case-sensitive matcher accepts the word's
+// first letter in either case.
+func wordMatchers(words []string, caseSensitive, anyInitial bool) []*regexp.Regexp {
+	matchers := make([]*regexp.Regexp, len(words))
+	for i, w := range words {
+		expr := regexp.QuoteMeta(w)
+		switch {
+		case !caseSensitive:
+			expr = "(?i)" + expr
+		case anyInitial:
+			first := w[:1]
+			expr = "[" + strings.ToUpper(first) + strings.ToLower(first) + "]" +
+				regexp.QuoteMeta(w[1:])
+		}
+		matchers[i] = regexp.MustCompile(`\b` + expr + `\b`)
+	}
+	return matchers
+}
+
+func matchesAny(matchers []*regexp.Regexp, s string) bool {
+	for _, m := range matchers {
+		if m.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect inspects a commit author and message and returns matching AI
+// signals: the built-in ones, then one per matching custom rule in cfg
+// (which may be nil).
+func Detect(author, message string, cfg *Config) []model.AISignal {
 	var signals []model.AISignal
 
-	if hasCoAuthorAI(message) {
+	tools, patterns := toolMatchers, messageMatchers
+	if cfg != nil && cfg.CaseSensitive {
+		tools, patterns = exactToolMatchers, exactMessageMatchers
+	}
+
+	if hasCoAuthorAI(message, tools) {
 		signals = append(signals, model.SignalCoAuthor)
 	}
 
-	if hasAIMessagePattern(message) {
+	if matchesAny(patterns, message) {
 		signals = append(signals, model.SignalCommitMessage)
 	}
 
-	if isBotAuthor(author) {
+	if IsBotAuthor(author) {
 		signals = append(signals, model.SignalBotAuthor)
 	}
 
+	if cfg != nil {
+		for _, r := range cfg.Rules {
+			if r.matches(author, message) {
+				signals = append(signals, r.Signal)
+			}
+		}
+	}
+
 	return signals
 }
 
-func hasCoAuthorAI(message string) bool {
-	lower := strings.ToLower(message)
-	for _, line := range strings.Split(lower, "\n") {
+// coAuthorTrailer is the (lower-cased) prefix of a co-author trailer line.
+const coAuthorTrailer = "co-authored-by:"
+
+// CoAuthors returns the values of the Co-authored-by trailers in a commit
+// message, e.g. "Jane Doe <jane@example.com>", in order of appearance.
+func CoAuthors(message string) []string {
+	var coAuthors []string
+	for _, line := range strings.Split(message, "\n") {
 		line = strings.TrimSpace(line)
-		if !strings.HasPrefix(line, "co-authored-by:") {
+		if len(line) < len(coAuthorTrailer) || !strings.EqualFold(line[:len(coAuthorTrailer)], coAuthorTrailer) {
 			continue
 		}
-		for _, tool := range aiToolNames {
-			if strings.Contains(line, tool) {
-				return true
-			}
+		if v := strings.TrimSpace(line[len(coAuthorTrailer):]); v != "" {
+			coAuthors = append(coAuthors, v)
 		}
 	}
-	return false
+	return coAuthors
+}
+
+// IsAITool reports whether an author or co-author identity names a known AI
+// tool as a whole word, ignoring case, e.g. "Claude <noreply@anthropic.com>".
+func IsAITool(identity string) bool {
+	return matchesAny(toolMatchers, identity)
 }
 
-func hasAIMessagePattern(message string) bool {
-	lower := strings.ToLower(message)
-	for _, pattern := range aiMessagePatterns {
-		if strings.Contains(lower, pattern) {
+func hasCoAuthorAI(message string, tools []*regexp.Regexp) bool {
+	for _, c := range CoAuthors(message) {
+		if matchesAny(tools, c) {
 			return true
 		}
 	}
 	return false
 }
 
-func isBotAuthor(author string) bool {
+// IsBotAuthor reports whether author is a bot account such as
+// "dependabot[bot]".
+func IsBotAuthor(author string) bool {
 	lower := strings.ToLower(author)
 	return strings.Contains(lower, "[bot]")
 }
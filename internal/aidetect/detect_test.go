@@ -1,6 +1,7 @@
 package aidetect_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/dsablic/codemium/internal/aidetect"
@@ -43,7 +44,7 @@ func TestDetectCoAuthorTrailer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := aidetect.Detect(tt.author, tt.message)
+			got := aidetect.Detect(tt.author, tt.message, nil)
 			if len(got) != len(tt.want) {
 				t.Errorf("Detect() = %v, want %v", got, tt.want)
 			}
@@ -81,7 +82,7 @@ func TestDetectCommitMessagePatterns(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := aidetect.Detect("", tt.message)
+			got := aidetect.Detect("", tt.message, nil)
 			if len(got) != len(tt.want) {
 				t.Errorf("Detect() = %v, want %v", got, tt.want)
 			}
@@ -124,7 +125,7 @@ func TestDetectBotAuthor(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := aidetect.Detect(tt.author, "some commit message")
+			got := aidetect.Detect(tt.author, "some commit message", nil)
 			if len(got) != len(tt.want) {
 				t.Errorf("Detect() = %v, want %v", got, tt.want)
 			}
@@ -136,7 +137,7 @@ func TestDetectMultipleSignals(t *testing.T) {
 	author := "copilot[bot] <copilot[bot]@users.noreply.github.com>"
 	message := "AI-generated fix\n\nCo-Authored-By: Claude <noreply@anthropic.com>"
 
-	got := aidetect.Detect(author, message)
+	got := aidetect.Detect(author, message, nil)
 	if len(got) != 3 {
 		t.Errorf("expected 3 signals, got %d: %v", len(got), got)
 	}
@@ -145,7 +146,7 @@ func TestDetectMultipleSignals(t *testing.T) {
 func TestDetectNoDuplicateSignals(t *testing.T) {
 	message := "feat: thing\n\nCo-Authored-By: Claude <noreply@anthropic.com>\nCo-Authored-By: Copilot <copilot@github.com>"
 
-	got := aidetect.Detect("", message)
+	got := aidetect.Detect("", message, nil)
 	coAuthorCount := 0
 	for _, s := range got {
 		if s == model.SignalCoAuthor {
@@ -156,3 +157,75 @@ func TestDetectNoDuplicateSignals(t *testing.T) {
 		t.Errorf("expected 1 co-author signal, got %d", coAuthorCount)
 	}
 }
+
+func TestCoAuthors(t *testing.T) {
+	msg := "feat: x\n\nCo-authored-by: Jane Doe <jane@example.com>\nco-authored-by:   Bob <bob@example.com>  \nCo-authored-by:\nSigned-off-by: Someone <s@example.com>"
+	got := aidetect.CoAuthors(msg)
+	want := []string{"Jane Doe <jane@example.com>", "Bob <bob@example.com>"}
+	if len(got) != len(want) {
+		t.Fatalf("CoAuthors() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CoAuthors()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDetectWordBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		author  string
+		message string
+		want    bool
+	}{
+		{"tool inside a word", "", "fix: included the missing header\n\nCo-authored-by: Jane Inclined <jane@example.com>", false},
+		{"name containing a tool", "", "docs: typo\n\nCo-authored-by: Claudette Smith <claudette@example.com>", false},
+		{"pattern inside a word", "", "chore: regenerated bylaws page", false},
+		{"legitimate generated by", "", "Generated by Claude", true},
+		{"tool as a word", "", "feat: x\n\nCo-authored-by: Cline <cline@example.com>", true},
+		{"tool in email domain", "", "feat: x\n\nCo-authored-by: Claude <noreply@anthropic.com>", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := len(aidetect.Detect(tt.author, tt.message, nil)) > 0
+			if got != tt.want {
+				t.Errorf("Detect(%q) flagged = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectCaseSensitive(t *testing.T) {
+	cfg := &aidetect.Config{CaseSensitive: true}
+
+	tests := []struct {
+		name    string
+		message string
+		want    []model.AISignal
+	}{
+		{"canonical co-author", "feat: x\n\nCo-authored-by: Claude <noreply@anthropic.com>", []model.AISignal{model.SignalCoAuthor}},
+		{"upper-case co-author", "feat: x\n\nCo-authored-by: CLAUDE <bot@example.com>", nil},
+		{"lower-case co-author", "feat: x\n\nCo-authored-by: claude <bot@example.com>", nil},
+		{"sentence-start pattern", "Generated by a script", []model.AISignal{model.SignalCommitMessage}},
+		{"mid-sentence pattern", "docs: generated by a script", []model.AISignal{model.SignalCommitMessage}},
+		{"upper-case pattern", "GENERATED BY a script", nil},
+		{"canonical pattern", "feat: AI-assisted refactor", []model.AISignal{model.SignalCommitMessage}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := aidetect.Detect("", tt.message, cfg)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Detect(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+
+	if len(aidetect.Detect("", "GENERATED BY Claude", nil)) == 0 {
+		t.Error("expected case-insensitive matching without CaseSensitive")
+	}
+	if !aidetect.IsAITool("CLAUDE <bot@example.com>") {
+		t.Error("expected IsAITool to ignore case")
+	}
+}
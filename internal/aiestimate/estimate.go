@@ -12,50 +12,55 @@ import (
 
 const statsConcurrency = 10
 
-// Estimate computes AI attribution metrics for a single repo.
+// Estimate computes AI attribution metrics for a single repo, detecting AI
+// commits with the built-in heuristics plus any custom rules
+// (which may be nil).
 // It returns the estimate, a list of partial error messages (per-commit stat failures), and a fatal error.
-func Estimate(ctx context.Context, cl provider.CommitLister, repo model.Repo, commitLimit int) (*model.AIEstimate, []string, error) {
+func Estimate(ctx context.Context, cl provider.CommitLister, repo model.Repo, commitLimit int, rules *aidetect.Config) (*model.AIEstimate, []string, error) {
 	commits, err := cl.ListCommits(ctx, repo, commitLimit)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	est, partialErrors := EstimateFromCommits(ctx, cl, repo, commits, rules)
+	return est, partialErrors, nil
+}
+
+// EstimateFromCommits computes AI attribution metrics from an already-fetched
+// commit list, so callers can share one listing across several analyses.
+// Stats are fetched for every commit, so AdditionPercent compares the
+// AI-flagged commits' additions with those of all scanned commits.
+// Per-commit stat failures are returned as partial error messages.
+func EstimateFromCommits(ctx context.Context, cl provider.CommitLister, repo model.Repo, commits []provider.CommitInfo, rules *aidetect.Config) (*model.AIEstimate, []string) {
 	est := &model.AIEstimate{
 		TotalCommits: int64(len(commits)),
 	}
 
-	type flaggedCommit struct {
-		info    provider.CommitInfo
-		signals []model.AISignal
+	// Fetch stats for every scanned commit concurrently: AI-flagged commits
+	// give AIAdditions, all of them TotalAdditions.
+	type commitDetail struct {
+		signals   []model.AISignal
+		additions int64
+		deletions int64
+		err       error
 	}
 
-	var flagged []flaggedCommit
-	for _, c := range commits {
-		signals := aidetect.Detect(c.Author, c.Message)
-		if len(signals) > 0 {
-			flagged = append(flagged, flaggedCommit{info: c, signals: signals})
+	details := make([]commitDetail, len(commits))
+	for i, c := range commits {
+		details[i].signals = aidetect.Detect(c.Author, c.Message, rules)
+		if len(details[i].signals) > 0 {
+			est.AICommits++
 		}
 	}
 
-	est.AICommits = int64(len(flagged))
-
 	if est.TotalCommits > 0 {
 		est.CommitPercent = float64(est.AICommits) / float64(est.TotalCommits) * 100
 	}
 
-	// Fetch stats for AI-flagged commits concurrently
-	type commitDetail struct {
-		index     int
-		additions int64
-		deletions int64
-		err       error
-	}
-
-	details := make([]commitDetail, len(flagged))
 	sem := make(chan struct{}, statsConcurrency)
 	var wg sync.WaitGroup
 
-	for i, fc := range flagged {
+	for i, c := range commits {
 		if ctx.Err() != nil {
 			break
 		}
@@ -65,39 +70,58 @@ func Estimate(ctx context.Context, cl provider.CommitLister, repo model.Repo, co
 			defer wg.Done()
 			defer func() { <-sem }()
 			add, del, err := cl.CommitStats(ctx, repo, hash)
-			details[idx] = commitDetail{index: idx, additions: add, deletions: del, err: err}
-		}(i, fc.info.Hash)
+			details[idx].additions, details[idx].deletions, details[idx].err = add, del, err
+		}(i, c.Hash)
 	}
 	wg.Wait()
 
 	var partialErrors []string
-	for i, fc := range flagged {
+	var zeroStats int
+	for i, c := range commits {
 		d := details[i]
 		if d.err != nil {
-			partialErrors = append(partialErrors, fmt.Sprintf("CommitStats %s: %v", fc.info.Hash, d.err))
+			partialErrors = append(partialErrors, fmt.Sprintf("CommitStats %s: %v", c.Hash, d.err))
 			continue
 		}
 
+		est.TotalAdditions += d.additions
+		if len(d.signals) == 0 {
+			continue
+		}
 		est.AIAdditions += d.additions
 
 		// Extract first line of commit message
-		firstLine := fc.info.Message
-		for j, ch := range fc.info.Message {
+		firstLine := c.Message
+		for j, ch := range c.Message {
 			if ch == '\n' {
-				firstLine = fc.info.Message[:j]
+				firstLine = c.Message[:j]
 				break
 			}
 		}
 
 		est.Details = append(est.Details, model.AICommit{
-			Hash:      fc.info.Hash,
-			Author:    fc.info.Author,
+			Hash:      c.Hash,
+			Author:    c.Author,
 			Message:   firstLine,
-			Signals:   fc.signals,
+			Signals:   d.signals,
 			Additions: d.additions,
 			Deletions: d.deletions,
 		})
+		if d.additions == 0 && d.deletions == 0 {
+			zeroStats++
+		}
 	}
 
-	return est, partialErrors, nil
+	// Some providers report 0/0 for certain commits (e.g. Bitbucket merge
+	// commits). When every fetched stat is zero, additions are unknown rather
+	// than genuinely zero, so flag it instead of reporting 0 silently.
+	if len(est.Details) > 0 && zeroStats == len(est.Details) {
+		est.AdditionsUnavailable = true
+		partialErrors = append(partialErrors, fmt.Sprintf("CommitStats returned 0 additions and 0 deletions for all %d AI commits; line additions are unavailable from the provider, not zero", zeroStats))
+	}
+	if !est.AdditionsUnavailable && est.TotalAdditions > 0 {
+		est.AdditionPercent = float64(est.AIAdditions) / float64(est.TotalAdditions) * 100
+	}
+
+	return est, partialErrors
 }
@@ -40,7 +40,7 @@ func TestEstimateBasic(t *testing.T) {
 	}
 
 	repo := model.Repo{Slug: "test-repo", URL: "https://github.com/org/test-repo"}
-	estimate, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500)
+	estimate, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500, nil)
 	if err != nil {
 		t.Fatalf("Estimate: %v", err)
 	}
@@ -68,7 +68,7 @@ func TestEstimateNoAICommits(t *testing.T) {
 	}
 
 	repo := model.Repo{Slug: "test-repo", URL: "https://github.com/org/test-repo"}
-	estimate, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500)
+	estimate, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500, nil)
 	if err != nil {
 		t.Fatalf("Estimate: %v", err)
 	}
@@ -91,11 +91,14 @@ func TestEstimatePercentCalculation(t *testing.T) {
 		},
 		stats: map[string][2]int64{
 			"a": {200, 50},
+			"b": {300, 20},
+			"c": {100, 0},
+			"d": {200, 10},
 		},
 	}
 
 	repo := model.Repo{Slug: "r", URL: "https://github.com/o/r"}
-	est, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500)
+	est, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500, nil)
 	if err != nil {
 		t.Fatalf("Estimate: %v", err)
 	}
@@ -103,6 +106,38 @@ func TestEstimatePercentCalculation(t *testing.T) {
 	if est.CommitPercent != 25.0 {
 		t.Errorf("expected 25.0%% commit percent, got %f", est.CommitPercent)
 	}
+	if est.TotalAdditions != 800 {
+		t.Errorf("expected 800 total additions, got %d", est.TotalAdditions)
+	}
+	if est.AIAdditions != 200 {
+		t.Errorf("expected 200 AI additions, got %d", est.AIAdditions)
+	}
+	if est.AdditionPercent != 25.0 {
+		t.Errorf("expected 25.0%% addition percent, got %f", est.AdditionPercent)
+	}
+}
+
+func TestEstimateAdditionPercentUnavailable(t *testing.T) {
+	mock := &mockCommitLister{
+		commits: []provider.CommitInfo{
+			{Hash: "a", Author: "Dev <d@e.com>", Message: "Merge PR\n\nCo-Authored-By: Claude <c@a.com>"},
+			{Hash: "b", Author: "Dev <d@e.com>", Message: "fix"},
+		},
+		stats: map[string][2]int64{
+			"b": {40, 2},
+		},
+	}
+
+	est, _, err := aiestimate.Estimate(context.Background(), mock, model.Repo{Slug: "r"}, 500, nil)
+	if err != nil {
+		t.Fatalf("Estimate: %v", err)
+	}
+	if est.TotalAdditions != 40 {
+		t.Errorf("expected 40 total additions, got %d", est.TotalAdditions)
+	}
+	if !est.AdditionsUnavailable || est.AdditionPercent != 0 {
+		t.Errorf("expected no addition percent when AI additions are unavailable, got %f (unavailable=%v)", est.AdditionPercent, est.AdditionsUnavailable)
+	}
 }
 
 func TestEstimateFirstLineMessage(t *testing.T) {
@@ -116,7 +151,7 @@ func TestEstimateFirstLineMessage(t *testing.T) {
 	}
 
 	repo := model.Repo{Slug: "r", URL: "https://github.com/o/r"}
-	est, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500)
+	est, _, err := aiestimate.Estimate(context.Background(), mock, repo, 500, nil)
 	if err != nil {
 		t.Fatalf("Estimate: %v", err)
 	}
@@ -128,3 +163,29 @@ func TestEstimateFirstLineMessage(t *testing.T) {
 		t.Errorf("expected first line only, got %q", est.Details[0].Message)
 	}
 }
+
+func TestEstimateAllZeroStats(t *testing.T) {
+	mock := &mockCommitLister{
+		commits: []provider.CommitInfo{
+			{Hash: "abc", Author: "Dev <dev@e.com>", Message: "Merge PR\n\nCo-Authored-By: Claude <noreply@anthropic.com>"},
+			{Hash: "def", Author: "dependabot[bot] <bot@github.com>", Message: "chore: bump deps"},
+		},
+	}
+
+	estimate, partialErrs, err := aiestimate.Estimate(context.Background(), mock, model.Repo{Slug: "test-repo"}, 500, nil)
+	if err != nil {
+		t.Fatalf("Estimate: %v", err)
+	}
+	if !estimate.AdditionsUnavailable {
+		t.Error("expected additions to be flagged unavailable")
+	}
+	if len(partialErrs) != 1 {
+		t.Errorf("expected one diagnostic, got %v", partialErrs)
+	}
+
+	mock.stats = map[string][2]int64{"abc": {10, 0}}
+	estimate, partialErrs, _ = aiestimate.Estimate(context.Background(), mock, model.Repo{Slug: "test-repo"}, 500, nil)
+	if estimate.AdditionsUnavailable || len(partialErrs) != 0 {
+		t.Errorf("expected no flag when some commits have stats, got %v, %v", estimate.AdditionsUnavailable, partialErrs)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/boyter/scc/v3/processor"
@@ -16,26 +18,260 @@ import (
 var initOnce sync.Once
 
 // Analyzer wraps scc's processor package to analyze source code directories.
-type Analyzer struct{}
+type Analyzer struct {
+	largeFileSize int64
+	overrides     LanguageOverrides
+	splitTests    bool
+	traceSkips    bool
+	docExtensions []string
+	noRepoConfig  bool
+	noIgnoreFiles bool
+	excludePaths  []string
+	includeLangs  map[string]bool // lowercased
+	excludeLangs  map[string]bool // lowercased
+}
+
+// MaxTracedSkips caps the skipped paths WithTraceSkips records per repository.
+const MaxTracedSkips = 20
+
+// Reasons recorded in model.SkippedFile by WithTraceSkips.
+const (
+	SkipVendored        = "vendored"
+	SkipVendoredDir     = "vendored directory"
+	SkipGenerated       = "generated"
+	SkipUnreadable      = "unreadable"
+	SkipUnknownLanguage = "unknown language"
+	SkipBinary          = "binary"
+	SkipExcluded        = "excluded by " + RepoConfigFile
+	SkipIgnoredLanguage = "language ignored by " + RepoConfigFile
+	SkipIgnored         = "ignored by .gitignore or " + IgnoreFile
+	SkipExcludedPath    = "excluded by --exclude-path"
+	SkipNotInLanguages  = "language not in --languages"
+	SkipExcludedLang    = "language excluded by --exclude-languages"
+)
+
+// Option configures optional Analyzer behavior.
+type Option func(*Analyzer)
+
+// WithLargeFiles makes the analyzer record every file of at least minBytes
+// in RepoStats.LargeFiles, whatever its language, as a Git LFS candidate.
+// Vendored directories are not walked and so never reported.
+func WithLargeFiles(minBytes int64) Option {
+	return func(a *Analyzer) {
+		a.largeFileSize = minBytes
+	}
+}
+
+// WithLanguageOverrides makes the analyzer count files matching an override
+// as its language instead of the one scc detects, including files scc would
+// otherwise skip as unknown.
+func WithLanguageOverrides(o LanguageOverrides) Option {
+	return func(a *Analyzer) {
+		a.overrides = o
+	}
+}
+
+// WithSplitTests makes the analyzer count test files (see IsTestFile) in
+// RepoStats.TestFiles and TestCode instead of the language breakdown and
+// totals, which then cover production code only.
+func WithSplitTests() Option {
+	return func(a *Analyzer) {
+		a.splitTests = true
+	}
+}
+
+// WithDocExtensions makes the analyzer count files whose names end in one of
+// exts (".mdx", ".adoc", ".md.tmpl"; matched case-insensitively, the leading
+// dot optional) under the model.DocumentationLanguage pseudo-language instead
+// of the language scc detects. Extensions scc doesn't know are counted like
+// Markdown, every non-blank line as code.
+func WithDocExtensions(exts []string) Option {
+	return func(a *Analyzer) {
+		for _, ext := range exts {
+			ext = strings.ToLower(strings.TrimSpace(ext))
+			if ext == "" {
+				continue
+			}
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			a.docExtensions = append(a.docExtensions, ext)
+		}
+	}
+}
+
+// isDocFile reports whether name ends in one of the documentation extensions.
+func (a *Analyzer) isDocFile(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range a.docExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTraceSkips makes the analyzer record why files were left out of the
+// counts: RepoStats.SkippedTotal counts them and SkippedFiles keeps the first
+// MaxTracedSkips paths with their reason. A vendored directory is recorded
+// once, not per file.
+func WithTraceSkips() Option {
+	return func(a *Analyzer) {
+		a.traceSkips = true
+	}
+}
+
+// WithoutRepoConfig makes the analyzer ignore RepoConfigFile, counting
+// repositories the same way whatever their owners configured.
+func WithoutRepoConfig() Option {
+	return func(a *Analyzer) {
+		a.noRepoConfig = true
+	}
+}
+
+// WithoutIgnoreFiles makes the analyzer count files matched by the
+// repository's .gitignore files and IgnoreFile, which it skips by default.
+func WithoutIgnoreFiles() Option {
+	return func(a *Analyzer) {
+		a.noIgnoreFiles = true
+	}
+}
+
+// WithExcludePaths makes the analyzer leave files matching one of patterns
+// out of the counts, as filtered files. Patterns are relative to the analyzed
+// directory: one without a slash matches the file name at any depth, others
+// match from the root with "**" standing for any number of directories.
+func WithExcludePaths(patterns []string) Option {
+	return func(a *Analyzer) {
+		for _, p := range patterns {
+			if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+				a.excludePaths = append(a.excludePaths, p)
+			}
+		}
+	}
+}
+
+// excludesPath reports whether the slash-separated relPath matches one of
+// the WithExcludePaths patterns.
+func (a *Analyzer) excludesPath(relPath string) bool {
+	for _, p := range a.excludePaths {
+		if matchExcludePath(p, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIncludeLanguages makes the analyzer count only files whose language
+// (scc's name or model.DocumentationLanguage, matched ignoring case) is one of
+// langs; other files are filtered files.
+func WithIncludeLanguages(langs []string) Option {
+	return func(a *Analyzer) {
+		a.includeLangs = languageSet(a.includeLangs, langs)
+	}
+}
+
+// WithExcludeLanguages makes the analyzer leave files whose language is one
+// of langs (matched ignoring case) out of the counts, as filtered files.
+func WithExcludeLanguages(langs []string) Option {
+	return func(a *Analyzer) {
+		a.excludeLangs = languageSet(a.excludeLangs, langs)
+	}
+}
+
+func languageSet(set map[string]bool, langs []string) map[string]bool {
+	for _, l := range langs {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			if set == nil {
+				set = map[string]bool{}
+			}
+			set[l] = true
+		}
+	}
+	return set
+}
+
+// languageSkip returns the reason WithIncludeLanguages or
+// WithExcludeLanguages leaves lang out, or "" when it is counted.
+func (a *Analyzer) languageSkip(lang string) string {
+	lang = strings.ToLower(lang)
+	switch {
+	case a.includeLangs != nil && !a.includeLangs[lang]:
+		return SkipNotInLanguages
+	case a.excludeLangs[lang]:
+		return SkipExcludedLang
+	}
+	return ""
+}
 
 // New creates a new Analyzer instance. It ensures that scc's ProcessConstants
 // is called exactly once, even when multiple goroutines create analyzers concurrently.
-func New() *Analyzer {
-	initOnce.Do(func() {
-		processor.ProcessConstants()
-	})
-	return &Analyzer{}
+func New(opts ...Option) *Analyzer {
+	initOnce.Do(processor.ProcessConstants)
+	a := &Analyzer{}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // Analyze walks the given directory, detects languages, and returns aggregated
 // code statistics per language.
 func (a *Analyzer) Analyze(ctx context.Context, dir string) (*model.RepoStats, error) {
+	return a.analyze(ctx, dir, nil)
+}
+
+// AnalyzeFiles is like Analyze but only counts the given files, given as
+// slash-separated paths relative to dir. Files not on disk are ignored.
+func (a *Analyzer) AnalyzeFiles(ctx context.Context, dir string, files []string) (*model.RepoStats, error) {
+	only := make(map[string]bool, len(files))
+	for _, f := range files {
+		only[f] = true
+	}
+	return a.analyze(ctx, dir, only)
+}
+
+// analyze walks dir and counts every file, or only the files in only when it
+// is non-nil. Unless disabled, the RepoConfigFile at dir is applied on top of
+// the analyzer's options, and paths matched by .gitignore files or IgnoreFile
+// are skipped.
+func (a *Analyzer) analyze(ctx context.Context, dir string, only map[string]bool) (*model.RepoStats, error) {
+	var cfg *RepoConfig
+	if !a.noRepoConfig {
+		var err error
+		if cfg, err = LoadRepoConfig(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var ignore *ignoreRules
+	if !a.noIgnoreFiles {
+		ignore = &ignoreRules{}
+	}
+
 	langMap := map[string]*model.LanguageStats{}
 	var totalFiles int64
 	var filteredFiles int64
+	var topLevelDirs int
+	var largeFiles []model.LargeFile
+	var testFiles, testCode int64
+	var skipped []model.SkippedFile
+	var skippedTotal int64
+	skip := func(relPath, reason string) {
+		if !a.traceSkips {
+			return
+		}
+		skippedTotal++
+		if len(skipped) < MaxTracedSkips {
+			skipped = append(skipped, model.SkippedFile{Path: filepath.ToSlash(relPath), Reason: reason})
+		}
+	}
 
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			if rel, relErr := filepath.Rel(dir, path); relErr == nil {
+				skip(rel, SkipUnreadable)
+			}
 			return nil // skip unreadable files
 		}
 		if ctx.Err() != nil {
@@ -55,30 +291,83 @@ func (a *Analyzer) Analyze(ctx context.Context, dir string) (*model.RepoStats, e
 			}
 			// Use enry for vendor directory detection
 			if enry.IsVendor(relPath + "/") {
+				skip(relPath, SkipVendoredDir)
 				return filepath.SkipDir
 			}
+			if relPath != "." && cfg.excludes(filepath.ToSlash(relPath)) {
+				skip(relPath, SkipExcluded)
+				return filepath.SkipDir
+			}
+			if relPath != "." && ignore.ignores(filepath.ToSlash(relPath), true) {
+				skip(relPath, SkipIgnored)
+				return filepath.SkipDir
+			}
+			ignore.load(dir, filepath.ToSlash(relPath), ".gitignore")
+			if relPath == "." {
+				ignore.load(dir, ".", IgnoreFile)
+			}
+			if relPath != "." && !strings.ContainsRune(relPath, filepath.Separator) {
+				topLevelDirs++
+			}
 			return nil
 		}
 
+		if only != nil && !only[filepath.ToSlash(relPath)] {
+			return nil
+		}
+		if cfg.excludes(filepath.ToSlash(relPath)) {
+			filteredFiles++
+			skip(relPath, SkipExcluded)
+			return nil
+		}
+		if ignore.ignores(filepath.ToSlash(relPath), false) {
+			filteredFiles++
+			skip(relPath, SkipIgnored)
+			return nil
+		}
+		if a.excludesPath(filepath.ToSlash(relPath)) {
+			filteredFiles++
+			skip(relPath, SkipExcludedPath)
+			return nil
+		}
+
+		if a.largeFileSize > 0 && info.Size() >= a.largeFileSize {
+			largeFiles = append(largeFiles, model.LargeFile{Path: filepath.ToSlash(relPath), Size: info.Size()})
+		}
+
 		// Check if file path is a vendor file
 		if enry.IsVendor(relPath) {
 			filteredFiles++
+			skip(relPath, SkipVendored)
 			return nil
 		}
 
 		content, err := os.ReadFile(path)
 		if err != nil {
+			skip(relPath, SkipUnreadable)
 			return nil
 		}
+		content = toUTF8(content)
 
 		// Check if file is generated
 		if enry.IsGenerated(relPath, content) {
 			filteredFiles++
+			skip(relPath, SkipGenerated)
 			return nil
 		}
 
-		possibleLanguages, _ := processor.DetectLanguage(info.Name())
+		var possibleLanguages []string
+		if lang := a.overrides.lookup(info.Name()); lang != "" {
+			possibleLanguages = []string{lang}
+		} else {
+			possibleLanguages, _ = processor.DetectLanguage(info.Name())
+		}
+		doc := a.isDocFile(info.Name())
+		if doc && len(possibleLanguages) == 0 {
+			possibleLanguages = []string{"Markdown"}
+		}
 		if len(possibleLanguages) == 0 {
+			skip(relPath, SkipUnknownLanguage)
 			return nil
 		}
 
@@ -91,19 +380,42 @@ func (a *Analyzer) Analyze(ctx context.Context, dir string) (*model.RepoStats, e
 
 		job.Language = processor.DetermineLanguage(job.Filename, job.Language, job.PossibleLanguages, job.Content)
 		if job.Language == "" {
+			skip(relPath, SkipUnknownLanguage)
 			return nil
 		}
 
 		processor.CountStats(job)
 
 		if job.Binary {
+			skip(relPath, SkipBinary)
 			return nil
 		}
 
-		lang, ok := langMap[job.Language]
+		langName := job.Language
+		if doc {
+			langName = model.DocumentationLanguage
+		}
+		if cfg.ignores(langName) {
+			filteredFiles++
+			skip(relPath, SkipIgnoredLanguage)
+			return nil
+		}
+		if reason := a.languageSkip(langName); reason != "" {
+			filteredFiles++
+			skip(relPath, reason)
+			return nil
+		}
+
+		if a.splitTests && IsTestFile(filepath.ToSlash(relPath)) {
+			testFiles++
+			testCode += job.Code
+			return nil
+		}
+
+		lang, ok := langMap[langName]
 		if !ok {
-			lang = &model.LanguageStats{Name: job.Language}
-			langMap[job.Language] = lang
+			lang = &model.LanguageStats{Name: langName}
+			langMap[langName] = lang
 		}
 
 		lang.Files++
@@ -122,6 +434,18 @@ func (a *Analyzer) Analyze(ctx context.Context, dir string) (*model.RepoStats, e
 
 	stats := &model.RepoStats{}
 	stats.FilteredFiles = filteredFiles
+	stats.TopLevelDirs = topLevelDirs
+	sort.SliceStable(largeFiles, func(i, j int) bool {
+		return largeFiles[i].Size > largeFiles[j].Size
+	})
+	stats.LargeFiles = largeFiles
+	stats.TestFiles = testFiles
+	stats.TestCode = testCode
+	stats.SkippedFiles = skipped
+	stats.SkippedTotal = skippedTotal
+	if cfg != nil {
+		stats.Area = cfg.Area
+	}
 	for _, lang := range langMap {
 		stats.Languages = append(stats.Languages, *lang)
 		stats.Totals.Files += lang.Files
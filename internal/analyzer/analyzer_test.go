@@ -3,11 +3,16 @@ package analyzer_test
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/dsablic/codemium/internal/analyzer"
+	"github.com/dsablic/codemium/internal/model"
 )
 
 func TestAnalyzeDirectory(t *testing.T) {
@@ -122,3 +127,409 @@ func TestAnalyzeEmptyDirectory(t *testing.T) {
 		t.Errorf("expected 0 files, got %d", stats.Totals.Files)
 	}
 }
+
+func TestAnalyzeTopLevelDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"cmd", "internal", filepath.Join("internal", "pkg"), "docs"} {
+		os.MkdirAll(filepath.Join(dir, sub), 0755)
+	}
+	os.WriteFile(filepath.Join(dir, "cmd", "main.go"), []byte("package main\n"), 0644)
+
+	a := analyzer.New()
+	stats, err := a.Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	// Nested directories don't count toward the top-level total
+	if stats.TopLevelDirs != 3 {
+		t.Errorf("expected 3 top-level dirs, got %d", stats.TopLevelDirs)
+	}
+}
+
+func TestAnalyzeLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "assets"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "assets", "video.mp4"), make([]byte, 4096), 0644)
+	os.WriteFile(filepath.Join(dir, "data.json"), make([]byte, 2048), 0644)
+
+	stats, err := analyzer.New(analyzer.WithLargeFiles(2048)).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if len(stats.LargeFiles) != 2 {
+		t.Fatalf("expected 2 large files, got %+v", stats.LargeFiles)
+	}
+	if stats.LargeFiles[0].Path != "assets/video.mp4" || stats.LargeFiles[0].Size != 4096 {
+		t.Errorf("expected largest file first, got %+v", stats.LargeFiles[0])
+	}
+	if stats.LargeFiles[1].Path != "data.json" {
+		t.Errorf("expected file at the threshold to be included, got %+v", stats.LargeFiles[1])
+	}
+
+	stats, err = analyzer.New().Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.LargeFiles != nil {
+		t.Errorf("expected no large files without the option, got %+v", stats.LargeFiles)
+	}
+}
+
+func TestAnalyzeUTF16(t *testing.T) {
+	dir := t.TempDir()
+	src := "// Entry point\r\nclass Program\r\n{\r\n    static void Main() { }\r\n}\r\n"
+
+	// UTF-16 LE with byte order mark, as written by Visual Studio
+	content := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(src)) {
+		content = binary.LittleEndian.AppendUint16(content, u)
+	}
+	os.WriteFile(filepath.Join(dir, "Program.cs"), content, 0644)
+
+	stats, err := analyzer.New().Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if len(stats.Languages) != 1 || stats.Languages[0].Name != "C#" {
+		t.Fatalf("expected C#, got %+v", stats.Languages)
+	}
+	cs := stats.Languages[0]
+	if cs.Lines != 5 || cs.Code != 4 || cs.Comments != 1 {
+		t.Errorf("expected 5 lines (4 code, 1 comment), got %d lines (%d code, %d comments)", cs.Lines, cs.Code, cs.Comments)
+	}
+}
+
+func TestAnalyzeLanguageOverrides(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "rules.dsl"), []byte("// rule\nrule a {\n}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Buildfile"), []byte("def build():\n    pass\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+
+	overrides, err := analyzer.ParseLanguageOverrides(strings.NewReader(`
+# custom DSL counted as Go
+.DSL = go
+Buildfile = Python
+`))
+	if err != nil {
+		t.Fatalf("ParseLanguageOverrides: %v", err)
+	}
+
+	stats, err := analyzer.New(analyzer.WithLanguageOverrides(overrides)).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	langs := map[string]int64{}
+	for _, l := range stats.Languages {
+		langs[l.Name] = l.Files
+	}
+	if langs["Go"] != 2 || langs["Python"] != 1 {
+		t.Errorf("expected 2 Go files and 1 Python file, got %v", langs)
+	}
+}
+
+func TestParseLanguageOverridesErrors(t *testing.T) {
+	for _, input := range []string{
+		".tsx = NotALanguage\n",
+		".tsx\n",
+		"= Go\n",
+	} {
+		if _, err := analyzer.ParseLanguageOverrides(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestAnalyzeFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pkg", "util.go"), []byte("package pkg\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "script.py"), []byte("print('hi')\n"), 0644)
+
+	a := analyzer.New()
+	stats, err := a.AnalyzeFiles(context.Background(), dir, []string{"pkg/util.go", "missing.go"})
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+
+	if stats.Totals.Files != 1 {
+		t.Errorf("expected 1 file, got %d", stats.Totals.Files)
+	}
+	if len(stats.Languages) != 1 || stats.Languages[0].Name != "Go" {
+		t.Errorf("expected only Go, got %+v", stats.Languages)
+	}
+}
+
+func TestAnalyzeSplitTests(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web", "spec"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n\nimport \"testing\"\n\nfunc TestMain(t *testing.T) {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "app.spec.ts"), []byte("it('works', () => {});\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "spec", "helper.ts"), []byte("export const x = 1;\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithSplitTests()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.TestFiles != 3 || stats.TestCode != 5 {
+		t.Errorf("expected 3 test files with 5 code lines, got %d files, %d code", stats.TestFiles, stats.TestCode)
+	}
+	if stats.Totals.Files != 1 || stats.Totals.Code != 2 {
+		t.Errorf("expected totals to cover main.go only, got %+v", stats.Totals)
+	}
+
+	stats, err = analyzer.New().Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.TestFiles != 0 || stats.Totals.Files != 4 {
+		t.Errorf("expected tests counted as code without the option, got %d test files, %+v", stats.TestFiles, stats.Totals)
+	}
+}
+
+func TestAnalyzeDocExtensions(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "guide.mdx"), []byte("# Guide\n\n<Note>hi</Note>\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "intro.ADOC"), []byte("= Intro\n\nText\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md.tmpl"), []byte("# {{ .Name }}\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithDocExtensions([]string{"mdx", ".adoc", ".md.tmpl"})).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	langs := map[string]model.LanguageStats{}
+	for _, l := range stats.Languages {
+		langs[l.Name] = l
+	}
+	doc := langs[model.DocumentationLanguage]
+	if doc.Files != 3 || doc.Code != 5 {
+		t.Errorf("expected 3 documentation files with 5 code lines, got %+v", doc)
+	}
+	if len(langs) != 2 || langs["Go"].Files != 1 {
+		t.Errorf("expected only Go and Documentation, got %+v", stats.Languages)
+	}
+}
+
+func TestAnalyzeTraceSkips(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "node_modules", "left-pad"), 0755)
+	os.WriteFile(filepath.Join(dir, "node_modules", "left-pad", "index.js"), []byte("module.exports = 1;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.xyz"), []byte("hello\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithTraceSkips()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	want := map[string]string{
+		"node_modules": analyzer.SkipVendoredDir,
+		"notes.xyz":    analyzer.SkipUnknownLanguage,
+	}
+	if stats.SkippedTotal != int64(len(want)) || len(stats.SkippedFiles) != len(want) {
+		t.Fatalf("expected %d skips, got total %d: %+v", len(want), stats.SkippedTotal, stats.SkippedFiles)
+	}
+	for _, s := range stats.SkippedFiles {
+		if want[s.Path] != s.Reason {
+			t.Errorf("unexpected skip %+v", s)
+		}
+	}
+
+	// The sample is capped but the total keeps counting
+	for i := range analyzer.MaxTracedSkips + 5 {
+		os.WriteFile(filepath.Join(dir, fmt.Sprintf("data%d.xyz", i)), []byte("x\n"), 0644)
+	}
+	stats, _ = analyzer.New(analyzer.WithTraceSkips()).Analyze(context.Background(), dir)
+	if len(stats.SkippedFiles) != analyzer.MaxTracedSkips || stats.SkippedTotal != int64(analyzer.MaxTracedSkips+7) {
+		t.Errorf("expected %d sampled of %d, got %d of %d", analyzer.MaxTracedSkips, analyzer.MaxTracedSkips+7, len(stats.SkippedFiles), stats.SkippedTotal)
+	}
+
+	stats, _ = analyzer.New().Analyze(context.Background(), dir)
+	if stats.SkippedTotal != 0 || stats.SkippedFiles != nil {
+		t.Errorf("expected no skip tracing without the option, got %+v", stats.SkippedFiles)
+	}
+}
+
+func TestAnalyzeRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "gen", "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "gen", "api", "client.go"), []byte("package api\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "schema.pb.py"), []byte("x = 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "build.sh"), []byte("echo hi\n"), 0644)
+	os.WriteFile(filepath.Join(dir, analyzer.RepoConfigFile), []byte("area: payments\nexclude_paths: [gen, \"*.pb.py\"]\nignore_languages: [shell]\n"), 0644)
+
+	stats, err := analyzer.New().Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Area != "payments" {
+		t.Errorf("expected area payments, got %q", stats.Area)
+	}
+	langs := map[string]model.LanguageStats{}
+	for _, l := range stats.Languages {
+		langs[l.Name] = l
+	}
+	if langs["Go"].Files != 1 || langs["Python"].Files != 0 || langs["Shell"].Files != 0 {
+		t.Errorf("expected excluded paths and ignored languages left out, got %+v", stats.Languages)
+	}
+	if stats.FilteredFiles != 2 {
+		t.Errorf("expected the excluded file and ignored language counted as filtered, got %d", stats.FilteredFiles)
+	}
+
+	stats, err = analyzer.New(analyzer.WithoutRepoConfig()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Area != "" || stats.Totals.Files != 5 {
+		t.Errorf("expected the repo config ignored, got area %q and %d files", stats.Area, stats.Totals.Files)
+	}
+}
+
+func TestAnalyzeIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "gen"), 0755)
+	os.MkdirAll(filepath.Join(dir, "web", "out"), 0755)
+	os.MkdirAll(filepath.Join(dir, "samples"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "gen", "bundle.js"), []byte("var a = 1;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "gen", "app.js"), []byte("var b = 2;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "app.js"), []byte("var c = 3;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "out", "app.js"), []byte("var d = 4;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "samples", "fixture.py"), []byte("x = 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("# build output\ngen/\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", ".gitignore"), []byte("out/\n"), 0644)
+	os.WriteFile(filepath.Join(dir, analyzer.IgnoreFile), []byte("samples/\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithTraceSkips()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Totals.Files != 3 {
+		t.Errorf("expected main.go, web/app.js and the ignore file only, got %d files: %+v", stats.Totals.Files, stats.Languages)
+	}
+	var ignored int
+	for _, s := range stats.SkippedFiles {
+		if s.Reason == analyzer.SkipIgnored {
+			ignored++
+		}
+	}
+	if ignored != 3 {
+		t.Errorf("expected gen, web/out and samples skipped as ignored, got %+v", stats.SkippedFiles)
+	}
+
+	stats, err = analyzer.New(analyzer.WithoutIgnoreFiles()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Totals.Files != 7 {
+		t.Errorf("expected ignore files disregarded, got %d files", stats.Totals.Files)
+	}
+}
+
+func TestAnalyzeExcludePaths(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "web", "lib"), 0755)
+	os.MkdirAll(filepath.Join(dir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main_test.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pkg", "util_test.go"), []byte("package pkg\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "app.js"), []byte("var a = 1;\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "lib", "app.min.js"), []byte("var b=2;\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithExcludePaths([]string{"**/*_test.go", "*.min.js"})).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Totals.Files != 2 {
+		t.Errorf("expected main.go and web/app.js counted, got %d files", stats.Totals.Files)
+	}
+	if stats.FilteredFiles != 3 {
+		t.Errorf("expected the test files and minified file filtered, got %d", stats.FilteredFiles)
+	}
+
+	stats, err = analyzer.New(analyzer.WithExcludePaths([]string{"web/**"})).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Totals.Files != 3 || stats.FilteredFiles != 2 {
+		t.Errorf("expected everything under web filtered, got %d files and %d filtered", stats.Totals.Files, stats.FilteredFiles)
+	}
+
+	if err := analyzer.ValidateExcludePath("src/[a"); err == nil {
+		t.Error("expected an invalid pattern to be rejected")
+	}
+}
+
+func TestAnalyzeLanguageFilters(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "tool.py"), []byte("x = 1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("a: 1\n"), 0644)
+
+	stats, err := analyzer.New(analyzer.WithIncludeLanguages([]string{"go"})).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if len(stats.Languages) != 1 || stats.Languages[0].Name != "Go" {
+		t.Errorf("expected only Go, got %+v", stats.Languages)
+	}
+	if stats.FilteredFiles != 2 {
+		t.Errorf("expected the Python and YAML files filtered, got %d", stats.FilteredFiles)
+	}
+
+	stats, err = analyzer.New(analyzer.WithExcludeLanguages([]string{"YAML"}), analyzer.WithTraceSkips()).Analyze(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("analysis failed: %v", err)
+	}
+	if stats.Totals.Files != 2 || stats.FilteredFiles != 1 {
+		t.Errorf("expected Go and Python counted and YAML filtered, got %d files and %d filtered", stats.Totals.Files, stats.FilteredFiles)
+	}
+	if len(stats.SkippedFiles) != 1 || stats.SkippedFiles[0].Reason != analyzer.SkipExcludedLang {
+		t.Errorf("expected config.yaml skipped as an excluded language, got %+v", stats.SkippedFiles)
+	}
+
+	if lang, err := analyzer.ValidateLanguage("typescript"); err != nil || lang != "TypeScript" {
+		t.Errorf("expected typescript to validate as TypeScript, got %q, %v", lang, err)
+	}
+	if _, err := analyzer.ValidateLanguage("Klingon"); err == nil {
+		t.Error("expected an unknown language to be rejected")
+	}
+}
+
+func TestLoadRepoConfigErrors(t *testing.T) {
+	for _, content := range []string{
+		"owner: me\n",                   // unknown key
+		"ignore_languages: [Klingon]\n", // unknown language
+		"exclude_paths: [\"[\"]\n",      // bad pattern
+	} {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, analyzer.RepoConfigFile), []byte(content), 0644)
+		if _, err := analyzer.LoadRepoConfig(dir); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+	if cfg, err := analyzer.LoadRepoConfig(t.TempDir()); cfg != nil || err != nil {
+		t.Errorf("expected no config and no error without the file, got %+v, %v", cfg, err)
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for p, want := range map[string]bool{
+		"pkg/foo_test.go":        true,
+		"src/app.spec.ts":        true,
+		"test/helpers.py":        true,
+		"lib/spec/model_spec.rb": true,
+		"src/__tests__/a.js":     true,
+		"tests/test_api.py":      true,
+		"src/main.go":            false,
+		"docs/testing.md":        false,
+	} {
+		if got := analyzer.IsTestFile(p); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
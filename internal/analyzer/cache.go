@@ -0,0 +1,80 @@
+// internal/analyzer/cache.go
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// analysisCacheVersion is part of every cache key; bump it when the analysis
+// itself changes (e.g. an scc upgrade) so stale entries stop matching.
+const analysisCacheVersion = "2"
+
+// AnalysisCache stores per-repository stats on disk, keyed by repository,
+// HEAD commit and a variant string describing the analysis settings, so a
+// repository whose default branch hasn't moved needn't be cloned again.
+type AnalysisCache struct {
+	dir     string
+	variant string
+}
+
+// NewAnalysisCache returns a cache storing entries in dir. Entries written
+// with a different variant are never returned.
+func NewAnalysisCache(dir, variant string) *AnalysisCache {
+	return &AnalysisCache{dir: dir, variant: variant}
+}
+
+func (c *AnalysisCache) path(repoURL, sha string) string {
+	sum := sha256.Sum256([]byte(analysisCacheVersion + "\x00" + c.variant + "\x00" + repoURL + "\x00" + sha))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load returns the stats stored for repoURL at commit sha, if any. Unreadable
+// entries count as misses.
+func (c *AnalysisCache) Load(repoURL, sha string) (*model.RepoStats, bool) {
+	data, err := os.ReadFile(c.path(repoURL, sha))
+	if err != nil {
+		return nil, false
+	}
+	var stats model.RepoStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, false
+	}
+	return &stats, true
+}
+
+// Store saves stats for repoURL at commit sha. The entry is written to a
+// temporary file and renamed, so concurrent workers never read a partial one.
+func (c *AnalysisCache) Store(repoURL, sha string, stats *model.RepoStats) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("create analysis cache: %w", err)
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("encode analysis cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write analysis cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write analysis cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write analysis cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(repoURL, sha)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write analysis cache entry: %w", err)
+	}
+	return nil
+}
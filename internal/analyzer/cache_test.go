@@ -0,0 +1,34 @@
+// internal/analyzer/cache_test.go
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/dsablic/codemium/internal/analyzer"
+	"github.com/dsablic/codemium/internal/model"
+)
+
+func TestAnalysisCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := analyzer.NewAnalysisCache(dir, "split-tests=false")
+	url := "https://github.com/org/repo"
+
+	if _, ok := cache.Load(url, "abc"); ok {
+		t.Fatal("expected a miss on an empty cache")
+	}
+	stats := &model.RepoStats{License: "MIT", Totals: model.Stats{Code: 42}}
+	if err := cache.Store(url, "abc", stats); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	got, ok := cache.Load(url, "abc")
+	if !ok || got.Totals.Code != 42 || got.License != "MIT" {
+		t.Errorf("expected cached stats, got %+v (hit %v)", got, ok)
+	}
+	if _, ok := cache.Load(url, "def"); ok {
+		t.Error("expected a miss for another HEAD")
+	}
+	if _, ok := analyzer.NewAnalysisCache(dir, "split-tests=true").Load(url, "abc"); ok {
+		t.Error("expected a miss for another variant")
+	}
+}
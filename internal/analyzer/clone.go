@@ -5,44 +5,240 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/go-git/go-billy/v5/osfs"
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // Cloner performs shallow git clones into temporary directories.
 type Cloner struct {
-	token    string
-	username string
-	client   *http.Client
+	token      string
+	username   string
+	client     *http.Client
+	keepDir    string
+	submodules bool
+	cacheDir   string
+	retries    int
+
+	keepDirs     sync.Map // kept clone dir -> in use
+	cacheLocks   sync.Map // cache path -> *sync.Mutex
+	cacheClones  atomic.Int64
+	cacheFetches atomic.Int64
+}
+
+// ClonerOption configures optional Cloner behavior.
+type ClonerOption func(*Cloner)
+
+// WithKeepDir makes the Cloner clone into <dir>/<host>/<owner>/<repo>
+// instead of a temporary directory. The returned cleanup functions leave the
+// working tree on disk for inspection.
+func WithKeepDir(dir string) ClonerOption {
+	return func(c *Cloner) {
+		c.keepDir = dir
+	}
+}
+
+// WithSubmodules makes Clone initialize and update submodules (recursively,
+// shallow) after cloning so their code is included in the analysis. Tarball
+// downloads never include submodules.
+func WithSubmodules() ClonerOption {
+	return func(c *Cloner) {
+		c.submodules = true
+	}
+}
+
+// WithCacheDir makes Clone and CloneFull keep a bare clone of each
+// repository under <dir>/<host>/<path>.git and, on later runs, fetch into it
+// and check the default branch out into the working directory instead of
+// cloning again. Cleanup functions remove the working directory, never the
+// cache. The cache is not used with WithSubmodules.
+func WithCacheDir(dir string) ClonerOption {
+	return func(c *Cloner) {
+		c.cacheDir = dir
+	}
+}
+
+// DefaultCloneRetries is how many times Clone and CloneFull retry a clone
+// that failed with a transient error, unless WithCloneRetries says otherwise.
+const DefaultCloneRetries = 2
+
+// cloneRetryBaseDelay is the wait before the first clone retry; it doubles
+// each time.
+var cloneRetryBaseDelay = time.Second
+
+// WithCloneRetries makes Clone and CloneFull retry a clone up to n times
+// after a transient failure (network errors and timeouts, HTTP 5xx and 429
+// from the git server), backing off exponentially. Authentication failures
+// and missing repositories fail at once. n = 0 disables retries.
+func WithCloneRetries(n int) ClonerOption {
+	return func(c *Cloner) {
+		c.retries = max(n, 0)
+	}
 }
 
 // NewCloner creates a Cloner. If token is non-empty it will be used for
 // HTTP basic-auth. If username is empty, "x-token-auth" is used (works
 // for OAuth tokens on GitHub and Bitbucket). For Bitbucket API tokens,
 // pass the Atlassian email as username.
-func NewCloner(token, username string) *Cloner {
-	return &Cloner{token: token, username: username, client: &http.Client{}}
+func NewCloner(token, username string, opts ...ClonerOption) *Cloner {
+	c := &Cloner{token: token, username: username, client: &http.Client{}, retries: DefaultCloneRetries}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// workDir creates the directory a repository is cloned into and returns it
+// with its cleanup function. Without a keep directory this is a fresh temp
+// dir; otherwise it is <keepDir>/<host>/<path> (see repoPath), emptied first
+// so re-runs start clean. While a kept directory is in use, until cleanup,
+// another clone of the same repository gets <dir>-2, <dir>-3 and so on
+// instead of wiping it.
+func (c *Cloner) workDir(repoURL string) (string, func(), error) {
+	if c.keepDir == "" {
+		tmpDir, err := os.MkdirTemp("", "codemium-*")
+		if err != nil {
+			return "", nil, fmt.Errorf("create temp dir: %w", err)
+		}
+		return tmpDir, func() { os.RemoveAll(tmpDir) }, nil
+	}
+
+	host, segs := repoPath(repoURL)
+	base := filepath.Join(append([]string{c.keepDir, host}, segs...)...)
+	dir := base
+	for n := 2; ; n++ {
+		if _, taken := c.keepDirs.LoadOrStore(dir, true); !taken {
+			break
+		}
+		dir = fmt.Sprintf("%s-%d", base, n)
+	}
+	release := func() { c.keepDirs.Delete(dir) }
+
+	if err := os.RemoveAll(dir); err != nil {
+		release()
+		return "", nil, fmt.Errorf("clear clone dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		release()
+		return "", nil, fmt.Errorf("create clone dir: %w", err)
+	}
+	return dir, release, nil
+}
+
+// repoPath splits a clone or tarball download URL into its host and the
+// repository's path segments, e.g. "https://gitlab.com/grp/sub/api.git" ->
+// "gitlab.com", [grp sub api] and "https://bitbucket.org/ws/repo/get/main.tar.gz"
+// -> "bitbucket.org", [ws repo]. File paths get "local" as their host. The
+// owner segments keep repositories with the same name apart.
+func repoPath(repoURL string) (host string, segs []string) {
+	host, p := "local", repoURL
+	if u, err := url.Parse(repoURL); err == nil && u.Host != "" {
+		host, p = u.Host, u.Path
+	}
+	for _, seg := range strings.Split(filepath.ToSlash(p), "/") {
+		if seg != "" && seg != "." && seg != ".." {
+			segs = append(segs, seg)
+		}
+	}
+	if n := len(segs); n >= 3 && segs[n-2] == "get" && strings.HasSuffix(segs[n-1], ".tar.gz") {
+		segs = segs[:n-2]
+	}
+	if n := len(segs); n > 0 {
+		segs[n-1] = strings.TrimSuffix(segs[n-1], ".git")
+		if segs[n-1] == "" {
+			segs = segs[:n-1]
+		}
+	}
+	if len(segs) == 0 {
+		segs = []string{"repo"}
+	}
+	return host, segs
+}
+
+// auth returns HTTP basic auth for the Cloner's token, or nil without one.
+func (c *Cloner) auth() transport.AuthMethod {
+	if c.token == "" {
+		return nil
+	}
+	username := c.username
+	if username == "" {
+		username = "x-token-auth"
+	}
+	return &githttp.BasicAuth{
+		Username: username,
+		Password: c.token,
+	}
+}
+
+// HeadSHA returns the commit the remote's branch points at, or its HEAD when
+// branch is empty, without cloning (the equivalent of git ls-remote).
+func (c *Cloner) HeadSHA(ctx context.Context, cloneURL, branch string) (string, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{cloneURL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: c.auth()})
+	if err != nil {
+		return "", fmt.Errorf("list remote refs: %w", err)
+	}
+
+	want := plumbing.HEAD
+	if branch != "" {
+		want = plumbing.NewBranchReferenceName(branch)
+	}
+	byName := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, ref := range refs {
+		byName[ref.Name()] = ref
+	}
+	ref, ok := byName[want]
+	if ok && ref.Type() == plumbing.SymbolicReference {
+		ref, ok = byName[ref.Target()]
+	}
+	if !ok || ref.Hash().IsZero() {
+		return "", fmt.Errorf("remote has no %s", want)
+	}
+	return ref.Hash().String(), nil
 }
 
 // Clone shallow-clones the repository at cloneURL into a temporary directory.
 // It returns the directory path, a cleanup function that removes the directory,
 // and any error. The caller must call cleanup when done with the directory.
+// Transient failures are retried (see WithCloneRetries).
 func (c *Cloner) Clone(ctx context.Context, cloneURL string) (dir string, cleanup func(), err error) {
-	tmpDir, err := os.MkdirTemp("", "codemium-*")
-	if err != nil {
-		return "", nil, fmt.Errorf("create temp dir: %w", err)
+	err = c.withRetries(ctx, func() error {
+		dir, cleanup, err = c.clone(ctx, cloneURL)
+		return err
+	})
+	return dir, cleanup, err
+}
+
+func (c *Cloner) clone(ctx context.Context, cloneURL string) (dir string, cleanup func(), err error) {
+	if c.useCache() {
+		_, dir, cleanup, err := c.checkoutCached(ctx, cloneURL)
+		return dir, cleanup, err
 	}
 
-	cleanupFn := func() {
-		os.RemoveAll(tmpDir)
+	tmpDir, cleanupFn, err := c.workDir(cloneURL)
+	if err != nil {
+		return "", nil, err
 	}
 
 	opts := &git.CloneOptions{
@@ -51,18 +247,13 @@ func (c *Cloner) Clone(ctx context.Context, cloneURL string) (dir string, cleanu
 		SingleBranch: true,
 		Tags:         git.NoTags,
 	}
-
-	if c.token != "" {
-		username := c.username
-		if username == "" {
-			username = "x-token-auth"
-		}
-		opts.Auth = &githttp.BasicAuth{
-			Username: username,
-			Password: c.token,
-		}
+	if c.submodules {
+		opts.RecurseSubmodules = git.DefaultSubmoduleRecursionDepth
+		opts.ShallowSubmodules = true
 	}
 
+	opts.Auth = c.auth()
+
 	_, err = git.PlainCloneContext(ctx, tmpDir, false, opts)
 	if err != nil {
 		cleanupFn()
@@ -74,15 +265,24 @@ func (c *Cloner) Clone(ctx context.Context, cloneURL string) (dir string, cleanu
 
 // CloneFull clones the repository at cloneURL with full history into a
 // temporary directory. It returns the go-git Repository handle, the directory
-// path, a cleanup function, and any error.
+// path, a cleanup function, and any error. Transient failures are retried
+// like Clone's.
 func (c *Cloner) CloneFull(ctx context.Context, cloneURL string) (repo *git.Repository, dir string, cleanup func(), err error) {
-	tmpDir, err := os.MkdirTemp("", "codemium-*")
-	if err != nil {
-		return nil, "", nil, fmt.Errorf("create temp dir: %w", err)
+	err = c.withRetries(ctx, func() error {
+		repo, dir, cleanup, err = c.cloneFull(ctx, cloneURL)
+		return err
+	})
+	return repo, dir, cleanup, err
+}
+
+func (c *Cloner) cloneFull(ctx context.Context, cloneURL string) (repo *git.Repository, dir string, cleanup func(), err error) {
+	if c.useCache() {
+		return c.checkoutCached(ctx, cloneURL)
 	}
 
-	cleanupFn := func() {
-		os.RemoveAll(tmpDir)
+	tmpDir, cleanupFn, err := c.workDir(cloneURL)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	opts := &git.CloneOptions{
@@ -90,16 +290,7 @@ func (c *Cloner) CloneFull(ctx context.Context, cloneURL string) (repo *git.Repo
 		Tags: git.NoTags,
 	}
 
-	if c.token != "" {
-		username := c.username
-		if username == "" {
-			username = "x-token-auth"
-		}
-		opts.Auth = &githttp.BasicAuth{
-			Username: username,
-			Password: c.token,
-		}
-	}
+	opts.Auth = c.auth()
 
 	r, err := git.PlainCloneContext(ctx, tmpDir, false, opts)
 	if err != nil {
@@ -110,6 +301,358 @@ func (c *Cloner) CloneFull(ctx context.Context, cloneURL string) (repo *git.Repo
 	return r, tmpDir, cleanupFn, nil
 }
 
+// withRetries runs clone, retrying it up to c.retries times while it fails
+// with a retryable error, waiting cloneRetryBaseDelay, then twice that, and
+// so on between tries. Cancellation stops retrying immediately. Each try
+// starts from a fresh working directory, since a failed clone removes its
+// own.
+func (c *Cloner) withRetries(ctx context.Context, clone func() error) error {
+	delay := cloneRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := clone()
+		if err == nil || attempt >= c.retries || ctx.Err() != nil || !retryableCloneError(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// retryableCloneError reports whether a clone error is likely transient:
+// a network error or timeout, a connection cut mid-transfer, or an HTTP 5xx
+// or 429 from the git server. Authentication and authorization failures,
+// missing or empty repositories, cancellation and anything unrecognized are
+// not retried.
+func retryableCloneError(err error) bool {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, transport.ErrEmptyRemoteRepository):
+		return false
+	}
+	// go-git wraps HTTP status errors in an UnexpectedError without Unwrap.
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		err = unexpected.Err
+	}
+	var statusErr *githttp.Err
+	if errors.As(err, &statusErr) {
+		code := statusErr.StatusCode()
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// useCache reports whether clones go through the WithCacheDir cache.
+func (c *Cloner) useCache() bool {
+	return c.cacheDir != "" && !c.submodules
+}
+
+// CacheStats returns how many repositories the WithCacheDir cache had to
+// clone and how many it only fetched into since the Cloner was created.
+func (c *Cloner) CacheStats() (clones, fetches int64) {
+	return c.cacheClones.Load(), c.cacheFetches.Load()
+}
+
+// cachePath returns where the bare clone of cloneURL is cached:
+// <cacheDir>/<host>/<path>.git (see repoPath). The Cloner only sees clone
+// URLs, so the host stands in for the provider and the full path for the
+// slug, which alone isn't unique across owners.
+func (c *Cloner) cachePath(cloneURL string) string {
+	host, segs := repoPath(cloneURL)
+	segs[len(segs)-1] += ".git"
+	return filepath.Join(append([]string{c.cacheDir, host}, segs...)...)
+}
+
+// cacheRemote is the remote of a cached bare clone. Its refspec mirrors the
+// remote's branches into refs/heads.
+const cacheRemote = "origin"
+
+// updateCache clones cloneURL into its cache directory, or fetches into the
+// existing bare clone, and points the cache's HEAD at the remote's default
+// branch, which it returns. The caller holds the cache path's lock.
+func (c *Cloner) updateCache(ctx context.Context, cloneURL string) (*git.Repository, plumbing.ReferenceName, error) {
+	path := c.cachePath(cloneURL)
+	repo, err := git.PlainOpen(path)
+	fresh := errors.Is(err, git.ErrRepositoryNotExists)
+	switch {
+	case fresh:
+		if repo, err = git.PlainInit(path, true); err != nil {
+			return nil, "", fmt.Errorf("create clone cache: %w", err)
+		}
+		_, err = repo.CreateRemote(&config.RemoteConfig{
+			Name:  cacheRemote,
+			URLs:  []string{cloneURL},
+			Fetch: []config.RefSpec{"+refs/heads/*:refs/heads/*"},
+		})
+		if err != nil {
+			os.RemoveAll(path)
+			return nil, "", fmt.Errorf("create clone cache: %w", err)
+		}
+	case err != nil:
+		return nil, "", fmt.Errorf("open clone cache: %w", err)
+	}
+
+	remote, err := repo.Remote(cacheRemote)
+	if err != nil {
+		return nil, "", fmt.Errorf("open clone cache: %w", err)
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: c.auth()})
+	if err != nil {
+		if fresh {
+			os.RemoveAll(path)
+		}
+		return nil, "", fmt.Errorf("git clone: %w", err)
+	}
+	branch := defaultBranch(refs)
+	if branch == "" {
+		if fresh {
+			os.RemoveAll(path)
+		}
+		return nil, "", fmt.Errorf("git clone: remote has no default branch")
+	}
+
+	err = remote.FetchContext(ctx, &git.FetchOptions{Auth: c.auth(), Tags: git.NoTags, Force: true})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if fresh {
+			os.RemoveAll(path)
+		}
+		return nil, "", fmt.Errorf("git fetch: %w", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return nil, "", fmt.Errorf("update clone cache: %w", err)
+	}
+
+	if fresh {
+		c.cacheClones.Add(1)
+	} else {
+		c.cacheFetches.Add(1)
+	}
+	return repo, branch, nil
+}
+
+// defaultBranch returns the branch the advertised HEAD points at, or ""
+// when there is none. Without a symbolic HEAD it picks the branch at HEAD's
+// commit.
+func defaultBranch(refs []*plumbing.Reference) plumbing.ReferenceName {
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
+		}
+	}
+	if head == nil {
+		return ""
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target()
+	}
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref.Name()
+		}
+	}
+	return ""
+}
+
+// checkoutCached updates the cached clone of cloneURL and checks its default
+// branch out into a working directory, like a git worktree. The checkout has
+// its own .git with HEAD, index, config and refs (the cache's branches as
+// refs/remotes/origin/*), reading objects from the cache (see
+// checkoutStorage), so later checkouts and fetches on the returned
+// repository never touch the cache. The cleanup function only removes the
+// working directory.
+func (c *Cloner) checkoutCached(ctx context.Context, cloneURL string) (*git.Repository, string, func(), error) {
+	path := c.cachePath(cloneURL)
+
+	// Fetches into one cache are serialized; checkouts only read it.
+	lock, _ := c.cacheLocks.LoadOrStore(path, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	cached, branch, err := c.updateCache(ctx, cloneURL)
+	var heads []*plumbing.Reference
+	if err == nil {
+		heads, err = branchRefs(cached)
+	}
+	lock.(*sync.Mutex).Unlock()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	dir, cleanupFn, err := c.workDir(cloneURL)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	repo, err := initCheckout(path, dir, cloneURL, branch, heads)
+	if err != nil {
+		cleanupFn()
+		return nil, "", nil, fmt.Errorf("open clone cache: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		cleanupFn()
+		return nil, "", nil, fmt.Errorf("get worktree: %w", err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: branch, Force: true}); err != nil {
+		cleanupFn()
+		return nil, "", nil, fmt.Errorf("checkout %s: %w", branch.Short(), err)
+	}
+	return repo, dir, cleanupFn, nil
+}
+
+// branchRefs returns the branches of a cached bare clone.
+func branchRefs(repo *git.Repository) ([]*plumbing.Reference, error) {
+	iter, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("list cached branches: %w", err)
+	}
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list cached branches: %w", err)
+	}
+	return refs, nil
+}
+
+// initCheckout creates the repository of a clone cache checkout in dir: an
+// "origin" remote for cloneURL with heads as its remote-tracking branches,
+// and HEAD on branch. Its objects/info/alternates points at the cache so the
+// git CLI can read kept checkouts too.
+func initCheckout(cachePath, dir, cloneURL string, branch plumbing.ReferenceName, heads []*plumbing.Reference) (*git.Repository, error) {
+	absCache, err := filepath.Abs(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	dotGit := filepath.Join(dir, git.GitDirName)
+	infoDir := filepath.Join(dotGit, "objects", "info")
+	if err := os.MkdirAll(infoDir, 0o755); err != nil {
+		return nil, err
+	}
+	alternates := filepath.Join(absCache, "objects") + "\n"
+	if err := os.WriteFile(filepath.Join(infoDir, "alternates"), []byte(alternates), 0o644); err != nil {
+		return nil, err
+	}
+
+	st := &checkoutStorage{
+		Storage: filesystem.NewStorage(osfs.New(dotGit), cache.NewObjectLRUDefault()),
+		shared:  filesystem.NewStorage(osfs.New(absCache), cache.NewObjectLRUDefault()),
+	}
+	repo, err := git.Init(st, osfs.New(dir))
+	if err != nil {
+		return nil, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name:  cacheRemote,
+		URLs:  []string{cloneURL},
+		Fetch: []config.RefSpec{"+refs/heads/*:refs/remotes/" + cacheRemote + "/*"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range heads {
+		if err := st.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName(cacheRemote, ref.Name().Short()), ref.Hash())); err != nil {
+			return nil, err
+		}
+		if ref.Name() == branch {
+			if err := st.SetReference(plumbing.NewHashReference(branch, ref.Hash())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := st.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// checkoutStorage is the storage of a clone cache checkout: everything but
+// objects lives in the checkout's own .git. Objects are read from the cache
+// first, then from .git, where later fetches (tags, a fork's parent) write
+// theirs. go-git only asks for self-contained packs, so objects written to
+// .git never depend on the cache's.
+type checkoutStorage struct {
+	*filesystem.Storage
+	shared *filesystem.Storage
+}
+
+func (s *checkoutStorage) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := s.shared.EncodedObject(t, h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.Storage.EncodedObject(t, h)
+	}
+	return obj, err
+}
+
+func (s *checkoutStorage) HasEncodedObject(h plumbing.Hash) error {
+	if err := s.shared.HasEncodedObject(h); !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	return s.Storage.HasEncodedObject(h)
+}
+
+func (s *checkoutStorage) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	size, err := s.shared.EncodedObjectSize(h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return s.Storage.EncodedObjectSize(h)
+	}
+	return size, err
+}
+
+func (s *checkoutStorage) IterEncodedObjects(t plumbing.ObjectType) (storer.EncodedObjectIter, error) {
+	shared, err := s.shared.IterEncodedObjects(t)
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.Storage.IterEncodedObjects(t)
+	if err != nil {
+		shared.Close()
+		return nil, err
+	}
+	return storer.NewMultiEncodedObjectIter([]storer.EncodedObjectIter{shared, local}), nil
+}
+
+// upstreamRemote is the remote FetchParent adds for a fork's parent.
+const upstreamRemote = "upstream"
+
+// FetchParent fetches the branches of a fork's parent repository at
+// parentURL into refs/remotes/upstream of repo, which must be a full clone.
+// It returns the upstream ref to compare the fork against: the parent branch
+// named like the fork's checked-out branch, else upstream main or master.
+func (c *Cloner) FetchParent(ctx context.Context, repo *git.Repository, parentURL string) (string, error) {
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name:  upstreamRemote,
+		URLs:  []string{parentURL},
+		Fetch: []config.RefSpec{"+refs/heads/*:refs/remotes/" + upstreamRemote + "/*"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("add parent remote: %w", err)
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{Auth: c.auth(), Tags: git.NoTags})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return "", fmt.Errorf("git fetch parent: %w", err)
+	}
+
+	var candidates []string
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		candidates = append(candidates, head.Name().Short())
+	}
+	candidates = append(candidates, "main", "master")
+	for _, branch := range candidates {
+		ref := plumbing.NewRemoteReferenceName(upstreamRemote, branch)
+		if _, err := repo.Reference(ref, true); err == nil {
+			return ref.String(), nil
+		}
+	}
+	return "", fmt.Errorf("parent %s has no branch matching %s", parentURL, strings.Join(candidates, ", "))
+}
+
 // Checkout checks out the given commit hash in the repository worktree,
 // forcefully replacing any existing working tree contents.
 func Checkout(repo *git.Repository, dir string, hash plumbing.Hash) error {
@@ -129,17 +672,67 @@ func Checkout(repo *git.Repository, dir string, hash plumbing.Hash) error {
 	return nil
 }
 
+// ChangedFiles returns the paths (slash-separated, relative to the repo root)
+// of files added or modified on HEAD since ref. The comparison starts from
+// the merge base of HEAD and ref, like "git diff ref...HEAD". ref may be a
+// commit hash, a local branch, or a remote branch name such as "main" (looked
+// up under refs/remotes/origin). Deleted files are not included.
+func ChangedFiles(repo *git.Repository, ref string) ([]string, error) {
+	headRef, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolve HEAD: %w", err)
+	}
+	head, err := repo.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("load HEAD commit: %w", err)
+	}
+
+	baseHash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		baseHash, err = repo.ResolveRevision(plumbing.Revision("refs/remotes/origin/" + ref))
+		if err != nil {
+			return nil, fmt.Errorf("resolve ref %q: %w", ref, err)
+		}
+	}
+	base, err := repo.CommitObject(*baseHash)
+	if err != nil {
+		return nil, fmt.Errorf("load commit for %q: %w", ref, err)
+	}
+
+	if bases, err := base.MergeBase(head); err == nil && len(bases) > 0 {
+		base = bases[0]
+	}
+
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("load tree for %q: %w", ref, err)
+	}
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("load HEAD tree: %w", err)
+	}
+
+	changes, err := baseTree.Diff(headTree)
+	if err != nil {
+		return nil, fmt.Errorf("diff %s..HEAD: %w", ref, err)
+	}
+
+	var files []string
+	for _, ch := range changes {
+		if ch.To.Name != "" {
+			files = append(files, ch.To.Name)
+		}
+	}
+	return files, nil
+}
+
 // Download fetches a tarball from downloadURL, extracts it to a temporary
 // directory, and returns the path. This is used when git clone is not
 // available (e.g. Bitbucket scoped API tokens).
 func (c *Cloner) Download(ctx context.Context, downloadURL string) (dir string, cleanup func(), err error) {
-	tmpDir, err := os.MkdirTemp("", "codemium-*")
+	tmpDir, cleanupFn, err := c.workDir(downloadURL)
 	if err != nil {
-		return "", nil, fmt.Errorf("create temp dir: %w", err)
-	}
-
-	cleanupFn := func() {
-		os.RemoveAll(tmpDir)
+		return "", nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
@@ -3,12 +3,22 @@ package analyzer_test
 
 import (
 	"context"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 
 	"github.com/dsablic/codemium/internal/analyzer"
@@ -111,3 +121,532 @@ func TestCheckout(t *testing.T) {
 		t.Errorf("b.txt should not exist after checkout to commit1, got err: %v", err)
 	}
 }
+
+func TestHeadSHA(t *testing.T) {
+	srcDir := t.TempDir()
+	repo, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("plain init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	if _, err := wt.Add("main.go"); err != nil {
+		t.Fatalf("add main.go: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	hash, err := wt.Commit("init", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	cloner := analyzer.NewCloner("", "")
+	sha, err := cloner.HeadSHA(context.Background(), srcDir, "")
+	if err != nil {
+		t.Fatalf("HeadSHA: %v", err)
+	}
+	if sha != hash.String() {
+		t.Errorf("expected %s, got %s", hash, sha)
+	}
+	if sha, err := cloner.HeadSHA(context.Background(), srcDir, "master"); err != nil || sha != hash.String() {
+		t.Errorf("expected master at %s, got %s (%v)", hash, sha, err)
+	}
+	if _, err := cloner.HeadSHA(context.Background(), srcDir, "missing"); err == nil {
+		t.Error("expected an error for a missing branch")
+	}
+}
+
+func TestCloneKeepDir(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "myrepo")
+	repo, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("plain init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	if _, err := wt.Add("main.go"); err != nil {
+		t.Fatalf("add main.go: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("init", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	keepDir := t.TempDir()
+	cloner := analyzer.NewCloner("", "", analyzer.WithKeepDir(keepDir))
+
+	dir, cleanup, err := cloner.Clone(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("clone failed: %v", err)
+	}
+
+	want := filepath.Join(keepDir, "local", srcDir)
+	if dir != want {
+		t.Errorf("expected clone in %s, got %s", want, dir)
+	}
+
+	// A second clone while the first is in use must not wipe it.
+	dir2, cleanup2, err := cloner.Clone(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("second clone failed: %v", err)
+	}
+	if dir2 != want+"-2" {
+		t.Errorf("expected second clone in %s, got %s", want+"-2", dir2)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected first clone to survive the second: %v", err)
+	}
+	cleanup2()
+	cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected kept clone to survive cleanup: %v", err)
+	}
+
+	// Released directories are reused.
+	dir3, cleanup3, err := cloner.Clone(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("third clone failed: %v", err)
+	}
+	cleanup3()
+	if dir3 != want {
+		t.Errorf("expected released dir %s to be reused, got %s", want, dir3)
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		path string
+	}{
+		{"https://github.com/org-a/api.git", "github.com", "org-a/api"},
+		{"https://github.com/org-b/api.git", "github.com", "org-b/api"},
+		{"https://gitlab.com/grp/sub/api.git", "gitlab.com", "grp/sub/api"},
+		{"https://dev.azure.com/org/proj/_git/api", "dev.azure.com", "org/proj/_git/api"},
+		{"https://bitbucket.org/ws/api/get/main.tar.gz", "bitbucket.org", "ws/api"},
+		{"/srv/repos/api/", "local", "srv/repos/api"},
+		{"https://example.com/", "example.com", "repo"},
+	}
+	for _, tt := range tests {
+		host, segs := analyzer.RepoPath(tt.url)
+		if host != tt.host || strings.Join(segs, "/") != tt.path {
+			t.Errorf("RepoPath(%q) = %q, %q; want %q, %q", tt.url, host, strings.Join(segs, "/"), tt.host, tt.path)
+		}
+	}
+}
+
+func TestCloneCacheDir(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "myrepo")
+	repo, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("plain init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	commit := func(name, msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte("package main\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+		if _, err := wt.Commit(msg, &git.CommitOptions{Author: sig}); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+	commit("main.go", "init")
+
+	cacheDir := t.TempDir()
+	cloner := analyzer.NewCloner("", "", analyzer.WithCacheDir(cacheDir))
+
+	dir, cleanup, err := cloner.Clone(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("first clone failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected main.go in first checkout: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected cleanup to remove the checkout, got %v", err)
+	}
+
+	commit("util.go", "add util")
+
+	repo2, dir2, cleanup2, err := cloner.CloneFull(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("second clone failed: %v", err)
+	}
+	defer cleanup2()
+	for _, name := range []string{"main.go", "util.go"} {
+		if _, err := os.Stat(filepath.Join(dir2, name)); err != nil {
+			t.Errorf("expected %s in second checkout: %v", name, err)
+		}
+	}
+	head, err := repo2.Head()
+	if err != nil {
+		t.Fatalf("head: %v", err)
+	}
+	srcHead, _ := repo.Head()
+	if head.Hash() != srcHead.Hash() {
+		t.Errorf("expected HEAD %s, got %s", srcHead.Hash(), head.Hash())
+	}
+
+	clones, fetches := cloner.CacheStats()
+	if clones != 1 || fetches != 1 {
+		t.Errorf("expected 1 clone and 1 fetch, got %d clones and %d fetches", clones, fetches)
+	}
+	cachePath := filepath.Join(cacheDir, "local", srcDir+".git")
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Errorf("expected the bare clone to stay in the cache: %v", err)
+	}
+
+	// Checkouts and fetches on a checkout stay out of the shared cache.
+	commits, err := repo2.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	var history []plumbing.Hash
+	commits.ForEach(func(c *object.Commit) error {
+		history = append(history, c.Hash)
+		return nil
+	})
+	if len(history) != 2 {
+		t.Fatalf("expected 2 commits in the checkout's history, got %d", len(history))
+	}
+	if err := analyzer.Checkout(repo2, dir2, history[1]); err != nil {
+		t.Fatalf("checkout first commit: %v", err)
+	}
+	if _, err := cloner.FetchParent(context.Background(), repo2, srcDir); err != nil {
+		t.Fatalf("fetch parent: %v", err)
+	}
+	if _, err := repo2.Reference(plumbing.NewRemoteReferenceName("origin", "master"), true); err != nil {
+		t.Errorf("expected origin/master in the checkout: %v", err)
+	}
+	cached, err := git.PlainOpen(cachePath)
+	if err != nil {
+		t.Fatalf("open cache: %v", err)
+	}
+	if _, err := cached.Remote("upstream"); err == nil {
+		t.Error("expected FetchParent to leave the cache's remotes alone")
+	}
+	if cachedHead, err := cached.Head(); err != nil || cachedHead.Hash() != srcHead.Hash() {
+		t.Errorf("expected the cache's HEAD to stay at %s, got %v (%v)", srcHead.Hash(), cachedHead, err)
+	}
+}
+
+// gitHTTPServer serves the repositories under root over git's smart HTTP
+// protocol through git http-backend, answering the first failures requests
+// with status instead. It returns the server and a request counter.
+func gitHTTPServer(t *testing.T, root string, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git binary not available")
+	}
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(requests.Add(1)) <= failures {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestCloneRetriesTransientFailures(t *testing.T) {
+	defer analyzer.SetCloneRetryBaseDelay(time.Millisecond)()
+
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "myrepo")
+	repo, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("plain init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	if _, err := wt.Add("main.go"); err != nil {
+		t.Fatalf("add main.go: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	if _, err := wt.Commit("init", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	srv, requests := gitHTTPServer(t, root, 2, http.StatusServiceUnavailable)
+	dir, cleanup, err := analyzer.NewCloner("", "").Clone(context.Background(), srv.URL+"/myrepo/.git")
+	if err != nil {
+		t.Fatalf("expected the clone to succeed on the third try: %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected main.go in the clone: %v", err)
+	}
+	if n := requests.Load(); n < 3 {
+		t.Errorf("expected two failed requests before the clone, got %d requests", n)
+	}
+
+	// Out of retries.
+	srv, _ = gitHTTPServer(t, root, 2, http.StatusBadGateway)
+	_, _, err = analyzer.NewCloner("", "", analyzer.WithCloneRetries(1)).Clone(context.Background(), srv.URL+"/myrepo/.git")
+	if err == nil {
+		t.Error("expected the clone to fail with one retry")
+	}
+
+	// Fatal errors are not retried.
+	srv, requests = gitHTTPServer(t, root, 10, http.StatusNotFound)
+	_, _, _, err = analyzer.NewCloner("", "").CloneFull(context.Background(), srv.URL+"/myrepo/.git")
+	if err == nil {
+		t.Fatal("expected the clone of a missing repository to fail")
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected a missing repository to fail without retrying, got %d requests", n)
+	}
+}
+
+func TestCloneWithSubmodules(t *testing.T) {
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+
+	// Library repo that will be referenced as a submodule
+	libDir := filepath.Join(t.TempDir(), "lib")
+	libRepo, err := git.PlainInit(libDir, false)
+	if err != nil {
+		t.Fatalf("init lib: %v", err)
+	}
+	libWt, _ := libRepo.Worktree()
+	if err := os.WriteFile(filepath.Join(libDir, "lib.go"), []byte("package lib\n"), 0o644); err != nil {
+		t.Fatalf("write lib.go: %v", err)
+	}
+	libWt.Add("lib.go")
+	libHash, err := libWt.Commit("init lib", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatalf("commit lib: %v", err)
+	}
+
+	// Superproject with .gitmodules and a gitlink entry for lib
+	superDir := filepath.Join(t.TempDir(), "super")
+	superRepo, err := git.PlainInit(superDir, false)
+	if err != nil {
+		t.Fatalf("init super: %v", err)
+	}
+	superWt, _ := superRepo.Worktree()
+	gitmodules := "[submodule \"lib\"]\n\tpath = lib\n\turl = " + libDir + "\n"
+	if err := os.WriteFile(filepath.Join(superDir, ".gitmodules"), []byte(gitmodules), 0o644); err != nil {
+		t.Fatalf("write .gitmodules: %v", err)
+	}
+	superWt.Add(".gitmodules")
+	idx, err := superRepo.Storer.Index()
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	entry := idx.Add("lib")
+	entry.Hash = libHash
+	entry.Mode = filemode.Submodule
+	if err := superRepo.Storer.SetIndex(idx); err != nil {
+		t.Fatalf("set index: %v", err)
+	}
+	if _, err := superWt.Commit("add submodule", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit super: %v", err)
+	}
+
+	plain, cleanup, err := analyzer.NewCloner("", "").Clone(context.Background(), superDir)
+	if err != nil {
+		t.Fatalf("clone without submodules: %v", err)
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(plain, "lib", "lib.go")); !os.IsNotExist(err) {
+		t.Errorf("expected submodule to be absent by default, got err: %v", err)
+	}
+
+	withSubs, cleanup2, err := analyzer.NewCloner("", "", analyzer.WithSubmodules()).Clone(context.Background(), superDir)
+	if err != nil {
+		t.Fatalf("clone with submodules: %v", err)
+	}
+	defer cleanup2()
+	if _, err := os.Stat(filepath.Join(withSubs, "lib", "lib.go")); err != nil {
+		t.Errorf("expected submodule file to be checked out: %v", err)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, _ := repo.Worktree()
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+
+	for _, name := range []string{"a.go", "b.go", "same.go"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("package x\n"), 0o644)
+		wt.Add(name)
+	}
+	base, err := wt.Commit("base", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatalf("commit base: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/base", base)); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package x\n\nvar A = 1\n"), 0o644)
+	wt.Add("a.go")
+	wt.Remove("b.go")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sub", "c.go"), []byte("package sub\n"), 0o644)
+	wt.Add("sub/c.go")
+	if _, err := wt.Commit("change", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit change: %v", err)
+	}
+
+	for _, ref := range []string{"base", base.String()} {
+		files, err := analyzer.ChangedFiles(repo, ref)
+		if err != nil {
+			t.Fatalf("ChangedFiles(%s): %v", ref, err)
+		}
+		sort.Strings(files)
+		want := []string{"a.go", "sub/c.go"}
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("ChangedFiles(%s) = %v, want %v", ref, files, want)
+		}
+	}
+
+	if _, err := analyzer.ChangedFiles(repo, "no-such-ref"); err == nil {
+		t.Error("expected error for unknown ref")
+	}
+}
+
+func TestFetchParent(t *testing.T) {
+	parentDir := t.TempDir()
+	parent, err := git.PlainInit(parentDir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	parentWT, _ := parent.Worktree()
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	os.WriteFile(filepath.Join(parentDir, "upstream.go"), []byte("package x\n"), 0o644)
+	parentWT.Add("upstream.go")
+	if _, err := parentWT.Commit("upstream", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit parent: %v", err)
+	}
+
+	cloner := analyzer.NewCloner("", "")
+	fork, forkDir, cleanup, err := cloner.CloneFull(context.Background(), parentDir)
+	if err != nil {
+		t.Fatalf("clone fork: %v", err)
+	}
+	defer cleanup()
+	forkWT, _ := fork.Worktree()
+	os.WriteFile(filepath.Join(forkDir, "fork.go"), []byte("package x\n"), 0o644)
+	forkWT.Add("fork.go")
+	if _, err := forkWT.Commit("fork change", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit fork: %v", err)
+	}
+
+	// Upstream moving on after the fork must not count as fork divergence
+	os.WriteFile(filepath.Join(parentDir, "later.go"), []byte("package x\n"), 0o644)
+	parentWT.Add("later.go")
+	if _, err := parentWT.Commit("later", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatalf("commit parent: %v", err)
+	}
+
+	ref, err := cloner.FetchParent(context.Background(), fork, parentDir)
+	if err != nil {
+		t.Fatalf("FetchParent: %v", err)
+	}
+	if ref != "refs/remotes/upstream/master" {
+		t.Errorf("expected upstream master ref, got %s", ref)
+	}
+
+	files, err := analyzer.ChangedFiles(fork, ref)
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if !reflect.DeepEqual(files, []string{"fork.go"}) {
+		t.Errorf("expected only fork.go, got %v", files)
+	}
+}
+
+func TestLatestReleaseTag(t *testing.T) {
+	srcDir := t.TempDir()
+	src, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	wt, _ := src.Worktree()
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	commit := func(name string) plumbing.Hash {
+		os.WriteFile(filepath.Join(srcDir, name), []byte("package x\n"), 0o644)
+		wt.Add(name)
+		h, err := wt.Commit(name, &git.CommitOptions{Author: sig})
+		if err != nil {
+			t.Fatalf("commit %s: %v", name, err)
+		}
+		return h
+	}
+
+	c1 := commit("a.go")
+	src.CreateTag("v1.2.0", c1, nil)
+	c2 := commit("b.go")
+	// Annotated, and numerically (not lexically) above v1.2.0
+	if _, err := src.CreateTag("v1.10.0", c2, &git.CreateTagOptions{Tagger: sig, Message: "release"}); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	c3 := commit("c.go")
+	src.CreateTag("v2.0.0-rc.1", c3, nil)
+	src.CreateTag("nightly", c3, nil)
+
+	cloner := analyzer.NewCloner("", "")
+	repo, dir, cleanup, err := cloner.CloneFull(context.Background(), srcDir)
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	defer cleanup()
+
+	if _, _, ok, err := analyzer.LatestReleaseTag(repo); err != nil || ok {
+		t.Fatalf("expected no tags before FetchTags, got ok=%v err=%v", ok, err)
+	}
+	if err := cloner.FetchTags(context.Background(), repo); err != nil {
+		t.Fatalf("FetchTags: %v", err)
+	}
+	name, hash, ok, err := analyzer.LatestReleaseTag(repo)
+	if err != nil || !ok {
+		t.Fatalf("LatestReleaseTag: ok=%v err=%v", ok, err)
+	}
+	if name != "v1.10.0" || hash != c2 {
+		t.Errorf("expected v1.10.0 at %s, got %s at %s", c2, name, hash)
+	}
+
+	if err := analyzer.Checkout(repo, dir, hash); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.go")); !os.IsNotExist(err) {
+		t.Error("c.go is newer than the release tag and should not be checked out")
+	}
+}
@@ -0,0 +1,41 @@
+// internal/analyzer/encoding.go
+package analyzer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// toUTF8 transcodes content that starts with a UTF-16 byte order mark to
+// UTF-8 (dropping the BOM) so scc counts its lines instead of treating the
+// NUL bytes as binary. Any other content is returned unchanged.
+func toUTF8(content []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(content, bomUTF16LE):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(content, bomUTF16BE):
+		order = binary.BigEndian
+	default:
+		return content
+	}
+
+	body := content[2:]
+	units := make([]uint16, len(body)/2)
+	for i := range units {
+		units[i] = order.Uint16(body[2*i:])
+	}
+
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
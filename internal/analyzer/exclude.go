@@ -0,0 +1,57 @@
+// internal/analyzer/exclude.go
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValidateExcludePath checks that pattern is usable with WithExcludePaths:
+// non-empty, with every slash-separated segment a valid path.Match pattern.
+func ValidateExcludePath(pattern string) error {
+	p := strings.Trim(strings.TrimSpace(pattern), "/")
+	if p == "" {
+		return fmt.Errorf("invalid exclude path %q", pattern)
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid exclude path %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchExcludePath reports whether the slash-separated relPath matches
+// pattern. A pattern without a slash matches the file name at any depth
+// ("*.min.js"); otherwise it is matched from the repository root segment by
+// segment, "**" standing for any number of directories ("docs/**",
+// "**/*_test.go").
+func matchExcludePath(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(relPath))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
@@ -0,0 +1,15 @@
+// internal/analyzer/export_test.go
+package analyzer
+
+import "time"
+
+// SetCloneRetryBaseDelay shortens the clone retry backoff for tests and
+// returns a function restoring the previous value.
+func SetCloneRetryBaseDelay(d time.Duration) func() {
+	old := cloneRetryBaseDelay
+	cloneRetryBaseDelay = d
+	return func() { cloneRetryBaseDelay = old }
+}
+
+// RepoPath exposes repoPath to tests.
+var RepoPath = repoPath
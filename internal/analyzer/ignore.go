@@ -0,0 +1,57 @@
+// internal/analyzer/ignore.go
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFile is an optional gitignore-syntax file at the repository root
+// listing paths the analyzer skips in addition to the .gitignore files.
+const IgnoreFile = ".codemiumignore"
+
+// ignoreRules collects gitignore patterns as the walk enters directories.
+// Patterns are kept in ascending priority: the root .gitignore, then
+// IgnoreFile, then nested .gitignore files in walk order, each scoped to its
+// own directory. A nil *ignoreRules ignores nothing.
+type ignoreRules struct {
+	patterns []gitignore.Pattern
+}
+
+// load adds the patterns of the file name in dir, whose slash-separated path
+// relative to the repository root is relDir ("." for the root). A missing or
+// unreadable file adds nothing.
+func (r *ignoreRules) load(dir, relDir, name string) {
+	if r == nil {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(relDir), name))
+	if err != nil {
+		return
+	}
+	var domain []string
+	if relDir != "." {
+		domain = strings.Split(relDir, "/")
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		r.patterns = append(r.patterns, gitignore.ParsePattern(line, domain))
+	}
+}
+
+// ignores reports whether the slash-separated relPath is ignored.
+func (r *ignoreRules) ignores(relPath string, isDir bool) bool {
+	if r == nil || len(r.patterns) == 0 {
+		return false
+	}
+	return gitignore.NewMatcher(r.patterns).Match(strings.Split(relPath, "/"), isDir)
+}
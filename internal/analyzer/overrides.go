@@ -0,0 +1,106 @@
+// internal/analyzer/overrides.go
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boyter/scc/v3/processor"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// LanguageOverrides maps lowercased file extensions (".tsx") and file names
+// ("jenkinsfile") to scc language names, taking precedence over scc's own
+// detection.
+type LanguageOverrides map[string]string
+
+// LoadLanguageOverrides reads a language override file.
+func LoadLanguageOverrides(path string) (LanguageOverrides, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open language overrides: %w", err)
+	}
+	defer f.Close()
+	return ParseLanguageOverrides(f)
+}
+
+// ParseLanguageOverrides parses "pattern = Language" lines from r, where
+// pattern is an extension starting with a dot or an exact file name. Blank
+// lines and '#' comments are ignored. Languages are matched
+// case-insensitively against the languages scc knows; unknown ones are an
+// error.
+func ParseLanguageOverrides(r io.Reader) (LanguageOverrides, error) {
+	known := sccLanguages()
+	o := LanguageOverrides{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		pattern, lang, ok := strings.Cut(line, "=")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		lang = strings.TrimSpace(lang)
+		if !ok || pattern == "" || lang == "" {
+			return nil, fmt.Errorf("language overrides line %d: expected \"pattern = Language\"", lineNo)
+		}
+		canonical, ok := known[strings.ToLower(lang)]
+		if !ok {
+			return nil, fmt.Errorf("language overrides line %d: unknown language %q", lineNo, lang)
+		}
+		o[pattern] = canonical
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read language overrides: %w", err)
+	}
+	return o, nil
+}
+
+// lookup returns the override for a file name, matching the full name before
+// its extension, or "" if none applies.
+func (o LanguageOverrides) lookup(name string) string {
+	name = strings.ToLower(name)
+	if lang, ok := o[name]; ok {
+		return lang
+	}
+	if ext := filepath.Ext(name); ext != "" {
+		return o[ext]
+	}
+	return ""
+}
+
+// ValidateLanguage checks that name is one of scc's languages or
+// model.DocumentationLanguage, ignoring case, and returns its canonical
+// spelling.
+func ValidateLanguage(name string) (string, error) {
+	known := sccLanguages()
+	known[strings.ToLower(model.DocumentationLanguage)] = model.DocumentationLanguage
+	canonical, ok := known[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown language %q", name)
+	}
+	return canonical, nil
+}
+
+// sccLanguages returns scc's language names keyed by their lowercased form.
+func sccLanguages() map[string]string {
+	initOnce.Do(processor.ProcessConstants)
+	processor.LanguageFeaturesMutex.Lock()
+	defer processor.LanguageFeaturesMutex.Unlock()
+	known := make(map[string]string, len(processor.LanguageFeatures))
+	for name := range processor.LanguageFeatures {
+		known[strings.ToLower(name)] = name
+	}
+	return known
+}
@@ -0,0 +1,97 @@
+// internal/analyzer/repoconfig.go
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// RepoConfigFile is the name of the per-repository config file the analyzer
+// reads from the root of the analyzed directory.
+const RepoConfigFile = ".codemium.yaml"
+
+// RepoConfig lets a repository's owners adjust how it is counted. Its
+// settings add to the CLI options rather than replacing them.
+type RepoConfig struct {
+	// Area is a free-form label (team, domain) copied to RepoStats.Area.
+	Area string `yaml:"area"`
+	// ExcludePaths are slash-separated paths or path.Match globs, relative
+	// to the repository root. A matching directory excludes everything in it.
+	ExcludePaths []string `yaml:"exclude_paths"`
+	// IgnoreLanguages are language names (case-insensitive) left out of the
+	// counts.
+	IgnoreLanguages []string `yaml:"ignore_languages"`
+}
+
+// LoadRepoConfig reads RepoConfigFile from dir. It returns nil when the file
+// doesn't exist. Unknown keys, invalid exclude patterns and unknown languages
+// are errors.
+func LoadRepoConfig(dir string) (*RepoConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, RepoConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", RepoConfigFile, err)
+	}
+
+	var cfg RepoConfig
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", RepoConfigFile, err)
+	}
+	for i, p := range cfg.ExcludePaths {
+		p = strings.Trim(strings.TrimSpace(p), "/")
+		if _, err := path.Match(p, ""); err != nil || p == "" {
+			return nil, fmt.Errorf("parse %s: invalid exclude path %q", RepoConfigFile, cfg.ExcludePaths[i])
+		}
+		cfg.ExcludePaths[i] = p
+	}
+	known := sccLanguages()
+	known[strings.ToLower(model.DocumentationLanguage)] = model.DocumentationLanguage
+	for i, lang := range cfg.IgnoreLanguages {
+		canonical, ok := known[strings.ToLower(strings.TrimSpace(lang))]
+		if !ok {
+			return nil, fmt.Errorf("parse %s: unknown language %q", RepoConfigFile, lang)
+		}
+		cfg.IgnoreLanguages[i] = canonical
+	}
+	return &cfg, nil
+}
+
+// excludes reports whether the slash-separated relPath matches one of the
+// exclude paths, itself or through one of its parent directories.
+func (c *RepoConfig) excludes(relPath string) bool {
+	if c == nil {
+		return false
+	}
+	for _, p := range c.ExcludePaths {
+		for cur := relPath; cur != "." && cur != "/" && cur != ""; cur = path.Dir(cur) {
+			if ok, _ := path.Match(p, cur); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ignores reports whether lang is one of the ignored languages.
+func (c *RepoConfig) ignores(lang string) bool {
+	if c == nil {
+		return false
+	}
+	for _, l := range c.IgnoreLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
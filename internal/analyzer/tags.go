@@ -0,0 +1,103 @@
+// internal/analyzer/tags.go
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// FetchTags fetches all tags of repo's origin, which CloneFull leaves out.
+func (c *Cloner) FetchTags(ctx context.Context, repo *git.Repository) error {
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+		Auth:       c.auth(),
+		Tags:       git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("git fetch tags: %w", err)
+	}
+	return nil
+}
+
+// LatestReleaseTag returns the name and commit of the highest semantic
+// version release tag in repo, such as "v1.10.0" or "2.3.1". Pre-release
+// tags (v2.0.0-rc.1) and tags that aren't versions are ignored. ok is false
+// when the repository has no release tag.
+func LatestReleaseTag(repo *git.Repository) (name string, commit plumbing.Hash, ok bool, err error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", plumbing.ZeroHash, false, fmt.Errorf("list tags: %w", err)
+	}
+	defer tags.Close()
+
+	var best [3]int
+	var bestRef *plumbing.Reference
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		v, isRelease := parseReleaseVersion(ref.Name().Short())
+		if isRelease && (bestRef == nil || compareVersions(v, best) > 0) {
+			best, bestRef = v, ref
+		}
+		return nil
+	})
+	if err != nil {
+		return "", plumbing.ZeroHash, false, fmt.Errorf("list tags: %w", err)
+	}
+	if bestRef == nil {
+		return "", plumbing.ZeroHash, false, nil
+	}
+
+	// Annotated tags point at a tag object rather than the commit itself
+	hash := bestRef.Hash()
+	if tag, err := repo.TagObject(hash); err == nil {
+		c, err := tag.Commit()
+		if err != nil {
+			return "", plumbing.ZeroHash, false, fmt.Errorf("resolve tag %s: %w", bestRef.Name().Short(), err)
+		}
+		hash = c.Hash
+	}
+	return bestRef.Name().Short(), hash, true, nil
+}
+
+// parseReleaseVersion parses "MAJOR.MINOR.PATCH" with an optional "v"
+// prefix and "+build" suffix. Pre-releases are rejected.
+func parseReleaseVersion(tag string) ([3]int, bool) {
+	var v [3]int
+	s := strings.TrimPrefix(tag, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || p == "" || p[0] == '+' {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is lower than, equal to, or
+// higher than b.
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
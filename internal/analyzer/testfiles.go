@@ -0,0 +1,29 @@
+// internal/analyzer/testfiles.go
+package analyzer
+
+import (
+	"path"
+	"strings"
+
+	enry "github.com/go-enry/go-enry/v2"
+)
+
+var testDirs = map[string]bool{
+	"test": true, "tests": true, "__tests__": true, "spec": true, "testdata": true,
+}
+
+// IsTestFile reports whether a slash-separated path looks like test code:
+// enry's test file patterns (_test.go, *.spec.ts, *.test.js, ...), a test_
+// prefix, or any parent directory named test, tests, __tests__, spec or
+// testdata.
+func IsTestFile(p string) bool {
+	if enry.IsTest(p) || strings.HasPrefix(path.Base(p), "test_") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if testDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+// internal/analyzer/worktree.go
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// Worktrees writes commits of one repository into separate temporary
+// directories, so several commits can be analyzed at once where a single
+// checked-out worktree only holds one. A directory holds the files of the
+// commit's tree, without a .git directory. A go-git Repository isn't safe for
+// concurrent use, so each concurrent Add reads through its own handle on the
+// repository's on-disk storage; repositories that aren't on disk are read by
+// one Add at a time.
+type Worktrees struct {
+	repo *git.Repository
+	root string
+
+	mu   sync.Mutex
+	idle []*git.Repository // reopened handles not in use
+	// shared guards repo when its storage can't be reopened.
+	shared sync.Mutex
+}
+
+// NewWorktrees creates the temporary directory that holds repo's worktrees.
+// Cleanup removes it along with every worktree left in it.
+func NewWorktrees(repo *git.Repository) (*Worktrees, error) {
+	root, err := os.MkdirTemp("", "codemium-worktrees-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	return &Worktrees{repo: repo, root: root}, nil
+}
+
+// Add writes the tree of commit hash into a new directory and returns it.
+func (w *Worktrees) Add(hash plumbing.Hash) (string, error) {
+	dir, err := os.MkdirTemp(w.root, hash.String()[:12]+"-*")
+	if err != nil {
+		return "", fmt.Errorf("create worktree: %w", err)
+	}
+
+	repo, release := w.acquire()
+	defer release()
+	err = func() error {
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		return tree.Files().ForEach(func(f *object.File) error {
+			return writeTreeFile(dir, f)
+		})
+	}()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("checkout %s: %w", hash, err)
+	}
+	return dir, nil
+}
+
+// acquire returns a repository handle for the caller's exclusive use and the
+// function that gives it back: an idle or newly reopened handle, or else the
+// original repository once no other Add is reading it.
+func (w *Worktrees) acquire() (*git.Repository, func()) {
+	w.mu.Lock()
+	var repo *git.Repository
+	if n := len(w.idle); n > 0 {
+		repo, w.idle = w.idle[n-1], w.idle[:n-1]
+	}
+	w.mu.Unlock()
+	if repo == nil {
+		repo = reopen(w.repo)
+	}
+	if repo == nil {
+		w.shared.Lock()
+		return w.repo, w.shared.Unlock
+	}
+	return repo, func() {
+		w.mu.Lock()
+		w.idle = append(w.idle, repo)
+		w.mu.Unlock()
+	}
+}
+
+// reopen returns a new handle on the on-disk storage of repo, with its own
+// object cache and open files, or nil when repo isn't stored on disk.
+func reopen(repo *git.Repository) *git.Repository {
+	var st storage.Storer
+	switch s := repo.Storer.(type) {
+	case *filesystem.Storage:
+		st = filesystem.NewStorage(s.Filesystem(), cache.NewObjectLRUDefault())
+	case *checkoutStorage:
+		st = &checkoutStorage{
+			Storage: filesystem.NewStorage(s.Storage.Filesystem(), cache.NewObjectLRUDefault()),
+			shared:  filesystem.NewStorage(s.shared.Filesystem(), cache.NewObjectLRUDefault()),
+		}
+	default:
+		return nil
+	}
+	r, err := git.Open(st, nil)
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// Remove deletes a worktree returned by Add.
+func (w *Worktrees) Remove(dir string) {
+	os.RemoveAll(dir)
+}
+
+// Cleanup removes all worktrees.
+func (w *Worktrees) Cleanup() {
+	os.RemoveAll(w.root)
+}
+
+// writeTreeFile writes f under dir with its executable bit, or as a symlink.
+// Paths escaping dir are skipped.
+func writeTreeFile(dir string, f *object.File) error {
+	rel := filepath.FromSlash(f.Name)
+	if !filepath.IsLocal(rel) {
+		return nil
+	}
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	if f.Mode == filemode.Symlink {
+		target, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, path)
+	}
+
+	perm := os.FileMode(0o644)
+	if f.Mode == filemode.Executable {
+		perm = 0o755
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// AnalyzeCommits analyzes the tree of each commit in hashes, up to
+// concurrency commits at a time, each in its own Worktrees directory that is
+// removed once analyzed. Duplicate hashes are analyzed once. Commits that
+// fail to check out or analyze are left out of the result and reported,
+// one per commit, in the returned error; the other commits' stats are
+// returned alongside it.
+func (a *Analyzer) AnalyzeCommits(ctx context.Context, repo *git.Repository, hashes []plumbing.Hash, concurrency int) (map[plumbing.Hash]*model.RepoStats, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	worktrees, err := NewWorktrees(repo)
+	if err != nil {
+		return nil, err
+	}
+	defer worktrees.Cleanup()
+
+	var (
+		mu      sync.Mutex
+		results = make(map[plumbing.Hash]*model.RepoStats, len(hashes))
+		failed  []string
+		seen    = make(map[plumbing.Hash]bool, len(hashes))
+	)
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, hash := range hashes {
+		if ctx.Err() != nil {
+			break
+		}
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(hash plumbing.Hash) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stats, err := a.analyzeCommit(ctx, worktrees, hash)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed = append(failed, err.Error())
+				return
+			}
+			results[hash] = stats
+		}(hash)
+	}
+
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return results, fmt.Errorf("%d of %d commits failed: %s", len(failed), len(seen), strings.Join(failed, "; "))
+	}
+	return results, nil
+}
+
+// analyzeCommit analyzes the tree of one commit in a new worktree.
+func (a *Analyzer) analyzeCommit(ctx context.Context, worktrees *Worktrees, hash plumbing.Hash) (*model.RepoStats, error) {
+	dir, err := worktrees.Add(hash)
+	if err != nil {
+		return nil, err
+	}
+	defer worktrees.Remove(dir)
+
+	stats, err := a.Analyze(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("analyze %s: %w", hash, err)
+	}
+	return stats, nil
+}
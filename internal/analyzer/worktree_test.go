@@ -0,0 +1,102 @@
+// internal/analyzer/worktree_test.go
+package analyzer_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/dsablic/codemium/internal/analyzer"
+	"github.com/dsablic/codemium/internal/history"
+)
+
+func TestAnalyzeCommitsConcurrently(t *testing.T) {
+	srcDir := t.TempDir()
+	repo, err := git.PlainInit(srcDir, false)
+	if err != nil {
+		t.Fatalf("plain init: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	// One more Go file in each month from January to April 2025.
+	for month := 1; month <= 4; month++ {
+		name := fmt.Sprintf("file%d.go", month)
+		src := fmt.Sprintf("package main\n\nfunc f%d() {}\n", month)
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Date(2025, time.Month(month), 10, 12, 0, 0, 0, time.UTC)}
+		if _, err := wt.Commit("add "+name, &git.CommitOptions{Author: sig}); err != nil {
+			t.Fatalf("commit %s: %v", name, err)
+		}
+	}
+
+	// May has no new commit, so it shares April's.
+	dates := history.GenerateDates("2025-01", "2025-05", "monthly")
+	commits, err := history.FindCommits(repo, dates)
+	if err != nil {
+		t.Fatalf("FindCommits: %v", err)
+	}
+	var hashes []plumbing.Hash
+	for _, d := range dates {
+		hashes = append(hashes, commits[d])
+	}
+
+	// Worktrees go under TMPDIR; cleanup must leave nothing behind.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	results, err := analyzer.New().AnalyzeCommits(context.Background(), repo, hashes, 3)
+	if err != nil {
+		t.Fatalf("AnalyzeCommits: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 analyzed commits, got %d", len(results))
+	}
+	for i, d := range dates {
+		stats, ok := results[commits[d]]
+		if !ok {
+			t.Fatalf("period %d: no stats", i)
+		}
+		wantFiles := int64(min(i+1, 4))
+		if stats.Totals.Files != wantFiles {
+			t.Errorf("period %d: expected %d files, got %d", i, wantFiles, stats.Totals.Files)
+		}
+		if stats.Totals.Code != 2*wantFiles {
+			t.Errorf("period %d: expected %d code lines, got %d", i, 2*wantFiles, stats.Totals.Code)
+		}
+	}
+
+	// A commit that can't be checked out is reported, not dropped silently,
+	// and the others are still analyzed.
+	missing := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	results, err = analyzer.New().AnalyzeCommits(context.Background(), repo, append(hashes, missing), 2)
+	if err == nil || !strings.Contains(err.Error(), missing.String()) {
+		t.Errorf("expected an error naming %s, got %v", missing, err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected the 4 other commits analyzed, got %d", len(results))
+	}
+
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		t.Fatalf("read temp dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected all worktrees removed, found %d entries", len(entries))
+	}
+}
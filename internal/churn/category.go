@@ -0,0 +1,67 @@
+// internal/churn/category.go
+package churn
+
+import (
+	"path"
+	"strings"
+
+	enry "github.com/go-enry/go-enry/v2"
+
+	"github.com/dsablic/codemium/internal/analyzer"
+)
+
+// File categories used for ChurnStats.ByCategory.
+const (
+	CategoryCode   = "code"
+	CategoryTest   = "test"
+	CategoryDocs   = "docs"
+	CategoryConfig = "config"
+	CategoryOther  = "other"
+)
+
+// Categories lists the file categories in display order.
+var Categories = []string{CategoryCode, CategoryTest, CategoryDocs, CategoryConfig, CategoryOther}
+
+var docExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".rst": true, ".adoc": true, ".asciidoc": true, ".txt": true,
+}
+
+var configExtensions = map[string]bool{
+	".yaml": true, ".yml": true, ".json": true, ".toml": true, ".ini": true, ".cfg": true,
+	".conf": true, ".properties": true, ".env": true, ".xml": true, ".lock": true,
+	".mod": true, ".sum": true, ".gradle": true, ".tf": true, ".tfvars": true,
+}
+
+var configNames = map[string]bool{
+	"Dockerfile": true, "Makefile": true, "Jenkinsfile": true, "Procfile": true,
+	"Gemfile": true, "Pipfile": true, "CODEOWNERS": true,
+}
+
+// Classify buckets a file path into code, test, docs, config or other.
+// Tests are checked first (analyzer.IsTestFile) so test code doesn't count
+// as code, then docs and configuration by extension or well-known name;
+// anything else enry recognizes as a programming or markup language is code.
+func Classify(p string) string {
+	base := path.Base(p)
+	ext := strings.ToLower(path.Ext(base))
+
+	if analyzer.IsTestFile(p) {
+		return CategoryTest
+	}
+
+	if docExtensions[ext] || enry.IsDocumentation(p) {
+		return CategoryDocs
+	}
+
+	if configExtensions[ext] || configNames[base] || strings.HasPrefix(base, ".") {
+		return CategoryConfig
+	}
+
+	if lang, _ := enry.GetLanguageByExtension(base); lang != "" {
+		switch enry.GetLanguageType(lang) {
+		case enry.Programming, enry.Markup:
+			return CategoryCode
+		}
+	}
+	return CategoryOther
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"sort"
 	"sync"
+	"time"
 
+	"github.com/dsablic/codemium/internal/aidetect"
+	"github.com/dsablic/codemium/internal/health"
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/provider"
 )
@@ -12,14 +15,37 @@ import (
 const (
 	maxTopFiles      = 20
 	statsConcurrency = 10
+
+	maxCouplings       = 10
+	minCouplingSupport = 2
+
+	maxAuthors = 10
 )
 
-func Analyze(ctx context.Context, cl provider.ChurnLister, repo model.Repo, commitLimit int) (*model.ChurnStats, error) {
-	commits, err := cl.ListCommits(ctx, repo, commitLimit)
+// Analyze aggregates per-file churn over the last commitLimit commits, only
+// counting commits from since on unless since is zero. With ownership set,
+// each top file also gets its dominant author: the person (normalized through
+// authors, bots left out) behind most of its changes. AuthorChurn always
+// lists the authors (normalized the same way) who changed the most lines.
+//
+// When cl only implements provider.CommitLister, per-file stats can't be
+// fetched: the result counts the commits and sets FileStatsUnavailable, with
+// no top files, categories or owners.
+func Analyze(ctx context.Context, cl provider.CommitLister, repo model.Repo, commitLimit int, since time.Time, ownership bool, authors *health.AuthorMap) (*model.ChurnStats, error) {
+	commits, err := provider.ListCommitsInRange(ctx, cl, repo, since, time.Time{}, commitLimit)
 	if err != nil {
 		return nil, err
 	}
 
+	fl, ok := cl.(provider.ChurnLister)
+	if !ok {
+		return &model.ChurnStats{
+			TotalCommits:         int64(len(commits)),
+			TopFiles:             []model.FileChurn{},
+			FileStatsUnavailable: true,
+		}, nil
+	}
+
 	type commitFiles struct {
 		files []provider.FileChange
 		err   error
@@ -38,7 +64,7 @@ func Analyze(ctx context.Context, cl provider.ChurnLister, repo model.Repo, comm
 		go func(idx int, hash string) {
 			defer wg.Done()
 			defer func() { <-sem }()
-			files, err := cl.CommitFileStats(ctx, repo, hash)
+			files, err := fl.CommitFileStats(ctx, repo, hash)
 			results[idx] = commitFiles{files: files, err: err}
 		}(i, c.Hash)
 	}
@@ -48,30 +74,67 @@ func Analyze(ctx context.Context, cl provider.ChurnLister, repo model.Repo, comm
 		changes   int64
 		additions int64
 		deletions int64
+		byAuthor  map[string]int64
 	}
 	agg := map[string]*fileAgg{}
+	var lineStats bool // any file change with non-zero additions or deletions
 
-	for _, r := range results {
+	var commitFileLists [][]provider.FileChange
+	byAuthor := map[string]*model.AuthorChurn{}
+	for i, r := range results {
 		if r.err != nil {
 			continue
 		}
+		commitFileLists = append(commitFileLists, r.files)
+		committer := authors.Normalize(commits[i].Author)
+		ac, ok := byAuthor[committer]
+		if !ok {
+			ac = &model.AuthorChurn{Author: committer}
+			byAuthor[committer] = ac
+		}
+		ac.Commits++
+		var author string
+		if ownership && !aidetect.IsBotAuthor(commits[i].Author) {
+			author = committer
+		}
 		for _, f := range r.files {
+			ac.Additions += f.Additions
+			ac.Deletions += f.Deletions
 			a, ok := agg[f.Path]
 			if !ok {
-				a = &fileAgg{}
+				a = &fileAgg{byAuthor: map[string]int64{}}
 				agg[f.Path] = a
 			}
 			a.changes++
 			a.additions += f.Additions
 			a.deletions += f.Deletions
+			if f.Additions != 0 || f.Deletions != 0 {
+				lineStats = true
+			}
+			if author != "" {
+				a.byAuthor[author]++
+			}
 		}
 	}
 
-	var topFiles []model.FileChurn
+	// Non-nil so JSON shows an empty list, not null, when no file stats came back
+	topFiles := []model.FileChurn{}
+	byCategory := map[string]model.CategoryChurn{}
 	for path, a := range agg {
 		topFiles = append(topFiles, model.FileChurn{
 			Path: path, Changes: a.changes, Additions: a.additions, Deletions: a.deletions,
 		})
+
+		category := Classify(path)
+		c := byCategory[category]
+		c.Files++
+		c.Changes += a.changes
+		c.Additions += a.additions
+		c.Deletions += a.deletions
+		byCategory[category] = c
+	}
+	if len(byCategory) == 0 {
+		byCategory = nil
 	}
 
 	sort.Slice(topFiles, func(i, j int) bool {
@@ -82,12 +145,145 @@ func Analyze(ctx context.Context, cl provider.ChurnLister, repo model.Repo, comm
 		topFiles = topFiles[:maxTopFiles]
 	}
 
+	if ownership {
+		for i := range topFiles {
+			a := agg[topFiles[i].Path]
+			owner, n := dominantAuthor(a.byAuthor)
+			if owner == "" {
+				continue
+			}
+			topFiles[i].Owner = owner
+			topFiles[i].OwnerShare = float64(n) / float64(a.changes) * 100
+		}
+	}
+
+	couplings := ComputeCoupling(commitFileLists, minCouplingSupport)
+	if len(couplings) > maxCouplings {
+		couplings = couplings[:maxCouplings]
+	}
+
 	return &model.ChurnStats{
 		TotalCommits: int64(len(commits)),
 		TopFiles:     topFiles,
+		ByCategory:   byCategory,
+		Couplings:    couplings,
+		AuthorChurn:  topAuthors(byAuthor, maxAuthors),
+		// Like CommitStats, some providers report 0/0 per file for certain
+		// commits; if that's all we got, line counts are unknown, not zero.
+		StatsUnavailable: len(agg) > 0 && !lineStats,
 	}, nil
 }
 
+// dominantAuthor returns the author with the most changes, breaking ties by
+// name so the result is deterministic.
+func dominantAuthor(byAuthor map[string]int64) (string, int64) {
+	var owner string
+	var most int64
+	for author, n := range byAuthor {
+		if n > most || (n == most && author < owner) {
+			owner, most = author, n
+		}
+	}
+	return owner, most
+}
+
+// topAuthors returns up to limit authors ordered by lines changed (additions
+// plus deletions), then commits, then name.
+func topAuthors(byAuthor map[string]*model.AuthorChurn, limit int) []model.AuthorChurn {
+	authors := make([]model.AuthorChurn, 0, len(byAuthor))
+	for _, a := range byAuthor {
+		authors = append(authors, *a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		li, lj := authors[i].Additions+authors[i].Deletions, authors[j].Additions+authors[j].Deletions
+		if li != lj {
+			return li > lj
+		}
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		return authors[i].Author < authors[j].Author
+	})
+	if len(authors) > limit {
+		authors = authors[:limit]
+	}
+	if len(authors) == 0 {
+		return nil
+	}
+	return authors
+}
+
+// maxCouplingCommitFiles skips commits touching more files than this when
+// computing coupling: mass renames and reformats would otherwise pair every
+// file with every other.
+const maxCouplingCommitFiles = 50
+
+// ComputeCoupling finds pairs of files changed in the same commits, given
+// each commit's changed files, keeping pairs that changed together in at
+// least minSupport commits. Results are sorted by co-changes, then
+// confidence, then path.
+func ComputeCoupling(commitFiles [][]provider.FileChange, minSupport int) []model.FileCoupling {
+	if minSupport < 1 {
+		minSupport = 1
+	}
+
+	type pair struct{ a, b string }
+	changes := map[string]int{}
+	together := map[pair]int{}
+	for _, files := range commitFiles {
+		seen := map[string]bool{}
+		var paths []string
+		for _, f := range files {
+			if !seen[f.Path] {
+				seen[f.Path] = true
+				paths = append(paths, f.Path)
+			}
+		}
+		for _, p := range paths {
+			changes[p]++
+		}
+		if len(paths) > maxCouplingCommitFiles {
+			continue
+		}
+		sort.Strings(paths)
+		for i := range paths {
+			for j := i + 1; j < len(paths); j++ {
+				together[pair{paths[i], paths[j]}]++
+			}
+		}
+	}
+
+	var couplings []model.FileCoupling
+	for p, n := range together {
+		if n < minSupport {
+			continue
+		}
+		a, b := p.a, p.b
+		if changes[b] < changes[a] {
+			a, b = b, a
+		}
+		couplings = append(couplings, model.FileCoupling{
+			FileA: a, FileB: b, CoChanges: n,
+			Confidence: float64(n) / float64(changes[a]),
+		})
+	}
+
+	sort.Slice(couplings, func(i, j int) bool {
+		ci, cj := couplings[i], couplings[j]
+		if ci.CoChanges != cj.CoChanges {
+			return ci.CoChanges > cj.CoChanges
+		}
+		if ci.Confidence != cj.Confidence {
+			return ci.Confidence > cj.Confidence
+		}
+		if ci.FileA != cj.FileA {
+			return ci.FileA < cj.FileA
+		}
+		return ci.FileB < cj.FileB
+	})
+	return couplings
+}
+
 const maxHotspots = 10
 
 func ComputeHotspots(files []model.FileChurn, complexity map[string]int64, limit int) []model.FileChurn {
@@ -3,6 +3,7 @@ package churn_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/churn"
 	"github.com/dsablic/codemium/internal/model"
@@ -39,7 +40,7 @@ func TestAnalyzeChurn(t *testing.T) {
 		},
 	}
 
-	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0)
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
 	if err != nil {
 		t.Fatalf("Analyze: %v", err)
 	}
@@ -57,6 +58,60 @@ func TestAnalyzeChurn(t *testing.T) {
 	}
 }
 
+func TestAnalyzeChurnByCategory(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{{Hash: "aaa"}, {Hash: "bbb"}},
+		files: map[string][]provider.FileChange{
+			"aaa": {{Path: "main.go", Additions: 50, Deletions: 10}, {Path: "main_test.go", Additions: 20, Deletions: 5}},
+			"bbb": {{Path: "main.go", Additions: 30, Deletions: 5}, {Path: "docs/setup.md", Additions: 5}, {Path: "config.yaml", Additions: 2, Deletions: 1}},
+		},
+	}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	code := stats.ByCategory[churn.CategoryCode]
+	if code.Files != 1 || code.Changes != 2 || code.Additions != 80 || code.Deletions != 15 {
+		t.Errorf("unexpected code churn: %+v", code)
+	}
+	for _, category := range []string{churn.CategoryTest, churn.CategoryDocs, churn.CategoryConfig} {
+		if stats.ByCategory[category].Files != 1 {
+			t.Errorf("expected 1 %s file, got %+v", category, stats.ByCategory[category])
+		}
+	}
+	if _, ok := stats.ByCategory[churn.CategoryOther]; ok {
+		t.Error("expected no other category")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]string{
+		"cmd/main.go":              churn.CategoryCode,
+		"src/App.tsx":              churn.CategoryCode,
+		"web/index.html":           churn.CategoryCode,
+		"pkg/util_test.go":         churn.CategoryTest,
+		"src/button.spec.ts":       churn.CategoryTest,
+		"tests/test_api.py":        churn.CategoryTest,
+		"test/helpers.rb":          churn.CategoryTest,
+		"README.md":                churn.CategoryDocs,
+		"docs/guide.rst":           churn.CategoryDocs,
+		"CHANGELOG":                churn.CategoryDocs,
+		"config/app.yaml":          churn.CategoryConfig,
+		"package.json":             churn.CategoryConfig,
+		"go.sum":                   churn.CategoryConfig,
+		"Dockerfile":               churn.CategoryConfig,
+		".github/workflows/ci.yml": churn.CategoryConfig,
+		".gitignore":               churn.CategoryConfig,
+		"assets/logo.png":          churn.CategoryOther,
+	}
+	for path, want := range tests {
+		if got := churn.Classify(path); got != want {
+			t.Errorf("Classify(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
 func TestAnalyzeChurnLimit(t *testing.T) {
 	mock := &mockChurnLister{
 		commits: []provider.CommitInfo{{Hash: "aaa"}, {Hash: "bbb"}},
@@ -66,7 +121,7 @@ func TestAnalyzeChurnLimit(t *testing.T) {
 		},
 	}
 
-	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 1)
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 1, time.Time{}, false, nil)
 	if err != nil {
 		t.Fatalf("Analyze: %v", err)
 	}
@@ -97,3 +152,197 @@ func TestComputeHotspots(t *testing.T) {
 		t.Errorf("expected hotspot score 500, got %f", hotspots[0].Hotspot)
 	}
 }
+
+func TestComputeCoupling(t *testing.T) {
+	commitFiles := [][]provider.FileChange{
+		{{Path: "api.go"}, {Path: "api_test.go"}, {Path: "README.md"}},
+		{{Path: "api.go"}, {Path: "api_test.go"}},
+		{{Path: "api_test.go"}, {Path: "api.go"}, {Path: "main.go"}},
+	}
+
+	couplings := churn.ComputeCoupling(commitFiles, 2)
+	if len(couplings) != 1 {
+		t.Fatalf("expected only api.go/api_test.go to reach the minimum support, got %+v", couplings)
+	}
+	c := couplings[0]
+	if c.FileA != "api.go" || c.FileB != "api_test.go" || c.CoChanges != 3 || c.Confidence != 1 {
+		t.Errorf("expected api.go and api_test.go always changed together, got %+v", c)
+	}
+
+	couplings = churn.ComputeCoupling(commitFiles, 1)
+	if len(couplings) != 5 || couplings[0].CoChanges != 3 {
+		t.Fatalf("expected every pair with the strongest first, got %+v", couplings)
+	}
+	for _, c := range couplings[1:] {
+		if (c.FileA != "README.md" && c.FileA != "main.go") || c.Confidence != 1 {
+			t.Errorf("expected the less-changed file as FileA with full confidence, got %+v", c)
+		}
+	}
+}
+
+func TestAnalyzeChurnCouplings(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{{Hash: "aaa"}, {Hash: "bbb"}, {Hash: "ccc"}},
+		files: map[string][]provider.FileChange{
+			"aaa": {{Path: "model.go", Additions: 5}, {Path: "schema.sql", Additions: 2}},
+			"bbb": {{Path: "model.go", Additions: 3}, {Path: "schema.sql", Additions: 1}, {Path: "main.go", Additions: 1}},
+			"ccc": {{Path: "model.go", Additions: 1}, {Path: "schema.sql", Additions: 1}},
+		},
+	}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(stats.Couplings) != 1 || stats.Couplings[0].CoChanges != 3 {
+		t.Errorf("expected model.go and schema.sql coupled in all 3 commits, got %+v", stats.Couplings)
+	}
+}
+
+func TestAnalyzeChurnOwnership(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{
+			{Hash: "aaa", Author: "Alice <alice@example.com>"},
+			{Hash: "bbb", Author: "Alice <ALICE@example.com>"},
+			{Hash: "ccc", Author: "Bob <bob@example.com>"},
+			{Hash: "ddd", Author: "renovate[bot] <bot@example.com>"},
+		},
+		files: map[string][]provider.FileChange{
+			"aaa": {{Path: "main.go", Additions: 5}},
+			"bbb": {{Path: "main.go", Additions: 5}},
+			"ccc": {{Path: "main.go", Additions: 5}, {Path: "util.go", Additions: 1}},
+			"ddd": {{Path: "main.go", Additions: 1}, {Path: "go.mod", Additions: 1}},
+		},
+	}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, true, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	owners := map[string]model.FileChurn{}
+	for _, f := range stats.TopFiles {
+		owners[f.Path] = f
+	}
+	if f := owners["main.go"]; f.Owner != "alice@example.com" || f.OwnerShare != 50 {
+		t.Errorf("expected alice to own half of main.go's changes, got %+v", f)
+	}
+	if f := owners["util.go"]; f.Owner != "bob@example.com" || f.OwnerShare != 100 {
+		t.Errorf("expected bob to own util.go, got %+v", f)
+	}
+	if f := owners["go.mod"]; f.Owner != "" {
+		t.Errorf("bot-only file should have no owner, got %+v", f)
+	}
+
+	stats, _ = churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if stats.TopFiles[0].Owner != "" {
+		t.Error("expected no owners without ownership")
+	}
+}
+
+func TestAnalyzeChurnAuthors(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{
+			{Hash: "aaa", Author: "Alice <alice@example.com>"},
+			{Hash: "bbb", Author: "Bob <bob@example.com>"},
+			{Hash: "ccc", Author: "alice <Alice@Example.com>"},
+		},
+		files: map[string][]provider.FileChange{
+			"aaa": {{Path: "main.go", Additions: 40, Deletions: 4}, {Path: "util.go", Additions: 10}},
+			"bbb": {{Path: "main.go", Additions: 7, Deletions: 3}},
+			"ccc": {{Path: "util.go", Additions: 5, Deletions: 1}},
+		},
+	}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	want := []model.AuthorChurn{
+		{Author: "alice@example.com", Commits: 2, Additions: 55, Deletions: 5},
+		{Author: "bob@example.com", Commits: 1, Additions: 7, Deletions: 3},
+	}
+	if len(stats.AuthorChurn) != len(want) {
+		t.Fatalf("expected %d authors, got %+v", len(want), stats.AuthorChurn)
+	}
+	for i, w := range want {
+		if stats.AuthorChurn[i] != w {
+			t.Errorf("author %d: expected %+v, got %+v", i, w, stats.AuthorChurn[i])
+		}
+	}
+}
+
+func TestAnalyzeChurnStatsUnavailable(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{{Hash: "aaa"}, {Hash: "bbb"}},
+		files: map[string][]provider.FileChange{
+			"aaa": {{Path: "main.go"}},
+			"bbb": {{Path: "main.go"}, {Path: "util.go"}},
+		},
+	}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !stats.StatsUnavailable {
+		t.Error("expected all-zero file stats to be flagged")
+	}
+
+	mock.files["bbb"][1].Additions = 3
+	stats, _ = churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, false, nil)
+	if stats.StatsUnavailable {
+		t.Error("expected no flag when some files have line counts")
+	}
+}
+
+func TestAnalyzeChurnSince(t *testing.T) {
+	mock := &mockChurnLister{
+		commits: []provider.CommitInfo{
+			{Hash: "new", Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+			{Hash: "old", Date: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		files: map[string][]provider.FileChange{
+			"new": {{Path: "main.go", Additions: 5}},
+			"old": {{Path: "legacy.go", Additions: 50}},
+		},
+	}
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, since, false, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if stats.TotalCommits != 1 || len(stats.TopFiles) != 1 || stats.TopFiles[0].Path != "main.go" {
+		t.Errorf("expected only the commit after since, got %+v", stats)
+	}
+}
+
+type mockCommitLister struct {
+	commits []provider.CommitInfo
+}
+
+func (m *mockCommitLister) ListCommits(_ context.Context, _ model.Repo, _ int) ([]provider.CommitInfo, error) {
+	return m.commits, nil
+}
+
+func (m *mockCommitLister) CommitStats(_ context.Context, _ model.Repo, _ string) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+func TestAnalyzeChurnWithoutFileStats(t *testing.T) {
+	mock := &mockCommitLister{commits: []provider.CommitInfo{{Hash: "aaa"}, {Hash: "bbb"}}}
+
+	stats, err := churn.Analyze(context.Background(), mock, model.Repo{Slug: "test"}, 0, time.Time{}, true, nil)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if !stats.FileStatsUnavailable {
+		t.Error("expected FileStatsUnavailable without CommitFileStats")
+	}
+	if stats.TotalCommits != 2 {
+		t.Errorf("expected 2 total commits, got %d", stats.TotalCommits)
+	}
+	if stats.TopFiles == nil || len(stats.TopFiles) != 0 {
+		t.Errorf("expected empty top files, got %v", stats.TopFiles)
+	}
+}
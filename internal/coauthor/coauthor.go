@@ -0,0 +1,86 @@
+// Package coauthor builds co-authorship counts from Co-authored-by trailers.
+package coauthor
+
+import (
+	"sort"
+
+	"github.com/dsablic/codemium/internal/aidetect"
+	"github.com/dsablic/codemium/internal/health"
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+type pairKey struct {
+	a, b string
+}
+
+// Pairs counts, for every pair of people on the same commit (the commit
+// author plus each Co-authored-by trailer), how many commits they share.
+// People are identified by lower-cased email, merged through authors when it
+// is non-nil. AI tools and bots are left out so they don't dominate the graph.
+// Pairs are sorted by commit count, highest first.
+func Pairs(commits []provider.CommitInfo, authors *health.AuthorMap) []model.CoAuthorPair {
+	counts := map[pairKey]int{}
+	for _, c := range commits {
+		coAuthors := aidetect.CoAuthors(c.Message)
+		if len(coAuthors) == 0 {
+			continue
+		}
+
+		seen := map[string]bool{}
+		var people []string
+		for _, p := range append([]string{c.Author}, coAuthors...) {
+			if aidetect.IsAITool(p) || aidetect.IsBotAuthor(p) {
+				continue
+			}
+			id := authors.Normalize(p)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			people = append(people, id)
+		}
+
+		for i := 0; i < len(people); i++ {
+			for j := i + 1; j < len(people); j++ {
+				counts[newPairKey(people[i], people[j])]++
+			}
+		}
+	}
+	return sortedPairs(counts)
+}
+
+// Merge sums the per-repository co-authorship counts into one list.
+func Merge(repos []model.RepoStats) []model.CoAuthorPair {
+	counts := map[pairKey]int{}
+	for _, r := range repos {
+		for _, p := range r.CoAuthorship {
+			counts[newPairKey(p.AuthorA, p.AuthorB)] += p.Commits
+		}
+	}
+	return sortedPairs(counts)
+}
+
+func newPairKey(x, y string) pairKey {
+	if y < x {
+		x, y = y, x
+	}
+	return pairKey{a: x, b: y}
+}
+
+func sortedPairs(counts map[pairKey]int) []model.CoAuthorPair {
+	var pairs []model.CoAuthorPair
+	for k, n := range counts {
+		pairs = append(pairs, model.CoAuthorPair{AuthorA: k.a, AuthorB: k.b, Commits: n})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Commits != pairs[j].Commits {
+			return pairs[i].Commits > pairs[j].Commits
+		}
+		if pairs[i].AuthorA != pairs[j].AuthorA {
+			return pairs[i].AuthorA < pairs[j].AuthorA
+		}
+		return pairs[i].AuthorB < pairs[j].AuthorB
+	})
+	return pairs
+}
@@ -0,0 +1,74 @@
+package coauthor_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dsablic/codemium/internal/coauthor"
+	"github.com/dsablic/codemium/internal/health"
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func TestPairs(t *testing.T) {
+	commits := []provider.CommitInfo{
+		{Author: "Alice <alice@example.com>", Message: "feat: a\n\nCo-authored-by: Bob <bob@example.com>"},
+		{Author: "Bob <bob@example.com>", Message: "fix: b\n\nCo-Authored-By: Alice <ALICE@example.com>\nCo-authored-by: Carol <carol@example.com>"},
+		{Author: "Alice <alice@example.com>", Message: "chore: c\n\nCo-Authored-By: Claude <noreply@anthropic.com>"},
+		{Author: "Dave <dave@example.com>", Message: "solo work"},
+	}
+
+	pairs := coauthor.Pairs(commits, nil)
+	want := []model.CoAuthorPair{
+		{AuthorA: "alice@example.com", AuthorB: "bob@example.com", Commits: 2},
+		{AuthorA: "alice@example.com", AuthorB: "carol@example.com", Commits: 1},
+		{AuthorA: "bob@example.com", AuthorB: "carol@example.com", Commits: 1},
+	}
+	if len(pairs) != len(want) {
+		t.Fatalf("expected %d pairs, got %d: %+v", len(want), len(pairs), pairs)
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("pair %d: expected %+v, got %+v", i, want[i], pairs[i])
+		}
+	}
+}
+
+func TestPairsWithAuthorMap(t *testing.T) {
+	authors, err := health.ParseAuthorMap(strings.NewReader("Alice <alice@example.com> <alice@home.example>\n"))
+	if err != nil {
+		t.Fatalf("ParseAuthorMap: %v", err)
+	}
+	commits := []provider.CommitInfo{
+		// Aliases of the same person don't form a pair
+		{Author: "Alice <alice@home.example>", Message: "x\n\nCo-authored-by: Alice <alice@example.com>"},
+		{Author: "Alice <alice@home.example>", Message: "y\n\nCo-authored-by: Bob <bob@example.com>"},
+	}
+
+	pairs := coauthor.Pairs(commits, authors)
+	if len(pairs) != 1 || pairs[0].AuthorA != "alice@example.com" || pairs[0].AuthorB != "bob@example.com" {
+		t.Errorf("unexpected pairs: %+v", pairs)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	repos := []model.RepoStats{
+		{Repository: "a", CoAuthorship: []model.CoAuthorPair{{AuthorA: "x@e.com", AuthorB: "y@e.com", Commits: 2}}},
+		{Repository: "b", CoAuthorship: []model.CoAuthorPair{
+			{AuthorA: "x@e.com", AuthorB: "y@e.com", Commits: 3},
+			{AuthorA: "x@e.com", AuthorB: "z@e.com", Commits: 1},
+		}},
+		{Repository: "c"},
+	}
+
+	merged := coauthor.Merge(repos)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 pairs, got %+v", merged)
+	}
+	if merged[0].AuthorB != "y@e.com" || merged[0].Commits != 5 {
+		t.Errorf("expected x/y with 5 commits first, got %+v", merged[0])
+	}
+	if coauthor.Merge(nil) != nil {
+		t.Error("expected nil for no data")
+	}
+}
@@ -0,0 +1,97 @@
+// Package complexity flags repositories, languages, and files whose
+// cyclomatic complexity exceeds configured thresholds.
+package complexity
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// Thresholds holds the limits parsed from --complexity-threshold. Zero
+// disables a check.
+type Thresholds struct {
+	// Default applies to a repository's total complexity and to hotspot files.
+	Default int64
+	// ByLanguage applies to a language's complexity within each repository,
+	// keyed by language name as reported by scc (e.g. "Go").
+	ByLanguage map[string]int64
+}
+
+// ParseThresholds parses threshold specs of the form "N" (the default) or
+// "Language=N". Language names are matched case-insensitively.
+func ParseThresholds(specs []string) (Thresholds, error) {
+	t := Thresholds{ByLanguage: map[string]int64{}}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		lang, value, hasLang := strings.Cut(spec, "=")
+		if !hasLang {
+			value = spec
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || n <= 0 {
+			return Thresholds{}, fmt.Errorf("invalid complexity threshold %q (use N or Language=N with N > 0)", spec)
+		}
+		if hasLang {
+			lang = strings.TrimSpace(lang)
+			if lang == "" {
+				return Thresholds{}, fmt.Errorf("invalid complexity threshold %q: missing language", spec)
+			}
+			t.ByLanguage[strings.ToLower(lang)] = n
+		} else {
+			t.Default = n
+		}
+	}
+	return t, nil
+}
+
+// Warnings returns an entry for every repository whose total complexity
+// exceeds the default threshold, every language within a repository that
+// exceeds its language threshold, and every churn hotspot file that exceeds
+// the default threshold. Results are sorted by complexity, highest first.
+func Warnings(repos []model.RepoStats, t Thresholds) []model.ComplexityWarning {
+	var warnings []model.ComplexityWarning
+	for _, r := range repos {
+		if t.Default > 0 && r.Totals.Complexity > t.Default {
+			warnings = append(warnings, model.ComplexityWarning{
+				Repository: r.Repository,
+				Complexity: r.Totals.Complexity,
+				Threshold:  t.Default,
+			})
+		}
+		for _, lang := range r.Languages {
+			limit, ok := t.ByLanguage[strings.ToLower(lang.Name)]
+			if ok && lang.Complexity > limit {
+				warnings = append(warnings, model.ComplexityWarning{
+					Repository: r.Repository,
+					Language:   lang.Name,
+					Complexity: lang.Complexity,
+					Threshold:  limit,
+				})
+			}
+		}
+		if t.Default > 0 && r.Churn != nil {
+			for _, f := range r.Churn.Hotspots {
+				if f.Complexity > t.Default {
+					warnings = append(warnings, model.ComplexityWarning{
+						Repository: r.Repository,
+						Path:       f.Path,
+						Complexity: f.Complexity,
+						Threshold:  t.Default,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Complexity > warnings[j].Complexity
+	})
+	return warnings
+}
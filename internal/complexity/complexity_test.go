@@ -0,0 +1,102 @@
+package complexity
+
+import (
+	"testing"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+func TestParseThresholds(t *testing.T) {
+	th, err := ParseThresholds([]string{"500", "Go=300", " python = 200 "})
+	if err != nil {
+		t.Fatalf("ParseThresholds: %v", err)
+	}
+	if th.Default != 500 {
+		t.Errorf("expected default 500, got %d", th.Default)
+	}
+	if th.ByLanguage["go"] != 300 || th.ByLanguage["python"] != 200 {
+		t.Errorf("unexpected language thresholds: %v", th.ByLanguage)
+	}
+
+	for _, bad := range []string{"abc", "0", "Go=-1", "=5"} {
+		if _, err := ParseThresholds([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	repos := []model.RepoStats{
+		{
+			Repository: "big",
+			Totals:     model.Stats{Complexity: 900},
+			Languages: []model.LanguageStats{
+				{Name: "Go", Complexity: 700},
+				{Name: "Python", Complexity: 200},
+			},
+			Churn: &model.ChurnStats{Hotspots: []model.FileChurn{
+				{Path: "core/engine.go", Complexity: 600},
+				{Path: "util.go", Complexity: 50},
+			}},
+		},
+		{
+			Repository: "small",
+			Totals:     model.Stats{Complexity: 100},
+			Languages:  []model.LanguageStats{{Name: "Go", Complexity: 100}},
+		},
+	}
+
+	warnings := Warnings(repos, Thresholds{Default: 500, ByLanguage: map[string]int64{"go": 300}})
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %d: %+v", len(warnings), warnings)
+	}
+
+	// Sorted by complexity descending: repo total, Go language, hotspot file
+	if warnings[0].Repository != "big" || warnings[0].Language != "" || warnings[0].Path != "" || warnings[0].Complexity != 900 {
+		t.Errorf("expected repo-level warning first, got %+v", warnings[0])
+	}
+	if warnings[1].Language != "Go" || warnings[1].Threshold != 300 {
+		t.Errorf("expected Go language warning, got %+v", warnings[1])
+	}
+	if warnings[2].Path != "core/engine.go" || warnings[2].Threshold != 500 {
+		t.Errorf("expected hotspot file warning, got %+v", warnings[2])
+	}
+}
+
+func TestWarningsLanguageOnly(t *testing.T) {
+	repos := []model.RepoStats{{
+		Repository: "svc",
+		Totals:     model.Stats{Complexity: 10000},
+		Languages:  []model.LanguageStats{{Name: "Go", Complexity: 10000}},
+	}}
+
+	// Without a default threshold the repo total is not checked
+	warnings := Warnings(repos, Thresholds{ByLanguage: map[string]int64{"python": 10}})
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	var repos []model.RepoStats
+	for i := int64(1); i <= 10; i++ {
+		repos = append(repos, model.RepoStats{Totals: model.Stats{Code: i * 100, Complexity: 11 - i}})
+	}
+
+	d := Distribution(repos)
+	if d == nil || d.Repos != 10 {
+		t.Fatalf("expected a distribution over 10 repos, got %+v", d)
+	}
+	if d.Code != (model.Percentiles{P50: 500, P90: 900, P99: 1000}) {
+		t.Errorf("unexpected code percentiles: %+v", d.Code)
+	}
+	if d.Complexity != (model.Percentiles{P50: 5, P90: 9, P99: 10}) {
+		t.Errorf("unexpected complexity percentiles: %+v", d.Complexity)
+	}
+	if repos[0].Totals.Code != 100 {
+		t.Error("Distribution reordered the input")
+	}
+	if Distribution(nil) != nil {
+		t.Error("expected nil without repositories")
+	}
+}
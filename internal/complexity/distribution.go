@@ -0,0 +1,40 @@
+package complexity
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// Distribution returns the p50/p90/p99 of per-repository code and
+// complexity, or nil when there are no repositories. Percentiles use the
+// nearest-rank method, so each is the value of an actual repository.
+func Distribution(repos []model.RepoStats) *model.Distribution {
+	if len(repos) == 0 {
+		return nil
+	}
+	code := make([]int64, len(repos))
+	cx := make([]int64, len(repos))
+	for i, r := range repos {
+		code[i] = r.Totals.Code
+		cx[i] = r.Totals.Complexity
+	}
+	return &model.Distribution{
+		Repos:      len(repos),
+		Code:       percentiles(code),
+		Complexity: percentiles(cx),
+	}
+}
+
+func percentiles(values []int64) model.Percentiles {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	rank := func(p float64) int64 {
+		i := int(math.Ceil(p/100*float64(len(values)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return values[i]
+	}
+	return model.Percentiles{P50: rank(50), P90: rank(90), P99: rank(99)}
+}
@@ -0,0 +1,35 @@
+// internal/conventional/conventional.go
+package conventional
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+// headerRe matches a Conventional Commits header: type, optional scope,
+// optional breaking-change marker, then ": " and a description.
+var headerRe = regexp.MustCompile(`(?i)^(feat|fix|docs|style|refactor|perf|test|build|ci|chore|revert)(\([^)]*\))?!?: \S`)
+
+// IsConventional reports whether the first line of a commit message follows
+// the Conventional Commits format.
+func IsConventional(message string) bool {
+	firstLine, _, _ := strings.Cut(message, "\n")
+	return headerRe.MatchString(strings.TrimSpace(firstLine))
+}
+
+// Percent returns the percentage of commits whose message follows the
+// Conventional Commits format, or 0 when there are no commits.
+func Percent(commits []provider.CommitInfo) float64 {
+	if len(commits) == 0 {
+		return 0
+	}
+	var matched int
+	for _, c := range commits {
+		if IsConventional(c.Message) {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(commits)) * 100
+}
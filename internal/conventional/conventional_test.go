@@ -0,0 +1,50 @@
+// internal/conventional/conventional_test.go
+package conventional_test
+
+import (
+	"testing"
+
+	"github.com/dsablic/codemium/internal/conventional"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func TestIsConventional(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected bool
+	}{
+		{"feat: add login", true},
+		{"fix(api): handle nil response", true},
+		{"refactor!: drop legacy config", true},
+		{"feat(parser)!: new grammar\n\nBREAKING CHANGE: old syntax removed", true},
+		{"Fix: capitalized type", true},
+		{"Add login page", false},
+		{"feat:missing space", false},
+		{"feature: not a known type", false},
+		{"Merge branch 'main' into feat: thing", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := conventional.IsConventional(tt.message); got != tt.expected {
+				t.Errorf("IsConventional(%q) = %v, want %v", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	commits := []provider.CommitInfo{
+		{Message: "feat: one"},
+		{Message: "fix: two"},
+		{Message: "wip"},
+		{Message: "Update README.md"},
+	}
+	if got := conventional.Percent(commits); got != 50 {
+		t.Errorf("expected 50%%, got %.1f", got)
+	}
+	if got := conventional.Percent(nil); got != 0 {
+		t.Errorf("expected 0 for no commits, got %.1f", got)
+	}
+}
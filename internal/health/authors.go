@@ -0,0 +1,113 @@
+// internal/health/authors.go
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// AuthorMap unifies commit author aliases. It maps alias emails to a
+// canonical email using entries in .mailmap format:
+//
+//	Proper Name <proper@example.com> <alias@example.com>
+//	Proper Name <proper@example.com> Alias Name <alias@example.com>
+//	<proper@example.com> <alias@example.com>
+//
+// Entries with a single email only rename and do not affect identity.
+// A nil *AuthorMap applies the built-in normalization only.
+type AuthorMap struct {
+	aliases map[string]string // lowercased alias email -> lowercased canonical email
+}
+
+// LoadAuthorMap reads a .mailmap-format file.
+func LoadAuthorMap(path string) (*AuthorMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open author map: %w", err)
+	}
+	defer f.Close()
+	return ParseAuthorMap(f)
+}
+
+// ParseAuthorMap parses .mailmap-format entries from r. Blank lines and
+// '#' comments are ignored.
+func ParseAuthorMap(r io.Reader) (*AuthorMap, error) {
+	m := &AuthorMap{aliases: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		emails, err := mailmapEmails(line)
+		if err != nil {
+			return nil, fmt.Errorf("author map line %d: %w", lineNo, err)
+		}
+		switch len(emails) {
+		case 1:
+			// "Proper Name <email>" only fixes the display name
+		case 2:
+			canonical := strings.ToLower(emails[0])
+			alias := strings.ToLower(emails[1])
+			if alias != canonical {
+				m.aliases[alias] = canonical
+			}
+		default:
+			return nil, fmt.Errorf("author map line %d: expected one or two <email> entries, got %d", lineNo, len(emails))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read author map: %w", err)
+	}
+	return m, nil
+}
+
+// mailmapEmails extracts the <email> entries of a mailmap line in order.
+func mailmapEmails(line string) ([]string, error) {
+	var emails []string
+	for {
+		start := strings.Index(line, "<")
+		if start < 0 {
+			if strings.Contains(line, ">") {
+				return nil, fmt.Errorf("unbalanced '>'")
+			}
+			return emails, nil
+		}
+		end := strings.Index(line[start:], ">")
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced '<'")
+		}
+		emails = append(emails, strings.TrimSpace(line[start+1:start+end]))
+		line = line[start+end+1:]
+	}
+}
+
+// Len returns the number of alias mappings.
+func (m *AuthorMap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.aliases)
+}
+
+// Normalize returns the identity used to deduplicate an author: the
+// lowercased email from "Name <email>" (or the whole string when there is
+// no email), resolved through the alias map when one is loaded.
+func (m *AuthorMap) Normalize(author string) string {
+	key := normalizeAuthor(author)
+	if m != nil {
+		if canonical, ok := m.aliases[key]; ok {
+			return canonical
+		}
+	}
+	return key
+}
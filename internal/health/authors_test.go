@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func TestParseAuthorMap(t *testing.T) {
+	mailmap := `# Team aliases
+Jane Doe <jane@work.com> <jane@personal.com>
+Jane Doe <jane@work.com> Janie <JANE@old-laptop.local>
+<bob@work.com> <bob@users.noreply.github.com>
+Carol Name <carol@work.com>
+`
+	m, err := ParseAuthorMap(strings.NewReader(mailmap))
+	if err != nil {
+		t.Fatalf("ParseAuthorMap: %v", err)
+	}
+	if m.Len() != 3 {
+		t.Errorf("expected 3 aliases, got %d", m.Len())
+	}
+
+	tests := []struct {
+		author   string
+		expected string
+	}{
+		{"Jane <jane@personal.com>", "jane@work.com"},
+		{"Janie <jane@old-laptop.local>", "jane@work.com"},
+		{"Jane Doe <jane@work.com>", "jane@work.com"},
+		{"bob <bob@users.noreply.github.com>", "bob@work.com"},
+		{"Carol <carol@work.com>", "carol@work.com"},
+		{"Dave <dave@work.com>", "dave@work.com"},
+	}
+	for _, tt := range tests {
+		if got := m.Normalize(tt.author); got != tt.expected {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.author, got, tt.expected)
+		}
+	}
+}
+
+func TestParseAuthorMapErrors(t *testing.T) {
+	for _, bad := range []string{
+		"Jane <jane@work.com",
+		"<a@x> <b@x> <c@x>",
+	} {
+		if _, err := ParseAuthorMap(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestNilAuthorMapNormalize(t *testing.T) {
+	var m *AuthorMap
+	if got := m.Normalize("Dev <Dev@Example.com>"); got != "dev@example.com" {
+		t.Errorf("expected built-in normalization, got %q", got)
+	}
+}
+
+func TestAnalyzeDetailsWithAuthorMap(t *testing.T) {
+	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
+	commits := []provider.CommitInfo{
+		{Hash: "a1", Author: "Jane <jane@work.com>", Date: now.AddDate(0, -1, 0)},
+		{Hash: "a2", Author: "jane <jane@personal.com>", Date: now.AddDate(0, -2, 0)},
+		{Hash: "a3", Author: "Bob <bob@work.com>", Date: now.AddDate(0, -3, 0)},
+	}
+	authors, err := ParseAuthorMap(strings.NewReader("Jane <jane@work.com> <jane@personal.com>\n"))
+	if err != nil {
+		t.Fatalf("ParseAuthorMap: %v", err)
+	}
+
+	details, _, err := AnalyzeDetails(context.Background(), &mockCommitLister{commits: commits}, model.Repo{Slug: "r"}, commits, now, authors, DefaultVelocityBand, nil)
+	if err != nil {
+		t.Fatalf("AnalyzeDetails: %v", err)
+	}
+	if details.AuthorsByWindow[Window0to6] != 2 {
+		t.Errorf("expected aliases to merge into 2 authors, got %d", details.AuthorsByWindow[Window0to6])
+	}
+	// Jane has 2 of 3 commits once aliases merge
+	if details.BusFactor < 66 || details.BusFactor > 67 {
+		t.Errorf("expected bus factor ~66.7%%, got %.1f%%", details.BusFactor)
+	}
+}
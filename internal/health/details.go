@@ -14,16 +14,87 @@ import (
 
 const statsConcurrency = 5
 
-// Window labels for bucketing commits by age.
+// Window labels for bucketing commits by age with DefaultWindows.
 const (
 	Window0to6   = "0-6mo"
 	Window6to12  = "6-12mo"
 	Window12Plus = "12mo+"
 )
 
+// DefaultWindows are the default window boundaries in months before now:
+// 0-6mo, 6-12mo and 12mo+.
+var DefaultWindows = []int{6, 12}
+
+// ValidateWindows checks that window boundaries are positive and strictly
+// increasing.
+func ValidateWindows(bounds []int) error {
+	if len(bounds) == 0 {
+		return fmt.Errorf("at least one window boundary is required")
+	}
+	for i, b := range bounds {
+		if b <= 0 {
+			return fmt.Errorf("window boundary must be positive, got %d", b)
+		}
+		if i > 0 && b <= bounds[i-1] {
+			return fmt.Errorf("window boundaries must be increasing, got %d after %d", b, bounds[i-1])
+		}
+	}
+	return nil
+}
+
+// WindowLabels returns the labels of the windows delimited by bounds, from
+// the most recent: {3, 12} gives "0-3mo", "3-12mo" and "12mo+".
+func WindowLabels(bounds []int) []string {
+	labels := make([]string, 0, len(bounds)+1)
+	start := 0
+	for _, b := range bounds {
+		labels = append(labels, fmt.Sprintf("%d-%dmo", start, b))
+		start = b
+	}
+	return append(labels, fmt.Sprintf("%dmo+", start))
+}
+
+// Velocity labels for RepoHealthDetails.VelocityLabel.
+const (
+	VelocityAccelerating = "accelerating"
+	VelocitySteady       = "steady"
+	VelocitySlowing      = "slowing"
+)
+
+// DefaultVelocityBand is the default tolerance around 1.0 within which the
+// velocity trend counts as steady.
+const DefaultVelocityBand = 0.2
+
+// VelocityLabel describes a velocity trend (recent / previous commits):
+// accelerating above 1+band, slowing below 1-band, steady in between. A repo
+// with recent commits but none in the previous window is accelerating; one
+// with neither gets no label.
+func VelocityLabel(recent, previous int, band float64) string {
+	switch {
+	case previous == 0 && recent == 0:
+		return ""
+	case previous == 0:
+		return VelocityAccelerating
+	}
+	trend := float64(recent) / float64(previous)
+	switch {
+	case trend > 1+band:
+		return VelocityAccelerating
+	case trend < 1-band:
+		return VelocitySlowing
+	default:
+		return VelocitySteady
+	}
+}
+
 // AnalyzeDetails performs deep health analysis on a repo's commits.
+// Authors are deduplicated through authors (nil uses email normalization only),
+// and velocityBand sets the steady range of the velocity label. Commits are
+// bucketed by the month boundaries in windows (nil uses DefaultWindows, see
+// WindowLabels for the keys); the velocity trend compares the first window
+// with the second.
 // It returns the details, a list of partial error messages (e.g. per-commit stat failures), and a fatal error.
-func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo model.Repo, commits []provider.CommitInfo, now time.Time) (*model.RepoHealthDetails, []string, error) {
+func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo model.Repo, commits []provider.CommitInfo, now time.Time, authors *AuthorMap, velocityBand float64, windows []int) (*model.RepoHealthDetails, []string, error) {
 	if len(commits) == 0 {
 		return &model.RepoHealthDetails{
 			AuthorsByWindow: map[string]int{},
@@ -31,25 +102,27 @@ func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo mode
 		}, nil, nil
 	}
 
-	sixMoAgo := now.AddDate(0, -6, 0)
-	twelveMoAgo := now.AddDate(0, -12, 0)
+	if windows == nil {
+		windows = DefaultWindows
+	}
+	labels := WindowLabels(windows)
+	cutoffs := make([]time.Time, len(windows))
+	for i, months := range windows {
+		cutoffs[i] = now.AddDate(0, -months, 0)
+	}
 
 	// Bucket commits by window
-	authorSets := map[string]map[string]bool{
-		Window0to6:   {},
-		Window6to12:  {},
-		Window12Plus: {},
-	}
-	churn := map[string]*model.WindowChurnStats{
-		Window0to6:   {},
-		Window6to12:  {},
-		Window12Plus: {},
+	authorSets := map[string]map[string]bool{}
+	churn := map[string]*model.WindowChurnStats{}
+	for _, label := range labels {
+		authorSets[label] = map[string]bool{}
+		churn[label] = &model.WindowChurnStats{}
 	}
 	authorCommitCounts := map[string]int{}
 
 	for _, c := range commits {
-		window := commitWindow(c.Date, sixMoAgo, twelveMoAgo)
-		author := normalizeAuthor(c.Author)
+		window := commitWindow(c.Date, cutoffs, labels)
+		author := authors.Normalize(c.Author)
 		authorSets[window][author] = true
 		authorCommitCounts[author]++
 		churn[window].Commits++
@@ -93,7 +166,7 @@ func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo mode
 
 	// We don't fail on stat errors — just use what we got
 	for i, c := range commits {
-		window := commitWindow(c.Date, sixMoAgo, twelveMoAgo)
+		window := commitWindow(c.Date, cutoffs, labels)
 		cs := churn[window]
 		cs.Additions += results[i].additions
 		cs.Deletions += results[i].deletions
@@ -116,10 +189,10 @@ func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo mode
 		busFactor = float64(maxCommits) / float64(len(commits)) * 100
 	}
 
-	// Compute velocity trend: commits in 0-6mo / commits in 6-12mo
+	// Compute velocity trend: commits in the first window / commits in the second
 	var velocityTrend float64
-	recent := churn[Window0to6].Commits
-	previous := churn[Window6to12].Commits
+	recent := churn[labels[0]].Commits
+	previous := churn[labels[1]].Commits
 	if previous > 0 {
 		velocityTrend = float64(recent) / float64(previous)
 	}
@@ -145,18 +218,19 @@ func AnalyzeDetails(ctx context.Context, lister provider.CommitLister, repo mode
 		ChurnByWindow:   churnByWindow,
 		BusFactor:       busFactor,
 		VelocityTrend:   velocityTrend,
+		VelocityLabel:   VelocityLabel(recent, previous, velocityBand),
 	}, partialErrors, nil
 }
 
-func commitWindow(date time.Time, sixMoAgo, twelveMoAgo time.Time) string {
-	switch {
-	case date.After(sixMoAgo) || date.Equal(sixMoAgo):
-		return Window0to6
-	case date.After(twelveMoAgo) || date.Equal(twelveMoAgo):
-		return Window6to12
-	default:
-		return Window12Plus
+// commitWindow returns the label of the first window whose cutoff the date
+// is not before, or the last (open-ended) label.
+func commitWindow(date time.Time, cutoffs []time.Time, labels []string) string {
+	for i, cutoff := range cutoffs {
+		if !date.Before(cutoff) {
+			return labels[i]
+		}
 	}
+	return labels[len(labels)-1]
 }
 
 func normalizeAuthor(author string) string {
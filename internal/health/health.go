@@ -2,6 +2,7 @@
 package health
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/dsablic/codemium/internal/model"
@@ -13,8 +14,34 @@ const (
 	MaintainedThresholdDays = 365
 )
 
-// Classify returns a RepoHealth based on the last commit date relative to now.
-func Classify(lastCommitDate, now time.Time) *model.RepoHealth {
+// Thresholds are the ages, in days since the last commit, at which a
+// repository stops being active (Active) and stops being maintained
+// (Maintained).
+type Thresholds struct {
+	Active     int
+	Maintained int
+}
+
+// DefaultThresholds returns the default 180/365-day thresholds.
+func DefaultThresholds() Thresholds {
+	return Thresholds{Active: ActiveThresholdDays, Maintained: MaintainedThresholdDays}
+}
+
+// ValidateThresholds checks that both thresholds are positive and the active
+// one is below the maintained one.
+func ValidateThresholds(t Thresholds) error {
+	if t.Active <= 0 || t.Maintained <= 0 {
+		return fmt.Errorf("health thresholds must be positive, got %d and %d days", t.Active, t.Maintained)
+	}
+	if t.Active >= t.Maintained {
+		return fmt.Errorf("active threshold (%d days) must be below the maintained threshold (%d days)", t.Active, t.Maintained)
+	}
+	return nil
+}
+
+// Classify returns a RepoHealth based on the last commit date relative to
+// now and the thresholds.
+func Classify(lastCommitDate, now time.Time, thresholds Thresholds) *model.RepoHealth {
 	days := int(now.Sub(lastCommitDate).Hours() / 24)
 	if days < 0 {
 		days = 0
@@ -22,9 +49,9 @@ func Classify(lastCommitDate, now time.Time) *model.RepoHealth {
 
 	var category model.HealthCategory
 	switch {
-	case days < ActiveThresholdDays:
+	case days < thresholds.Active:
 		category = model.HealthActive
-	case days < MaintainedThresholdDays:
+	case days < thresholds.Maintained:
 		category = model.HealthMaintained
 	default:
 		category = model.HealthAbandoned
@@ -37,7 +64,9 @@ func Classify(lastCommitDate, now time.Time) *model.RepoHealth {
 	}
 }
 
-func ClassifyFromCommits(commits []provider.CommitInfo, now time.Time) *model.RepoHealth {
+// ClassifyFromCommits classifies a repository by the latest of its commits.
+// A repository without dated commits is abandoned, with DaysSinceCommit -1.
+func ClassifyFromCommits(commits []provider.CommitInfo, now time.Time, thresholds Thresholds) *model.RepoHealth {
 	if len(commits) == 0 {
 		return &model.RepoHealth{
 			Category:        model.HealthAbandoned,
@@ -61,5 +90,5 @@ func ClassifyFromCommits(commits []provider.CommitInfo, now time.Time) *model.Re
 		}
 	}
 
-	return Classify(latest, now)
+	return Classify(latest, now, thresholds)
 }
@@ -30,7 +30,7 @@ func TestClassifyBoundaries(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			lastCommit := now.AddDate(0, 0, -tt.daysAgo)
-			result := Classify(lastCommit, now)
+			result := Classify(lastCommit, now, DefaultThresholds())
 			if result.Category != tt.expected {
 				t.Errorf("expected %s, got %s", tt.expected, result.Category)
 			}
@@ -41,9 +41,51 @@ func TestClassifyBoundaries(t *testing.T) {
 	}
 }
 
+func TestClassifyCustomThresholds(t *testing.T) {
+	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
+	thresholds := Thresholds{Active: 30, Maintained: 90}
+
+	tests := []struct {
+		name     string
+		daysAgo  int
+		expected model.HealthCategory
+	}{
+		{"29 days = active", 29, model.HealthActive},
+		{"30 days = maintained", 30, model.HealthMaintained},
+		{"89 days = maintained", 89, model.HealthMaintained},
+		{"90 days = abandoned", 90, model.HealthAbandoned},
+		{"179 days = abandoned", 179, model.HealthAbandoned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Classify(now.AddDate(0, 0, -tt.daysAgo), now, thresholds)
+			if result.Category != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result.Category)
+			}
+		})
+	}
+
+	commits := []provider.CommitInfo{{Hash: "a", Date: now.AddDate(0, 0, -45)}}
+	if got := ClassifyFromCommits(commits, now, thresholds).Category; got != model.HealthMaintained {
+		t.Errorf("expected maintained at 45 days with custom thresholds, got %s", got)
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	if err := ValidateThresholds(DefaultThresholds()); err != nil {
+		t.Errorf("expected the defaults to be valid, got %v", err)
+	}
+	for _, th := range []Thresholds{{Active: 0, Maintained: 90}, {Active: 90, Maintained: 90}, {Active: 120, Maintained: 90}} {
+		if err := ValidateThresholds(th); err == nil {
+			t.Errorf("expected %+v to be rejected", th)
+		}
+	}
+}
+
 func TestClassifyFromCommitsEmpty(t *testing.T) {
 	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
-	result := ClassifyFromCommits(nil, now)
+	result := ClassifyFromCommits(nil, now, DefaultThresholds())
 	if result == nil {
 		t.Fatal("expected non-nil result for empty commits")
 	}
@@ -62,7 +104,7 @@ func TestClassifyFromCommits(t *testing.T) {
 		{Hash: "recent", Date: now.AddDate(0, 0, -10)},
 		{Hash: "middle", Date: now.AddDate(0, 0, -200)},
 	}
-	result := ClassifyFromCommits(commits, now)
+	result := ClassifyFromCommits(commits, now, DefaultThresholds())
 	if result == nil {
 		t.Fatal("expected non-nil result")
 	}
@@ -198,7 +240,7 @@ func TestAnalyzeDetails(t *testing.T) {
 	}
 
 	repo := model.Repo{Slug: "test-repo", URL: "https://github.com/org/test-repo"}
-	details, partialErrs, err := AnalyzeDetails(context.Background(), lister, repo, commits, now)
+	details, partialErrs, err := AnalyzeDetails(context.Background(), lister, repo, commits, now, nil, DefaultVelocityBand, nil)
 	if err != nil {
 		t.Fatalf("AnalyzeDetails: %v", err)
 	}
@@ -228,6 +270,9 @@ func TestAnalyzeDetails(t *testing.T) {
 	if details.VelocityTrend != 2.0 {
 		t.Errorf("expected velocity trend 2.0, got %.1f", details.VelocityTrend)
 	}
+	if details.VelocityLabel != VelocityAccelerating {
+		t.Errorf("expected accelerating velocity, got %q", details.VelocityLabel)
+	}
 
 	// Churn: 0-6mo should have additions=150, deletions=30
 	if cs, ok := details.ChurnByWindow[Window0to6]; ok {
@@ -245,12 +290,82 @@ func TestAnalyzeDetails(t *testing.T) {
 	}
 }
 
+func TestVelocityLabel(t *testing.T) {
+	tests := []struct {
+		recent, previous int
+		band             float64
+		want             string
+	}{
+		{10, 10, 0.2, VelocitySteady},
+		{11, 10, 0.2, VelocitySteady},
+		{12, 10, 0.2, VelocitySteady},
+		{13, 10, 0.2, VelocityAccelerating},
+		{7, 10, 0.2, VelocitySlowing},
+		{8, 10, 0.2, VelocitySteady},
+		{11, 10, 0, VelocityAccelerating},
+		{5, 10, 0.6, VelocitySteady},
+		{3, 0, 0.2, VelocityAccelerating},
+		{0, 4, 0.2, VelocitySlowing},
+		{0, 0, 0.2, ""},
+	}
+	for _, tt := range tests {
+		if got := VelocityLabel(tt.recent, tt.previous, tt.band); got != tt.want {
+			t.Errorf("VelocityLabel(%d, %d, %.1f) = %q, want %q", tt.recent, tt.previous, tt.band, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeDetailsCustomWindows(t *testing.T) {
+	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
+	commits := []provider.CommitInfo{
+		{Hash: "a1", Author: "Alice <alice@example.com>", Date: now.AddDate(0, -1, 0)},
+		{Hash: "a2", Author: "Bob <bob@example.com>", Date: now.AddDate(0, -4, 0)},
+		{Hash: "a3", Author: "Carol <carol@example.com>", Date: now.AddDate(0, -5, 0)},
+		{Hash: "a4", Author: "Dave <dave@example.com>", Date: now.AddDate(0, -8, 0)},
+		{Hash: "a5", Author: "Erin <erin@example.com>", Date: now.AddDate(0, -15, 0)},
+	}
+	lister := &mockCommitLister{commits: commits}
+
+	details, _, err := AnalyzeDetails(context.Background(), lister, model.Repo{Slug: "r"}, commits, now, nil, DefaultVelocityBand, []int{3, 6, 12})
+	if err != nil {
+		t.Fatalf("AnalyzeDetails: %v", err)
+	}
+	want := map[string]int{"0-3mo": 1, "3-6mo": 2, "6-12mo": 1, "12mo+": 1}
+	for window, n := range want {
+		if details.ChurnByWindow[window].Commits != n {
+			t.Errorf("expected %d commits in %s, got %d", n, window, details.ChurnByWindow[window].Commits)
+		}
+	}
+	if len(details.ChurnByWindow) != len(want) {
+		t.Errorf("expected %d windows, got %v", len(want), details.ChurnByWindow)
+	}
+	// Velocity compares the first two windows: 1 (0-3mo) / 2 (3-6mo)
+	if details.VelocityTrend != 0.5 {
+		t.Errorf("expected velocity trend 0.5, got %f", details.VelocityTrend)
+	}
+}
+
+func TestWindowLabels(t *testing.T) {
+	got := strings.Join(WindowLabels(DefaultWindows), ",")
+	if want := Window0to6 + "," + Window6to12 + "," + Window12Plus; got != want {
+		t.Errorf("WindowLabels(DefaultWindows) = %s, want %s", got, want)
+	}
+	for _, bounds := range [][]int{nil, {0}, {6, 6}, {12, 6}} {
+		if err := ValidateWindows(bounds); err == nil {
+			t.Errorf("ValidateWindows(%v) = nil, want error", bounds)
+		}
+	}
+	if err := ValidateWindows([]int{3, 6, 12}); err != nil {
+		t.Errorf("ValidateWindows: %v", err)
+	}
+}
+
 func TestAnalyzeDetailsEmpty(t *testing.T) {
 	now := time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)
 	lister := &mockCommitLister{}
 	repo := model.Repo{Slug: "empty-repo"}
 
-	details, _, err := AnalyzeDetails(context.Background(), lister, repo, nil, now)
+	details, _, err := AnalyzeDetails(context.Background(), lister, repo, nil, now, nil, DefaultVelocityBand, nil)
 	if err != nil {
 		t.Fatalf("AnalyzeDetails: %v", err)
 	}
@@ -274,7 +389,7 @@ func TestAnalyzeDetailsPartialErrors(t *testing.T) {
 	}
 
 	repo := model.Repo{Slug: "test-repo", URL: "https://github.com/org/test-repo"}
-	details, partialErrs, err := AnalyzeDetails(context.Background(), lister, repo, commits, now)
+	details, partialErrs, err := AnalyzeDetails(context.Background(), lister, repo, commits, now, nil, DefaultVelocityBand, nil)
 	if err != nil {
 		t.Fatalf("AnalyzeDetails: %v", err)
 	}
@@ -291,3 +406,29 @@ func TestAnalyzeDetailsPartialErrors(t *testing.T) {
 		t.Errorf("expected partial error to contain status code, got %q", partialErrs[0])
 	}
 }
+
+func TestRiskRepos(t *testing.T) {
+	repos := []model.RepoStats{
+		{Repository: "active", Totals: model.Stats{Code: 90000}, Health: &model.RepoHealth{Category: model.HealthActive, DaysSinceCommit: 3}},
+		{Repository: "small-dead", Totals: model.Stats{Code: 100}, Health: &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: 2000}},
+		{Repository: "big-dead", Totals: model.Stats{Code: 50000}, Health: &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: 400}},
+		{Repository: "empty-dead", Health: &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: 900}},
+		{Repository: "undated-dead", Totals: model.Stats{Code: 80000}, Health: &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: -1}},
+		{Repository: "no-health", Totals: model.Stats{Code: 70000}},
+	}
+
+	risks := RiskRepos(repos)
+	if len(risks) != 2 {
+		t.Fatalf("expected 2 risk repos, got %+v", risks)
+	}
+	if risks[0].Repository != "big-dead" || risks[0].Score != 20000000 {
+		t.Errorf("unexpected top risk: %+v", risks[0])
+	}
+	if risks[1].Repository != "small-dead" || risks[1].DaysSinceCommit != 2000 {
+		t.Errorf("unexpected second risk: %+v", risks[1])
+	}
+
+	if RiskRepos(repos[:1]) != nil {
+		t.Error("expected nil without abandoned repos")
+	}
+}
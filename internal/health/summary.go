@@ -2,6 +2,8 @@
 package health
 
 import (
+	"sort"
+
 	"github.com/dsablic/codemium/internal/model"
 )
 
@@ -49,3 +51,27 @@ func Summarize(repos []model.RepoStats) *model.HealthSummary {
 
 	return summary
 }
+
+// RiskRepos lists abandoned repositories that still hold code, ranked by
+// code × days since the last commit so large, long-dead repos come first
+// (decommission candidates). Repositories without dated commits
+// (DaysSinceCommit -1) have no age to rank by and are left out. Returns nil
+// if there are none.
+func RiskRepos(repos []model.RepoStats) []model.RiskRepo {
+	var risks []model.RiskRepo
+	for _, r := range repos {
+		if r.Health == nil || r.Health.Category != model.HealthAbandoned || r.Totals.Code == 0 || r.Health.DaysSinceCommit < 0 {
+			continue
+		}
+		risks = append(risks, model.RiskRepo{
+			Repository:      r.Repository,
+			Code:            r.Totals.Code,
+			DaysSinceCommit: r.Health.DaysSinceCommit,
+			Score:           r.Totals.Code * int64(r.Health.DaysSinceCommit),
+		})
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].Score > risks[j].Score
+	})
+	return risks
+}
@@ -1,6 +1,7 @@
 package history
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -8,6 +9,11 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
+// ISOWeekly is the interval of weekly periods aligned to ISO weeks, which
+// start on Monday, instead of stepping 7 days from since. Its periods are
+// labeled like "2025-W07".
+const ISOWeekly = "weekly-iso"
+
 // GenerateDates produces a slice of target dates based on interval.
 //
 // For "monthly": since/until are "YYYY-MM" strings. Returns end-of-month
@@ -15,12 +21,29 @@ import (
 //
 // For "weekly": since/until are "YYYY-MM-DD" strings. Returns end-of-day
 // dates (23:59:59 UTC) every 7 days from since to until inclusive.
+//
+// For "quarterly": since/until are "YYYY-MM" strings. Returns end-of-quarter
+// dates (last day of March, June, September or December, 23:59:59 UTC) for
+// each quarter from the one containing since to the one containing until.
+//
+// For "yearly": since/until are "YYYY" strings. Returns December 31,
+// 23:59:59 UTC, of each year from since to until inclusive.
+//
+// For ISOWeekly: since/until are "YYYY-MM-DD" strings. Returns the Sunday
+// (23:59:59 UTC) ending each ISO week (Monday to Sunday) from the week
+// containing since to the week containing until.
 func GenerateDates(since, until, interval string) []time.Time {
 	switch interval {
 	case "monthly":
 		return generateMonthly(since, until)
 	case "weekly":
 		return generateWeekly(since, until)
+	case "quarterly":
+		return generateQuarterly(since, until)
+	case "yearly":
+		return generateYearly(since, until)
+	case ISOWeekly:
+		return generateISOWeekly(since, until)
 	default:
 		return nil
 	}
@@ -38,12 +61,51 @@ func generateMonthly(since, until string) []time.Time {
 
 	var dates []time.Time
 	for cur := start; !cur.After(end); cur = cur.AddDate(0, 1, 0) {
-		// End of month: go to first day of next month, subtract one day,
-		// then set time to 23:59:59.
-		nextMonth := cur.AddDate(0, 1, 0)
-		lastDay := nextMonth.AddDate(0, 0, -1)
-		eom := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 23, 59, 59, 0, time.UTC)
-		dates = append(dates, eom)
+		dates = append(dates, endOfMonth(cur))
+	}
+	return dates
+}
+
+// endOfMonth returns 23:59:59 UTC on the last day of the month starting at
+// first: the first day of the next month, minus one day.
+func endOfMonth(first time.Time) time.Time {
+	lastDay := first.AddDate(0, 1, -1)
+	return time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 23, 59, 59, 0, time.UTC)
+}
+
+func generateQuarterly(since, until string) []time.Time {
+	start, err := time.Parse("2006-01", since)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01", until)
+	if err != nil {
+		return nil
+	}
+
+	// Step from the first month of since's quarter; each date is the end of
+	// that quarter's third month.
+	start = start.AddDate(0, -(int(start.Month())-1)%3, 0)
+	var dates []time.Time
+	for cur := start; !cur.After(end); cur = cur.AddDate(0, 3, 0) {
+		dates = append(dates, endOfMonth(cur.AddDate(0, 2, 0)))
+	}
+	return dates
+}
+
+func generateYearly(since, until string) []time.Time {
+	start, err := time.Parse("2006", since)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006", until)
+	if err != nil {
+		return nil
+	}
+
+	var dates []time.Time
+	for year := start.Year(); year <= end.Year(); year++ {
+		dates = append(dates, time.Date(year, time.December, 31, 23, 59, 59, 0, time.UTC))
 	}
 	return dates
 }
@@ -66,16 +128,92 @@ func generateWeekly(since, until string) []time.Time {
 	return dates
 }
 
+func generateISOWeekly(since, until string) []time.Time {
+	start, err := time.Parse("2006-01-02", since)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", until)
+	if err != nil {
+		return nil
+	}
+
+	var dates []time.Time
+	for cur := endOfISOWeek(start); !cur.After(endOfISOWeek(end)); cur = cur.AddDate(0, 0, 7) {
+		dates = append(dates, cur)
+	}
+	return dates
+}
+
+// endOfISOWeek returns 23:59:59 UTC on the Sunday ending d's ISO week.
+func endOfISOWeek(d time.Time) time.Time {
+	daysToSunday := (7 - int(d.Weekday())) % 7
+	sunday := d.AddDate(0, 0, daysToSunday)
+	return time.Date(sunday.Year(), sunday.Month(), sunday.Day(), 23, 59, 59, 0, time.UTC)
+}
+
+// AutoSince returns the since value that starts a repo's periods at its first
+// commit, for "--since auto". Monthly and quarterly periods start at the
+// commit's month (quarterly dates then cover its quarter), yearly ones at its
+// year.
+// Weekly periods keep the 7-day grid ending at until, so every repo's weeks
+// line up, and start at the first grid date on or after the commit's day.
+// ISOWeekly periods start at the ISO week of the commit.
+// It returns until when first is zero or after until.
+func AutoSince(first time.Time, until, interval string) string {
+	switch interval {
+	case "monthly", "quarterly":
+		if first.IsZero() || first.UTC().Format("2006-01") > until {
+			return until
+		}
+		return first.UTC().Format("2006-01")
+	case "yearly":
+		if first.IsZero() || first.UTC().Format("2006") > until {
+			return until
+		}
+		return first.UTC().Format("2006")
+	case "weekly":
+		end, err := time.Parse("2006-01-02", until)
+		if err != nil || first.IsZero() {
+			return until
+		}
+		f := first.UTC()
+		firstDay := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, time.UTC)
+		start := end
+		for !start.AddDate(0, 0, -7).Before(firstDay) {
+			start = start.AddDate(0, 0, -7)
+		}
+		return start.Format("2006-01-02")
+	case ISOWeekly:
+		if first.IsZero() || first.UTC().Format("2006-01-02") > until {
+			return until
+		}
+		return first.UTC().Format("2006-01-02")
+	default:
+		return until
+	}
+}
+
 // FormatPeriod formats a date according to the interval type.
 //
 // For "monthly": returns "2006-01" format.
 // For "weekly": returns "2006-01-02" format.
+// For "quarterly": returns the year and quarter, e.g. "2025-Q1".
+// For "yearly": returns "2006" format.
+// For ISOWeekly: returns the ISO year and week, e.g. "2025-W07".
 func FormatPeriod(d time.Time, interval string) string {
 	switch interval {
 	case "monthly":
 		return d.Format("2006-01")
 	case "weekly":
 		return d.Format("2006-01-02")
+	case "quarterly":
+		return fmt.Sprintf("%d-Q%d", d.Year(), (int(d.Month())-1)/3+1)
+	case "yearly":
+		return d.Format("2006")
+	case ISOWeekly:
+		year, week := d.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
 	default:
 		return d.Format(time.RFC3339)
 	}
@@ -85,6 +223,48 @@ func FormatPeriod(d time.Time, interval string) string {
 // finds the last commit whose Author.When is at or before that date.
 // Dates with no prior commits are omitted from the result map.
 func FindCommits(repo *git.Repository, dates []time.Time) (map[time.Time]plumbing.Hash, error) {
+	commits, err := logCommits(repo)
+	if err != nil {
+		return nil, err
+	}
+	return findInCommits(commits, dates), nil
+}
+
+// FirstCommit returns the earliest author date among the commits reachable
+// from HEAD.
+func FirstCommit(repo *git.Repository) (time.Time, error) {
+	commits, err := logCommits(repo)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var first time.Time
+	for _, c := range commits {
+		if first.IsZero() || c.Author.When.Before(first) {
+			first = c.Author.When
+		}
+	}
+	return first, nil
+}
+
+func findInCommits(commits []*object.Commit, dates []time.Time) map[time.Time]plumbing.Hash {
+	result := make(map[time.Time]plumbing.Hash)
+	for _, targetDate := range dates {
+		// Find the last commit at or before targetDate.
+		// Commits are newest-first, so the first commit we find
+		// with Author.When <= targetDate is the most recent one
+		// at or before that date.
+		for _, c := range commits {
+			if !c.Author.When.After(targetDate) {
+				result[targetDate] = c.Hash
+				break
+			}
+		}
+	}
+	return result
+}
+
+// logCommits lists the commits reachable from HEAD, newest first.
+func logCommits(repo *git.Repository) ([]*object.Commit, error) {
 	head, err := repo.Head()
 	if err != nil {
 		return nil, err
@@ -108,19 +288,5 @@ func FindCommits(repo *git.Repository, dates []time.Time) (map[time.Time]plumbin
 		return nil, err
 	}
 
-	result := make(map[time.Time]plumbing.Hash)
-	for _, targetDate := range dates {
-		// Find the last commit at or before targetDate.
-		// Commits are newest-first, so the first commit we find
-		// with Author.When <= targetDate is the most recent one
-		// at or before that date.
-		for _, c := range commits {
-			if !c.Author.When.After(targetDate) {
-				result[targetDate] = c.Hash
-				break
-			}
-		}
-	}
-
-	return result, nil
+	return commits, nil
 }
@@ -3,6 +3,7 @@ package history
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -44,6 +45,63 @@ func TestGenerateDatesMonthly(t *testing.T) {
 	}
 }
 
+func TestGenerateDatesQuarterly(t *testing.T) {
+	// since and until fall mid-quarter and span a year boundary.
+	dates := GenerateDates("2024-11", "2025-05", "quarterly")
+
+	expected := []string{"2024-12-31", "2025-03-31", "2025-06-30"}
+	if len(dates) != len(expected) {
+		t.Fatalf("expected %d dates, got %d", len(expected), len(dates))
+	}
+	for i, d := range dates {
+		if d.Format("2006-01-02") != expected[i] {
+			t.Errorf("date %d: expected %s, got %s", i, expected[i], d.Format("2006-01-02"))
+		}
+		if d.Hour() != 23 || d.Minute() != 59 || d.Second() != 59 {
+			t.Errorf("date %d: expected 23:59:59, got %02d:%02d:%02d", i, d.Hour(), d.Minute(), d.Second())
+		}
+		if d.Location() != time.UTC {
+			t.Errorf("date %d: expected UTC, got %v", i, d.Location())
+		}
+	}
+
+	var labels []string
+	for _, d := range dates {
+		labels = append(labels, FormatPeriod(d, "quarterly"))
+	}
+	if want := []string{"2024-Q4", "2025-Q1", "2025-Q2"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected labels %v, got %v", want, labels)
+	}
+}
+
+func TestGenerateDatesYearly(t *testing.T) {
+	dates := GenerateDates("2024", "2026", "yearly")
+
+	expected := []string{"2024-12-31", "2025-12-31", "2026-12-31"}
+	if len(dates) != len(expected) {
+		t.Fatalf("expected %d dates, got %d", len(expected), len(dates))
+	}
+	for i, d := range dates {
+		if d.Format("2006-01-02") != expected[i] {
+			t.Errorf("date %d: expected %s, got %s", i, expected[i], d.Format("2006-01-02"))
+		}
+		if d.Hour() != 23 || d.Minute() != 59 || d.Second() != 59 {
+			t.Errorf("date %d: expected 23:59:59, got %02d:%02d:%02d", i, d.Hour(), d.Minute(), d.Second())
+		}
+		if d.Location() != time.UTC {
+			t.Errorf("date %d: expected UTC, got %v", i, d.Location())
+		}
+	}
+
+	var labels []string
+	for _, d := range dates {
+		labels = append(labels, FormatPeriod(d, "yearly"))
+	}
+	if want := []string{"2024", "2025", "2026"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected labels %v, got %v", want, labels)
+	}
+}
+
 func TestGenerateDatesWeekly(t *testing.T) {
 	dates := GenerateDates("2025-01-01", "2025-01-22", "weekly")
 
@@ -65,6 +123,51 @@ func TestGenerateDatesWeekly(t *testing.T) {
 	}
 }
 
+func TestGenerateDatesISOWeekly(t *testing.T) {
+	// 2025-01-01 is a Wednesday in ISO week 2025-W01 (Mon 2024-12-30 to Sun 2025-01-05).
+	dates := GenerateDates("2025-01-01", "2025-01-22", ISOWeekly)
+
+	expected := []string{"2025-01-05", "2025-01-12", "2025-01-19", "2025-01-26"}
+	if len(dates) != len(expected) {
+		t.Fatalf("expected %d dates, got %d", len(expected), len(dates))
+	}
+	for i, d := range dates {
+		if d.Format("2006-01-02") != expected[i] {
+			t.Errorf("date %d: expected %s, got %s", i, expected[i], d.Format("2006-01-02"))
+		}
+		if d.Weekday() != time.Sunday {
+			t.Errorf("date %d: expected a Sunday, got %s", i, d.Weekday())
+		}
+		if d.Hour() != 23 || d.Minute() != 59 || d.Second() != 59 {
+			t.Errorf("date %d: expected 23:59:59, got %02d:%02d:%02d", i, d.Hour(), d.Minute(), d.Second())
+		}
+	}
+
+	var labels []string
+	for _, d := range dates {
+		labels = append(labels, FormatPeriod(d, ISOWeekly))
+	}
+	if want := []string{"2025-W01", "2025-W02", "2025-W03", "2025-W04"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("expected labels %v, got %v", want, labels)
+	}
+}
+
+func TestFormatPeriodISOWeeklyYearBoundary(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{time.Date(2024, 12, 29, 23, 59, 59, 0, time.UTC), "2024-W52"},
+		{time.Date(2026, 1, 4, 23, 59, 59, 0, time.UTC), "2026-W01"},
+		{time.Date(2021, 1, 3, 23, 59, 59, 0, time.UTC), "2020-W53"},
+	}
+	for _, tt := range tests {
+		if got := FormatPeriod(tt.date, ISOWeekly); got != tt.want {
+			t.Errorf("FormatPeriod(%s) = %q, want %q", tt.date.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
 func TestFormatPeriodMonthly(t *testing.T) {
 	d := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
 	result := FormatPeriod(d, "monthly")
@@ -195,4 +298,36 @@ func TestFindCommits(t *testing.T) {
 	if _, ok := earlyResult[earlyDate]; ok {
 		t.Error("expected no commit for date before any commits exist")
 	}
+
+	first, err := FirstCommit(repo)
+	if err != nil {
+		t.Fatalf("FirstCommit failed: %v", err)
+	}
+	if !first.Equal(commit1Time) {
+		t.Errorf("FirstCommit: expected %v, got %v", commit1Time, first)
+	}
+}
+
+func TestAutoSince(t *testing.T) {
+	first := time.Date(2025, 1, 10, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		until, interval string
+		want            string
+	}{
+		{"monthly starts at the first commit's month", "2025-06", "monthly", "2025-01"},
+		{"weekly keeps the grid ending at until", "2025-01-29", "weekly", "2025-01-15"},
+		{"weekly grid date on the commit day", "2025-01-24", "weekly", "2025-01-10"},
+		{"first commit after until", "2024-12", "monthly", "2024-12"},
+		{"iso weekly starts at the first commit's day", "2025-01-29", ISOWeekly, "2025-01-10"},
+		{"quarterly starts at the first commit's month", "2025-12", "quarterly", "2025-01"},
+		{"yearly starts at the first commit's year", "2026", "yearly", "2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AutoSince(first, tt.until, tt.interval); got != tt.want {
+				t.Errorf("AutoSince = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
@@ -1,33 +1,59 @@
 package license
 
 import (
+	"os"
+	"path"
+	"path/filepath"
+
 	"github.com/go-enry/go-license-detector/v4/licensedb"
 	"github.com/go-enry/go-license-detector/v4/licensedb/filer"
 )
 
 const confidenceThreshold = 0.85
 
+// altDirs are the directories searched, in order, when the repository root
+// has no license file.
+var altDirs = []string{".github", "docs", "doc", "LICENSES", "legal"}
+
 // Detect scans the directory for license files and returns the SPDX
-// identifier of the most confident match, or empty string if none found.
-func Detect(dir string) string {
+// identifier of the most confident match and the slash-separated path of the
+// file it came from, relative to dir. The root is searched first, then
+// altDirs. Both are empty if no license is found.
+func Detect(dir string) (id, file string) {
+	if id, file := detectIn(dir); id != "" {
+		return id, file
+	}
+	for _, sub := range altDirs {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			continue
+		}
+		if id, file := detectIn(filepath.Join(dir, sub)); id != "" {
+			return id, path.Join(sub, file)
+		}
+	}
+	return "", ""
+}
+
+// detectIn runs license detection on the files directly in dir.
+func detectIn(dir string) (id, file string) {
 	f, err := filer.FromDirectory(dir)
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
 	results, err := licensedb.Detect(f)
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
-	var bestID string
 	var bestConf float32
-	for id, match := range results {
+	for candidate, match := range results {
 		if match.Confidence > bestConf && match.Confidence >= confidenceThreshold {
 			bestConf = match.Confidence
-			bestID = id
+			id = candidate
+			file = match.File
 		}
 	}
 
-	return bestID
+	return id, file
 }
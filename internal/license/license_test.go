@@ -8,9 +8,7 @@ import (
 	"github.com/dsablic/codemium/internal/license"
 )
 
-func TestDetectMIT(t *testing.T) {
-	dir := t.TempDir()
-	mit := `MIT License
+const mit = `MIT License
 
 Copyright (c) 2024 Example
 
@@ -32,20 +30,40 @@ LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
 OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 SOFTWARE.
 `
+
+func TestDetectMIT(t *testing.T) {
+	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "LICENSE"), []byte(mit), 0644)
 
-	result := license.Detect(dir)
+	result, file := license.Detect(dir)
+	if result != "MIT" {
+		t.Errorf("expected MIT, got %q", result)
+	}
+	if file != "LICENSE" {
+		t.Errorf("expected LICENSE, got %q", file)
+	}
+}
+
+func TestDetectAlternateDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.WriteFile(filepath.Join(dir, "docs", "LICENSE.md"), []byte(mit), 0644)
+
+	result, file := license.Detect(dir)
 	if result != "MIT" {
 		t.Errorf("expected MIT, got %q", result)
 	}
+	if file != "docs/LICENSE.md" {
+		t.Errorf("expected docs/LICENSE.md, got %q", file)
+	}
 }
 
 func TestDetectNoLicense(t *testing.T) {
 	dir := t.TempDir()
 	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
 
-	result := license.Detect(dir)
-	if result != "" {
-		t.Errorf("expected empty string, got %q", result)
+	result, file := license.Detect(dir)
+	if result != "" || file != "" {
+		t.Errorf("expected empty strings, got %q, %q", result, file)
 	}
 }
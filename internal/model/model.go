@@ -1,11 +1,14 @@
 // internal/model/model.go
 package model
 
+import "time"
+
 // Repo represents a repository from a provider.
 type Repo struct {
 	Name          string
 	Slug          string
 	Project       string
+	Owner         string // workspace or group the repo was listed from (set when several are analyzed)
 	URL           string
 	CloneURL      string
 	DownloadURL   string // tarball download URL (used when git clone isn't available)
@@ -13,19 +16,28 @@ type Repo struct {
 	DefaultBranch string
 	Archived      bool
 	Fork          bool
+	ParentURL     string    // clone URL of the repo a fork was made from (empty if unknown)
+	LastActivity  time.Time // last push/activity reported by the repo listing (zero if unavailable)
+	SizeKB        int64     // repository size reported by the listing, in KiB (zero if unavailable)
+	HeadSHA       string    // default-branch HEAD commit, when looked up (--cache-analysis)
 }
 
 // LanguageStats holds code statistics for a single language.
 type LanguageStats struct {
-	Name       string `json:"name"`
-	Files      int64  `json:"files"`
-	Lines      int64  `json:"lines"`
-	Code       int64  `json:"code"`
-	Comments   int64  `json:"comments"`
-	Blanks     int64  `json:"blanks"`
-	Complexity int64  `json:"complexity"`
+	Name        string  `json:"name"`
+	Files       int64   `json:"files"`
+	Lines       int64   `json:"lines"`
+	Code        int64   `json:"code"`
+	Comments    int64   `json:"comments"`
+	Blanks      int64   `json:"blanks"`
+	Complexity  int64   `json:"complexity"`
+	CodePercent float64 `json:"code_percent,omitempty"` // share of the enclosing total code (0-100)
 }
 
+// DocumentationLanguage is the pseudo-language name the analyzer records
+// files matched by --doc-extensions under.
+const DocumentationLanguage = "Documentation"
+
 // Stats holds aggregate code statistics.
 type Stats struct {
 	Repos         int   `json:"repos,omitempty"`
@@ -36,22 +48,44 @@ type Stats struct {
 	Blanks        int64 `json:"blanks"`
 	Complexity    int64 `json:"complexity"`
 	FilteredFiles int64 `json:"filtered_files,omitempty"`
+	FilteredRepos int   `json:"filtered_repos,omitempty"` // below --min-code, left out of Repositories
 }
 
 // RepoStats holds the analysis results for a single repository.
 type RepoStats struct {
-	Repository    string             `json:"repository"`
-	Project       string             `json:"project,omitempty"`
-	Provider      string             `json:"provider"`
-	URL           string             `json:"url"`
-	License       string             `json:"license,omitempty"`
-	Languages     []LanguageStats    `json:"languages"`
-	Totals        Stats              `json:"totals"`
-	FilteredFiles int64              `json:"filtered_files,omitempty"`
-	Churn         *ChurnStats        `json:"churn,omitempty"`
-	AIEstimate    *AIEstimate        `json:"ai_estimate,omitempty"`
-	Health        *RepoHealth        `json:"health,omitempty"`
-	HealthDetails *RepoHealthDetails `json:"health_details,omitempty"`
+	Repository                string             `json:"repository"`
+	Project                   string             `json:"project,omitempty"`
+	Owner                     string             `json:"owner,omitempty"`            // workspace or group, set when several are analyzed
+	Area                      string             `json:"area,omitempty"`             // from the repo's own .codemium.yaml
+	LastCommitDate            string             `json:"last_commit_date,omitempty"` // RFC 3339, copied from Health when --health ran
+	Provider                  string             `json:"provider"`
+	URL                       string             `json:"url"`
+	License                   string             `json:"license,omitempty"`
+	LicenseFile               string             `json:"license_file,omitempty"`
+	RepoSizeKB                int64              `json:"repo_size_kb,omitempty"` // provider-reported size (GitHub, GitLab)
+	Languages                 []LanguageStats    `json:"languages"`
+	Totals                    Stats              `json:"totals"`
+	FilteredFiles             int64              `json:"filtered_files,omitempty"`
+	TestFiles                 int64              `json:"test_files,omitempty"` // with --split-tests, not in Languages/Totals
+	TestCode                  int64              `json:"test_code,omitempty"`
+	TopLevelDirs              int                `json:"top_level_dirs,omitempty"`
+	Structure                 string             `json:"structure,omitempty"`
+	IsInfraOnly               bool               `json:"is_infra_only,omitempty"` // only config/markup languages (YAML, HCL, Dockerfile, ...)
+	ForkParent                string             `json:"fork_parent,omitempty"`   // set when only the fork's divergence was counted
+	AnalyzedRef               string             `json:"analyzed_ref,omitempty"`  // release tag, or HEAD when it has none (--at-latest-tag)
+	LargeFiles                []LargeFile        `json:"large_files,omitempty"`
+	SkippedFiles              []SkippedFile      `json:"skipped_files,omitempty"` // capped sample (--trace-skips)
+	SkippedTotal              int64              `json:"skipped_total,omitempty"`
+	OpenIssues                *int               `json:"open_issues,omitempty"`
+	ConventionalCommitPercent *float64           `json:"conventional_commit_percent,omitempty"`
+	CommitCount               *int               `json:"commit_count,omitempty"`           // commits listed, capped by --ai-commit-limit (--commit-counts)
+	CommitCountTruncated      bool               `json:"commit_count_truncated,omitempty"` // the listing hit the cap, so the count is a lower bound
+	CoAuthorship              []CoAuthorPair     `json:"co_authorship,omitempty"`
+	ActivityHeatmap           *ActivityHeatmap   `json:"activity_heatmap,omitempty"`
+	Churn                     *ChurnStats        `json:"churn,omitempty"`
+	AIEstimate                *AIEstimate        `json:"ai_estimate,omitempty"`
+	Health                    *RepoHealth        `json:"health,omitempty"`
+	HealthDetails             *RepoHealthDetails `json:"health_details,omitempty"`
 }
 
 // RepoError records a repository that failed to process.
@@ -60,6 +94,20 @@ type RepoError struct {
 	Error      string `json:"error"`
 }
 
+// LargeFile is a file at or above the --large-file-size threshold, a
+// candidate for Git LFS.
+type LargeFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"` // bytes
+}
+
+// SkippedFile is a file (or vendored directory) the analyzer left out of
+// the counts, and why.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
 // FileChurn holds churn metrics for a single file.
 type FileChurn struct {
 	Path       string  `json:"path"`
@@ -68,13 +116,54 @@ type FileChurn struct {
 	Deletions  int64   `json:"deletions"`
 	Complexity int64   `json:"complexity,omitempty"`
 	Hotspot    float64 `json:"hotspot,omitempty"`
+	Owner      string  `json:"owner,omitempty"`       // dominant author (--code-ownership)
+	OwnerShare float64 `json:"owner_share,omitempty"` // owner's share of Changes (0-100)
+}
+
+// FileCoupling records two files that changed in the same commits. FileA is
+// the one of the pair with fewer changes, and Confidence (0-1) is the share
+// of its changes that also touched FileB.
+type FileCoupling struct {
+	FileA      string  `json:"file_a"`
+	FileB      string  `json:"file_b"`
+	CoChanges  int     `json:"co_changes"`
+	Confidence float64 `json:"confidence"`
+}
+
+// AuthorChurn holds one author's share of a repository's churn: the commits
+// with file stats and the lines they added and deleted.
+type AuthorChurn struct {
+	Author    string `json:"author"`
+	Commits   int    `json:"commits"`
+	Additions int64  `json:"additions"`
+	Deletions int64  `json:"deletions"`
+}
+
+// CategoryChurn holds churn totals for one file category (code, test, docs,
+// config, other).
+type CategoryChurn struct {
+	Files     int   `json:"files"`
+	Changes   int64 `json:"changes"`
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
 }
 
 // ChurnStats holds code churn and hotspot data for a repository.
 type ChurnStats struct {
-	TotalCommits int64       `json:"total_commits"`
-	TopFiles     []FileChurn `json:"top_files"`
-	Hotspots     []FileChurn `json:"hotspots,omitempty"`
+	TotalCommits int64                    `json:"total_commits"`
+	TopFiles     []FileChurn              `json:"top_files"`
+	Hotspots     []FileChurn              `json:"hotspots,omitempty"`
+	ByCategory   map[string]CategoryChurn `json:"by_category,omitempty"`
+	Couplings    []FileCoupling           `json:"couplings,omitempty"`
+	AuthorChurn  []AuthorChurn            `json:"author_churn,omitempty"`
+
+	// StatsUnavailable is set when every changed file came back with 0
+	// additions and 0 deletions, so only change counts are meaningful.
+	StatsUnavailable bool `json:"stats_unavailable,omitempty"`
+
+	// FileStatsUnavailable is set when the provider can list commits but not
+	// their changed files, so only TotalCommits is known.
+	FileStatsUnavailable bool `json:"file_stats_unavailable,omitempty"`
 }
 
 // AISignal represents why a commit was flagged as AI-authored.
@@ -105,6 +194,14 @@ type AIEstimate struct {
 	AIAdditions     int64      `json:"ai_additions"`
 	AdditionPercent float64    `json:"addition_percent"`
 	Details         []AICommit `json:"details,omitempty"`
+
+	// SignalCounts counts AI commits by detection signal across repositories
+	// (report level only). A commit with several signals counts once for each.
+	SignalCounts map[AISignal]int64 `json:"signal_counts,omitempty"`
+
+	// AdditionsUnavailable is set when the provider returned 0/0 stats for
+	// every AI commit, so AIAdditions is unknown rather than zero.
+	AdditionsUnavailable bool `json:"additions_unavailable,omitempty"`
 }
 
 // HealthCategory classifies a repository's activity level.
@@ -131,6 +228,7 @@ type RepoHealthDetails struct {
 	ChurnByWindow   map[string]WindowChurnStats `json:"churn_by_window,omitempty"`
 	BusFactor       float64                     `json:"bus_factor"`
 	VelocityTrend   float64                     `json:"velocity_trend"`
+	VelocityLabel   string                      `json:"velocity_label,omitempty"` // accelerating, steady, or slowing
 }
 
 // WindowChurnStats holds code churn metrics for a time window.
@@ -158,9 +256,12 @@ type HealthSummary struct {
 
 // Filters records what filters were applied to the analysis.
 type Filters struct {
-	Projects []string `json:"projects,omitempty"`
-	Repos    []string `json:"repos,omitempty"`
-	Exclude  []string `json:"exclude,omitempty"`
+	Projects        []string `json:"projects,omitempty"`
+	Repos           []string `json:"repos,omitempty"`
+	Exclude         []string `json:"exclude,omitempty"`
+	ExcludeProjects []string `json:"exclude_projects,omitempty"`
+	ChangedSince    string   `json:"changed_since,omitempty"`
+	MinCode         int64    `json:"min_code,omitempty"`
 }
 
 // PeriodSnapshot holds stats for all repos at a single point in time.
@@ -169,6 +270,9 @@ type PeriodSnapshot struct {
 	Repositories []RepoStats     `json:"repositories"`
 	Totals       Stats           `json:"totals"`
 	ByLanguage   []LanguageStats `json:"by_language"`
+	// NotYetCreated lists the repositories with no commit at or before the
+	// period, as opposed to ones that existed but had no code.
+	NotYetCreated []string `json:"not_yet_created,omitempty"`
 }
 
 // TrendsReport is the top-level output for historical trends.
@@ -181,22 +285,116 @@ type TrendsReport struct {
 	Since        string           `json:"since"`
 	Until        string           `json:"until"`
 	Interval     string           `json:"interval"`
+	WeekAnchor   string           `json:"week_anchor,omitempty"` // "iso" when weekly periods follow ISO weeks
 	Periods      []string         `json:"periods"`
 	Snapshots    []PeriodSnapshot `json:"snapshots"`
 	Errors       []RepoError      `json:"errors,omitempty"`
+
+	// RepoSince maps each repository to its first period when Since is
+	// "auto", since coverage then differs across repos.
+	RepoSince map[string]string `json:"repo_since,omitempty"`
+}
+
+// PhaseTiming records the wall-clock duration of one analysis phase.
+type PhaseTiming struct {
+	Phase   string  `json:"phase"`
+	Seconds float64 `json:"seconds"`
+}
+
+// Timing records where an analysis run spent its time.
+type Timing struct {
+	TotalSeconds float64       `json:"total_seconds"`
+	Phases       []PhaseTiming `json:"phases"`
 }
 
 // Report is the top-level output structure.
 type Report struct {
-	GeneratedAt   string          `json:"generated_at"`
-	Provider      string          `json:"provider"`
-	Workspace     string          `json:"workspace,omitempty"`
-	Organization  string          `json:"organization,omitempty"`
-	Filters       Filters         `json:"filters"`
-	Repositories  []RepoStats     `json:"repositories"`
-	Totals        Stats           `json:"totals"`
-	ByLanguage    []LanguageStats `json:"by_language"`
-	Errors        []RepoError     `json:"errors,omitempty"`
-	AIEstimate    *AIEstimate     `json:"ai_estimate,omitempty"`
-	HealthSummary *HealthSummary  `json:"health_summary,omitempty"`
+	GeneratedAt        string              `json:"generated_at"`
+	Provider           string              `json:"provider"`
+	Workspace          string              `json:"workspace,omitempty"`
+	Organization       string              `json:"organization,omitempty"`
+	Filters            Filters             `json:"filters"`
+	Repositories       []RepoStats         `json:"repositories"`
+	Totals             Stats               `json:"totals"`
+	ByLanguage         []LanguageStats     `json:"by_language"`
+	Errors             []RepoError         `json:"errors,omitempty"`
+	AIEstimate         *AIEstimate         `json:"ai_estimate,omitempty"`
+	HealthSummary      *HealthSummary      `json:"health_summary,omitempty"`
+	Timing             *Timing             `json:"timing,omitempty"`
+	ComplexityWarnings []ComplexityWarning `json:"complexity_warnings,omitempty"`
+	CoAuthorship       []CoAuthorPair      `json:"co_authorship,omitempty"`
+	RiskRepos          []RiskRepo          `json:"risk_repos,omitempty"`
+	ActivityHeatmap    *ActivityHeatmap    `json:"activity_heatmap,omitempty"`
+	Distribution       *Distribution       `json:"distribution,omitempty"`
+	RunConfig          *RunConfig          `json:"run_config,omitempty"`
+}
+
+// RunConfig records how a report was generated, so it can be audited or
+// reproduced: the target, the phases that ran, the limits and thresholds
+// they used, and every flag set on the command line.
+type RunConfig struct {
+	Provider             string            `json:"provider"`
+	Workspace            string            `json:"workspace,omitempty"`
+	Organization         string            `json:"organization,omitempty"`
+	Phases               []string          `json:"phases"`
+	Concurrency          int               `json:"concurrency"`
+	APIConcurrency       int               `json:"api_concurrency"`
+	AICommitLimit        int               `json:"ai_commit_limit,omitempty"`
+	ChurnLimit           int               `json:"churn_limit,omitempty"`
+	VelocityBand         float64           `json:"velocity_band,omitempty"`
+	HealthWindows        []int             `json:"health_windows,omitempty"`
+	HealthActiveDays     int               `json:"health_active_days,omitempty"`
+	HealthMaintainedDays int               `json:"health_maintained_days,omitempty"`
+	ComplexityThresholds []string          `json:"complexity_thresholds,omitempty"`
+	Flags                map[string]string `json:"flags,omitempty"`
+}
+
+// Distribution describes how code and complexity are spread over the
+// repositories in a report.
+type Distribution struct {
+	Repos      int         `json:"repos"`
+	Code       Percentiles `json:"code"`
+	Complexity Percentiles `json:"complexity"`
+}
+
+// Percentiles holds nearest-rank percentiles of a per-repository value.
+type Percentiles struct {
+	P50 int64 `json:"p50"`
+	P90 int64 `json:"p90"`
+	P99 int64 `json:"p99"`
+}
+
+// ActivityHeatmap counts commits by day of week (Counts[0] is Sunday, as in
+// time.Weekday) and hour of day.
+type ActivityHeatmap struct {
+	Commits int        `json:"commits"`
+	Counts  [7][24]int `json:"counts"`
+}
+
+// RiskRepo is an abandoned repository that still holds code, a
+// decommission candidate. Score is Code × DaysSinceCommit.
+type RiskRepo struct {
+	Repository      string `json:"repository"`
+	Code            int64  `json:"code"`
+	DaysSinceCommit int    `json:"days_since_commit"`
+	Score           int64  `json:"score"`
+}
+
+// CoAuthorPair counts the commits two people worked on together, from
+// Co-authored-by trailers. AuthorA sorts before AuthorB.
+type CoAuthorPair struct {
+	AuthorA string `json:"author_a"`
+	AuthorB string `json:"author_b"`
+	Commits int    `json:"commits"`
+}
+
+// ComplexityWarning flags a repository, or one language or file within it,
+// whose cyclomatic complexity exceeds the configured threshold. Language and
+// Path are empty for repository-level warnings.
+type ComplexityWarning struct {
+	Repository string `json:"repository"`
+	Language   string `json:"language,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Complexity int64  `json:"complexity"`
+	Threshold  int64  `json:"threshold"`
 }
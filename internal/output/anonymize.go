@@ -0,0 +1,92 @@
+// internal/output/anonymize.go
+package output
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/dsablic/codemium/internal/aidetect"
+	"github.com/dsablic/codemium/internal/health"
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// Anonymize returns a copy of report with author identities (AI commit
+// authors, co-authorship pairs, churn file owners and per-author churn)
+// replaced by pseudonyms such as "author-3f9a1c0b2e". A pseudonym is an HMAC of the author's
+// normalized identity (lowercased email) keyed by salt, so the same person
+// maps to the same pseudonym across the whole report, while a fresh salt per
+// run keeps pseudonyms from being matched between reports or reversed by
+// hashing known emails. Bots and AI tools are kept as-is since they aren't personal data.
+func Anonymize(report model.Report, salt []byte) model.Report {
+	a := anonymizer{salt: salt}
+
+	report.Repositories = append([]model.RepoStats(nil), report.Repositories...)
+	for i := range report.Repositories {
+		r := &report.Repositories[i]
+		if r.AIEstimate != nil && len(r.AIEstimate.Details) > 0 {
+			est := *r.AIEstimate
+			est.Details = append([]model.AICommit(nil), est.Details...)
+			for j := range est.Details {
+				est.Details[j].Author = a.pseudonym(est.Details[j].Author)
+			}
+			r.AIEstimate = &est
+		}
+		r.CoAuthorship = a.pairs(r.CoAuthorship)
+		if r.Churn != nil && (len(r.Churn.TopFiles) > 0 || len(r.Churn.AuthorChurn) > 0) {
+			cs := *r.Churn
+			cs.TopFiles = append([]model.FileChurn(nil), cs.TopFiles...)
+			for j := range cs.TopFiles {
+				cs.TopFiles[j].Owner = a.pseudonym(cs.TopFiles[j].Owner)
+			}
+			cs.AuthorChurn = append([]model.AuthorChurn(nil), cs.AuthorChurn...)
+			for j := range cs.AuthorChurn {
+				cs.AuthorChurn[j].Author = a.pseudonym(cs.AuthorChurn[j].Author)
+			}
+			r.Churn = &cs
+		}
+	}
+	report.CoAuthorship = a.pairs(report.CoAuthorship)
+	return report
+}
+
+type anonymizer struct {
+	salt []byte
+}
+
+// pseudonym returns the stable pseudonym for an author identity.
+func (a anonymizer) pseudonym(author string) string {
+	if author == "" || aidetect.IsAITool(author) || aidetect.IsBotAuthor(author) {
+		return author
+	}
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte((*health.AuthorMap)(nil).Normalize(author)))
+	return "author-" + hex.EncodeToString(mac.Sum(nil))[:10]
+}
+
+// pairs anonymizes co-authorship pairs, keeping AuthorA before AuthorB and
+// the commit-count ordering of the input.
+func (a anonymizer) pairs(pairs []model.CoAuthorPair) []model.CoAuthorPair {
+	if len(pairs) == 0 {
+		return pairs
+	}
+	out := make([]model.CoAuthorPair, len(pairs))
+	for i, p := range pairs {
+		x, y := a.pseudonym(p.AuthorA), a.pseudonym(p.AuthorB)
+		if y < x {
+			x, y = y, x
+		}
+		out[i] = model.CoAuthorPair{AuthorA: x, AuthorB: y, Commits: p.Commits}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Commits != out[j].Commits {
+			return out[i].Commits > out[j].Commits
+		}
+		if out[i].AuthorA != out[j].AuthorA {
+			return out[i].AuthorA < out[j].AuthorA
+		}
+		return out[i].AuthorB < out[j].AuthorB
+	})
+	return out
+}
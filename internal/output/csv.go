@@ -0,0 +1,75 @@
+// internal/output/csv.go
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// WriteCSV writes one row per repository, for loading a report into a
+// spreadsheet or BI tool. The health and AI columns are only present when at
+// least one repository carries that data; repositories without it leave the
+// cells empty.
+func WriteCSV(w io.Writer, report model.Report) error {
+	var hasHealth, hasAI bool
+	for _, r := range report.Repositories {
+		hasHealth = hasHealth || r.Health != nil
+		hasAI = hasAI || r.AIEstimate != nil
+	}
+
+	header := []string{"repository", "project", "provider", "license", "files", "code", "comments", "blanks", "complexity"}
+	if hasHealth {
+		header = append(header, "health", "days_since_commit")
+	}
+	if hasAI {
+		header = append(header, "ai_commit_percent", "ai_additions")
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, r := range report.Repositories {
+		row := []string{
+			r.Repository,
+			r.Project,
+			r.Provider,
+			r.License,
+			strconv.FormatInt(r.Totals.Files, 10),
+			strconv.FormatInt(r.Totals.Code, 10),
+			strconv.FormatInt(r.Totals.Comments, 10),
+			strconv.FormatInt(r.Totals.Blanks, 10),
+			strconv.FormatInt(r.Totals.Complexity, 10),
+		}
+		if hasHealth {
+			category, days := "", ""
+			if r.Health != nil {
+				category = string(r.Health.Category)
+				if r.Health.Category != model.HealthFailed {
+					days = strconv.Itoa(r.Health.DaysSinceCommit)
+				}
+			}
+			row = append(row, category, days)
+		}
+		if hasAI {
+			percent, additions := "", ""
+			if r.AIEstimate != nil {
+				percent = strconv.FormatFloat(r.AIEstimate.CommitPercent, 'f', 1, 64)
+				if !r.AIEstimate.AdditionsUnavailable {
+					additions = strconv.FormatInt(r.AIEstimate.AIAdditions, 10)
+				}
+			}
+			row = append(row, percent, additions)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
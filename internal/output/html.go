@@ -0,0 +1,171 @@
+// internal/output/html.go
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// htmlHealthRow is one row of the HTML health table.
+type htmlHealthRow struct {
+	Label   string
+	Summary model.HealthCategorySummary
+	Failed  bool
+}
+
+// htmlReport is the data the HTML template renders.
+type htmlReport struct {
+	model.Report
+	Languages []model.LanguageStats
+	HasHealth bool
+	HasAI     bool
+	Health    []htmlHealthRow
+	Churn     []model.RepoStats
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct":   func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"ratio": func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Code Statistics Report</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;margin:2rem auto;max-width:72rem;padding:0 1rem;color:#222}
+h1,h2,h3,h4{font-weight:600}
+table{border-collapse:collapse;margin:0 0 1.5rem}
+th,td{border:1px solid #ddd;padding:.3rem .6rem;text-align:left}
+th{background:#f4f4f4}
+td.n{text-align:right;font-variant-numeric:tabular-nums}
+.meta{color:#555}
+</style>
+</head>
+<body>
+<h1>Code Statistics Report</h1>
+<p class="meta">Provider: {{.Provider}}{{if .Workspace}} &middot; Workspace: {{.Workspace}}{{end}}{{if .Organization}} &middot; Organization: {{.Organization}}{{end}} &middot; Generated: {{.GeneratedAt}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Metric</th><th>Value</th></tr>
+<tr><td>Repositories</td><td class="n">{{.Totals.Repos}}</td></tr>
+<tr><td>Files</td><td class="n">{{.Totals.Files}}</td></tr>
+<tr><td>Lines</td><td class="n">{{.Totals.Lines}}</td></tr>
+<tr><td>Code</td><td class="n">{{.Totals.Code}}</td></tr>
+<tr><td>Comments</td><td class="n">{{.Totals.Comments}}</td></tr>
+<tr><td>Blanks</td><td class="n">{{.Totals.Blanks}}</td></tr>
+<tr><td>Complexity</td><td class="n">{{.Totals.Complexity}}</td></tr>
+{{- if .AIEstimate}}
+<tr><td>AI Commits</td><td class="n">{{.AIEstimate.AICommits}} of {{.AIEstimate.TotalCommits}} ({{pct .AIEstimate.CommitPercent}})</td></tr>
+{{- end}}
+</table>
+{{if .Health}}
+<h2>Repository Health</h2>
+<table>
+<tr><th>Category</th><th>Repos</th><th>Code Lines</th><th>% of Code</th></tr>
+{{- range .Health}}
+<tr><td>{{.Label}}</td><td class="n">{{.Summary.Repos}}</td><td class="n">{{.Summary.Code}}</td><td class="n">{{if .Failed}}&mdash;{{else}}{{pct .Summary.CodePercent}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{end}}
+<h2>Languages</h2>
+<table>
+<tr><th>Language</th><th>Files</th><th>Code</th><th>% of Code</th><th>Comments</th><th>Blanks</th><th>Complexity</th></tr>
+{{- range .Languages}}
+<tr><td>{{.Name}}</td><td class="n">{{.Files}}</td><td class="n">{{.Code}}</td><td class="n">{{pct .CodePercent}}</td><td class="n">{{.Comments}}</td><td class="n">{{.Blanks}}</td><td class="n">{{.Complexity}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Repositories</h2>
+<table>
+<tr><th>Repository</th><th>Project</th><th>License</th><th>Files</th><th>Code</th><th>Comments</th><th>Complexity</th>{{if .HasHealth}}<th>Health</th>{{end}}{{if .HasAI}}<th>AI Commits %</th>{{end}}</tr>
+{{- range .Repositories}}
+<tr><td>{{if .URL}}<a href="{{.URL}}">{{.Repository}}</a>{{else}}{{.Repository}}{{end}}</td><td>{{.Project}}</td><td>{{.License}}</td><td class="n">{{.Totals.Files}}</td><td class="n">{{.Totals.Code}}</td><td class="n">{{.Totals.Comments}}</td><td class="n">{{.Totals.Complexity}}</td>
+{{- if $.HasHealth}}<td>{{with .Health}}{{.Category}}{{end}}</td>{{end}}
+{{- if $.HasAI}}<td class="n">{{with .AIEstimate}}{{pct .CommitPercent}}{{end}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{if .Churn}}
+<h2>Code Churn</h2>
+{{- range .Churn}}
+<h3>{{.Repository}}</h3>
+<p>Commits scanned: {{.Churn.TotalCommits}}</p>
+{{- if .Churn.TopFiles}}
+<table>
+<tr><th>File</th><th>Changes</th><th>Additions</th><th>Deletions</th></tr>
+{{- range .Churn.TopFiles}}
+<tr><td>{{.Path}}</td><td class="n">{{.Changes}}</td><td class="n">{{.Additions}}</td><td class="n">{{.Deletions}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Churn.Hotspots}}
+<h4>Hotspots</h4>
+<table>
+<tr><th>File</th><th>Changes</th><th>Complexity</th><th>Hotspot Score</th></tr>
+{{- range .Churn.Hotspots}}
+<tr><td>{{.Path}}</td><td class="n">{{.Changes}}</td><td class="n">{{.Complexity}}</td><td class="n">{{printf "%.0f" .Hotspot}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Churn.Couplings}}
+<h4>Change Coupling</h4>
+<table>
+<tr><th>File A</th><th>File B</th><th>Co-changes</th><th>Confidence</th></tr>
+{{- range .Churn.Couplings}}
+<tr><td>{{.FileA}}</td><td>{{.FileB}}</td><td class="n">{{.CoChanges}}</td><td class="n">{{ratio .Confidence}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Churn.AuthorChurn}}
+<h4>Top Authors</h4>
+<table>
+<tr><th>Author</th><th>Commits</th><th>Additions</th><th>Deletions</th></tr>
+{{- range .Churn.AuthorChurn}}
+<tr><td>{{.Author}}</td><td class="n">{{.Commits}}</td><td class="n">{{.Additions}}</td><td class="n">{{.Deletions}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a single self-contained HTML page (inline
+// CSS, no external assets) with the summary, health, languages, repositories
+// and churn sections of the markdown report. Names and URLs are escaped by
+// html/template.
+func WriteHTML(w io.Writer, report model.Report) error {
+	data := htmlReport{Report: report}
+	for _, lang := range report.ByLanguage {
+		if lang.Code > 0 {
+			data.Languages = append(data.Languages, lang)
+		}
+	}
+	for _, repo := range report.Repositories {
+		if repo.Health != nil {
+			data.HasHealth = true
+		}
+		if repo.AIEstimate != nil {
+			data.HasAI = true
+		}
+		if repo.Churn != nil {
+			data.Churn = append(data.Churn, repo)
+		}
+	}
+	if s := report.HealthSummary; s != nil {
+		th := healthThresholds(report)
+		data.Health = []htmlHealthRow{
+			{Label: fmt.Sprintf("Active (<%dd)", th.Active), Summary: s.Active},
+			{Label: fmt.Sprintf("Maintained (%d-%dd)", th.Active, th.Maintained), Summary: s.Maintained},
+			{Label: fmt.Sprintf("Abandoned (>%dd)", th.Maintained), Summary: s.Abandoned},
+		}
+		if s.Failed.Repos > 0 {
+			data.Health = append(data.Health, htmlHealthRow{Label: "Failed (error)", Summary: s.Failed, Failed: true})
+		}
+	}
+	return htmlTemplate.Execute(w, data)
+}
@@ -8,16 +8,64 @@ import (
 	"github.com/dsablic/codemium/internal/model"
 )
 
-// WriteJSON writes the report as pretty-printed JSON to w.
-func WriteJSON(w io.Writer, report model.Report) error {
+// JSONOption configures WriteJSON and WriteTrendsJSON.
+type JSONOption func(*jsonConfig)
+
+type jsonConfig struct {
+	compact     bool
+	summaryOnly bool
+}
+
+// Compact writes JSON on a single line without indentation, which is much
+// smaller for large, machine-consumed reports.
+func Compact() JSONOption {
+	return func(c *jsonConfig) {
+		c.compact = true
+	}
+}
+
+// SummaryOnly makes WriteJSON leave out the repositories array, keeping the
+// envelope, totals and report-level aggregates. WriteTrendsJSON ignores it.
+func SummaryOnly() JSONOption {
+	return func(c *jsonConfig) {
+		c.summaryOnly = true
+	}
+}
+
+// summaryReport shadows the embedded report's repositories with an empty,
+// omitted field.
+type summaryReport struct {
+	model.Report
+	Repositories []model.RepoStats `json:"repositories,omitempty"`
+}
+
+// newJSONEncoder returns an encoder that pretty-prints unless Compact is given.
+func newJSONEncoder(w io.Writer, cfg jsonConfig) *json.Encoder {
 	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(report)
+	if !cfg.compact {
+		enc.SetIndent("", "  ")
+	}
+	return enc
+}
+
+func applyJSONOptions(opts []JSONOption) jsonConfig {
+	var cfg jsonConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WriteJSON writes the report as pretty-printed JSON to w.
+func WriteJSON(w io.Writer, report model.Report, opts ...JSONOption) error {
+	cfg := applyJSONOptions(opts)
+	if cfg.summaryOnly {
+		return newJSONEncoder(w, cfg).Encode(summaryReport{Report: report})
+	}
+	return newJSONEncoder(w, cfg).Encode(report)
 }
 
 // WriteTrendsJSON writes the trends report as pretty-printed JSON to w.
-func WriteTrendsJSON(w io.Writer, report model.TrendsReport) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(report)
+func WriteTrendsJSON(w io.Writer, report model.TrendsReport, opts ...JSONOption) error {
+	return newJSONEncoder(w, applyJSONOptions(opts)).Encode(report)
 }
@@ -4,12 +4,24 @@ package output
 import (
 	"fmt"
 	"io"
+	"math"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dsablic/codemium/internal/activity"
+	"github.com/dsablic/codemium/internal/churn"
+	"github.com/dsablic/codemium/internal/health"
 	"github.com/dsablic/codemium/internal/model"
 )
 
+// maxCoAuthorPairs caps the Co-Authorship table in markdown reports.
+const maxCoAuthorPairs = 20
+
+// maxRiskRepos caps the Decommission Candidates table in markdown reports.
+const maxRiskRepos = 20
+
 func capitalize(s string) string {
 	if s == "" {
 		return s
@@ -17,8 +29,102 @@ func capitalize(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
 
+// healthThresholds returns the thresholds the report's health phase used,
+// or the defaults for reports that don't record them.
+func healthThresholds(report model.Report) health.Thresholds {
+	th := health.DefaultThresholds()
+	if rc := report.RunConfig; rc != nil && rc.HealthActiveDays > 0 && rc.HealthMaintainedDays > 0 {
+		th = health.Thresholds{Active: rc.HealthActiveDays, Maintained: rc.HealthMaintainedDays}
+	}
+	return th
+}
+
+// projectTotal aggregates repository totals for one project.
+type projectTotal struct {
+	name string
+	model.Stats
+}
+
+// projectTotals groups repositories by Project and sums their totals, sorted
+// by code descending. Repos without a project are grouped under an em-dash.
+// It returns nil when no repository has a project.
+func projectTotals(repos []model.RepoStats) []projectTotal {
+	byName := map[string]*projectTotal{}
+	var hasProject bool
+	for _, r := range repos {
+		name := r.Project
+		if name == "" {
+			name = "\u2014"
+		} else {
+			hasProject = true
+		}
+		pt, ok := byName[name]
+		if !ok {
+			pt = &projectTotal{name: name}
+			byName[name] = pt
+		}
+		pt.Repos++
+		pt.Files += r.Totals.Files
+		pt.Code += r.Totals.Code
+		pt.Complexity += r.Totals.Complexity
+	}
+	if !hasProject {
+		return nil
+	}
+
+	totals := make([]projectTotal, 0, len(byName))
+	for _, pt := range byName {
+		totals = append(totals, *pt)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Code != totals[j].Code {
+			return totals[i].Code > totals[j].Code
+		}
+		return totals[i].name < totals[j].name
+	})
+	return totals
+}
+
+// testRatio formats test code per line of production code, or a dash when
+// there is no production code.
+func testRatio(testCode, code int64) string {
+	if code == 0 {
+		return "\u2014"
+	}
+	return fmt.Sprintf("%.2f", float64(testCode)/float64(code))
+}
+
+// MarkdownOption configures WriteMarkdown.
+type MarkdownOption func(*markdownConfig)
+
+type markdownConfig struct {
+	allLanguages bool
+	topLanguages int
+}
+
+// AllLanguages keeps languages with no code (only comments/blanks, or data
+// formats) in the Languages table, which otherwise omits them.
+func AllLanguages() MarkdownOption {
+	return func(c *markdownConfig) {
+		c.allLanguages = true
+	}
+}
+
+// TopLanguages limits the Languages table to the n languages with the most
+// code, rolling the rest into an "Other" row. n <= 0 shows every language.
+func TopLanguages(n int) MarkdownOption {
+	return func(c *markdownConfig) {
+		c.topLanguages = n
+	}
+}
+
 // WriteMarkdown writes the report as GitHub-flavored markdown to w.
-func WriteMarkdown(w io.Writer, report model.Report) error {
+func WriteMarkdown(w io.Writer, report model.Report, opts ...MarkdownOption) error {
+	var cfg markdownConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	fmt.Fprintf(w, "# Code Statistics Report\n\n")
 	fmt.Fprintf(w, "**Provider:** %s\n", report.Provider)
 	if report.Workspace != "" {
@@ -43,8 +149,37 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 	if report.Totals.FilteredFiles > 0 {
 		fmt.Fprintf(w, "| Filtered Files | %d |\n", report.Totals.FilteredFiles)
 	}
+	if report.Totals.FilteredRepos > 0 {
+		fmt.Fprintf(w, "| Filtered Small Repos | %d (below %d lines of code) |\n", report.Totals.FilteredRepos, report.Filters.MinCode)
+	}
+	for _, lang := range report.ByLanguage {
+		if lang.Name == model.DocumentationLanguage {
+			fmt.Fprintf(w, "| Documentation | %d (%.1f%% of code) |\n", lang.Code, lang.CodePercent)
+		}
+	}
+	var testFiles, testCode int64
+	for _, repo := range report.Repositories {
+		testFiles += repo.TestFiles
+		testCode += repo.TestCode
+	}
+	hasTests := testFiles > 0
+	if hasTests {
+		fmt.Fprintf(w, "| Test Files | %d |\n", testFiles)
+		fmt.Fprintf(w, "| Test Code | %d |\n", testCode)
+		fmt.Fprintf(w, "| Test-to-Code Ratio | %s |\n", testRatio(testCode, report.Totals.Code))
+	}
 	fmt.Fprintln(w)
 
+	// Per-repository distribution (typical vs outlier repos)
+	if d := report.Distribution; d != nil {
+		fmt.Fprintf(w, "## Distribution\n\n")
+		fmt.Fprintf(w, "| Per Repository | p50 | p90 | p99 |\n")
+		fmt.Fprintf(w, "|----------------|----:|----:|----:|\n")
+		fmt.Fprintf(w, "| Code | %d | %d | %d |\n", d.Code.P50, d.Code.P90, d.Code.P99)
+		fmt.Fprintf(w, "| Complexity | %d | %d | %d |\n", d.Complexity.P50, d.Complexity.P90, d.Complexity.P99)
+		fmt.Fprintln(w)
+	}
+
 	// AI Code Estimation (only if present)
 	if report.AIEstimate != nil {
 		fmt.Fprintf(w, "## AI Code Estimation\n\n")
@@ -52,10 +187,42 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 		fmt.Fprintf(w, "|--------|------:|:-------------:|-----------:|\n")
 		fmt.Fprintf(w, "| Commits | %d | %d | %.1f%% |\n",
 			report.AIEstimate.TotalCommits, report.AIEstimate.AICommits, report.AIEstimate.CommitPercent)
-		if report.AIEstimate.AIAdditions > 0 {
+		switch {
+		case report.AIEstimate.TotalAdditions > 0:
+			fmt.Fprintf(w, "| Line additions | %d | %d | %.1f%% |\n",
+				report.AIEstimate.TotalAdditions, report.AIEstimate.AIAdditions, report.AIEstimate.AdditionPercent)
+		case report.AIEstimate.AIAdditions > 0:
+			// Reports from before total additions were collected.
 			fmt.Fprintf(w, "| Line additions | — | %d | — |\n", report.AIEstimate.AIAdditions)
 		}
 		fmt.Fprintln(w)
+
+		if len(report.AIEstimate.SignalCounts) > 0 {
+			signals := make([]model.AISignal, 0, len(report.AIEstimate.SignalCounts))
+			for sig := range report.AIEstimate.SignalCounts {
+				signals = append(signals, sig)
+			}
+			sort.Slice(signals, func(i, j int) bool {
+				ni, nj := report.AIEstimate.SignalCounts[signals[i]], report.AIEstimate.SignalCounts[signals[j]]
+				if ni != nj {
+					return ni > nj
+				}
+				return signals[i] < signals[j]
+			})
+			// A commit can carry several signals, so shares may add up to more than 100%.
+			fmt.Fprintf(w, "### Detection Signals\n\n")
+			fmt.Fprintf(w, "| Signal | AI Commits | Share |\n")
+			fmt.Fprintf(w, "|--------|-----------:|------:|\n")
+			for _, sig := range signals {
+				n := report.AIEstimate.SignalCounts[sig]
+				var share float64
+				if report.AIEstimate.AICommits > 0 {
+					share = float64(n) / float64(report.AIEstimate.AICommits) * 100
+				}
+				fmt.Fprintf(w, "| %s | %d | %.1f%% |\n", sig, n, share)
+			}
+			fmt.Fprintln(w)
+		}
 	}
 
 	// Repository Health (only if present)
@@ -63,11 +230,12 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 		fmt.Fprintf(w, "## Repository Health\n\n")
 		fmt.Fprintf(w, "| Category | Repos | Code Lines | %% of Code |\n")
 		fmt.Fprintf(w, "|----------|------:|-----------:|----------:|\n")
-		fmt.Fprintf(w, "| Active (<180d) | %d | %d | %.1f%% |\n",
+		th := healthThresholds(report)
+		fmt.Fprintf(w, "| Active (<%dd) | %d | %d | %.1f%% |\n", th.Active,
 			report.HealthSummary.Active.Repos, report.HealthSummary.Active.Code, report.HealthSummary.Active.CodePercent)
-		fmt.Fprintf(w, "| Maintained (180-365d) | %d | %d | %.1f%% |\n",
+		fmt.Fprintf(w, "| Maintained (%d-%dd) | %d | %d | %.1f%% |\n", th.Active, th.Maintained,
 			report.HealthSummary.Maintained.Repos, report.HealthSummary.Maintained.Code, report.HealthSummary.Maintained.CodePercent)
-		fmt.Fprintf(w, "| Abandoned (>365d) | %d | %d | %.1f%% |\n",
+		fmt.Fprintf(w, "| Abandoned (>%dd) | %d | %d | %.1f%% |\n", th.Maintained,
 			report.HealthSummary.Abandoned.Repos, report.HealthSummary.Abandoned.Code, report.HealthSummary.Abandoned.CodePercent)
 		if report.HealthSummary.Failed.Repos > 0 {
 			fmt.Fprintf(w, "| Failed (error) | %d | %d | — |\n",
@@ -78,17 +246,68 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 
 	// By language
 	fmt.Fprintf(w, "## Languages\n\n")
-	fmt.Fprintf(w, "| Language | Files | Code | Comments | Blanks | Complexity |\n")
-	fmt.Fprintf(w, "|----------|------:|-----:|---------:|-------:|-----------:|\n")
+	fmt.Fprintf(w, "| Language | Files | Code | %% of Code | Comments | Blanks | Complexity |\n")
+	fmt.Fprintf(w, "|----------|------:|-----:|----------:|---------:|-------:|-----------:|\n")
+	var noCode, shown int
+	other := model.LanguageStats{Name: "Other"}
+	var otherCount int
 	for _, lang := range report.ByLanguage {
-		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d |\n",
-			lang.Name, lang.Files, lang.Code, lang.Comments, lang.Blanks, lang.Complexity)
+		if lang.Code == 0 && !cfg.allLanguages {
+			noCode++
+			continue
+		}
+		if cfg.topLanguages > 0 && shown >= cfg.topLanguages {
+			otherCount++
+			other.Files += lang.Files
+			other.Code += lang.Code
+			other.CodePercent += lang.CodePercent
+			other.Comments += lang.Comments
+			other.Blanks += lang.Blanks
+			other.Complexity += lang.Complexity
+			continue
+		}
+		shown++
+		fmt.Fprintf(w, "| %s | %d | %d | %.1f%% | %d | %d | %d |\n",
+			lang.Name, lang.Files, lang.Code, lang.CodePercent, lang.Comments, lang.Blanks, lang.Complexity)
+	}
+	if otherCount > 0 {
+		fmt.Fprintf(w, "| Other (%d languages) | %d | %d | %.1f%% | %d | %d | %d |\n",
+			otherCount, other.Files, other.Code, other.CodePercent, other.Comments, other.Blanks, other.Complexity)
 	}
 	fmt.Fprintln(w)
+	if noCode > 0 {
+		fmt.Fprintf(w, "_%d languages with no code omitted (use --all-languages to show them)._\n\n", noCode)
+	}
+
+	// By project (only if any repo has a project)
+	if projects := projectTotals(report.Repositories); len(projects) > 0 {
+		fmt.Fprintf(w, "## By Project\n\n")
+		fmt.Fprintf(w, "| Project | Repos | Files | Code | Complexity |\n")
+		fmt.Fprintf(w, "|---------|------:|------:|-----:|-----------:|\n")
+		for _, p := range projects {
+			fmt.Fprintf(w, "| %s | %d | %d | %d | %d |\n", p.name, p.Repos, p.Files, p.Code, p.Complexity)
+		}
+		fmt.Fprintln(w)
+	}
 
 	// Per repository
 	hasAI := report.AIEstimate != nil
 	hasHealth := report.HealthSummary != nil
+	var hasIssues, hasConventional, hasCommitCount, hasSize bool
+	for _, repo := range report.Repositories {
+		if repo.OpenIssues != nil {
+			hasIssues = true
+		}
+		if repo.CommitCount != nil {
+			hasCommitCount = true
+		}
+		if repo.RepoSizeKB > 0 {
+			hasSize = true
+		}
+		if repo.ConventionalCommitPercent != nil {
+			hasConventional = true
+		}
+	}
 	fmt.Fprintf(w, "## Repositories\n\n")
 
 	// Build header based on which optional columns are present
@@ -102,6 +321,26 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 		header += " | AI Commits % | AI Additions"
 		separator += "|-------------:|-------------:"
 	}
+	if hasIssues {
+		header += " | Open Issues"
+		separator += "|------------:"
+	}
+	if hasConventional {
+		header += " | Conventional %"
+		separator += "|---------------:"
+	}
+	if hasCommitCount {
+		header += " | Commits"
+		separator += "|--------:"
+	}
+	if hasTests {
+		header += " | Test Code | Test Ratio"
+		separator += "|----------:|-----------:"
+	}
+	if hasSize {
+		header += " | Size (KB)"
+		separator += "|----------:"
+	}
 	fmt.Fprintf(w, "%s |\n%s|\n", header, separator)
 
 	for _, repo := range report.Repositories {
@@ -109,8 +348,12 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 		if lic == "" {
 			lic = "\u2014"
 		}
-		fmt.Fprintf(w, "| [%s](%s) | %s | %s | %d | %d | %d | %d",
-			repo.Repository, repo.URL, repo.Project, lic, repo.Totals.Files, repo.Totals.Code,
+		tag := ""
+		if repo.IsInfraOnly {
+			tag = " `infra`"
+		}
+		fmt.Fprintf(w, "| [%s](%s)%s | %s | %s | %d | %d | %d | %d",
+			repo.Repository, repo.URL, tag, repo.Project, lic, repo.Totals.Files, repo.Totals.Code,
 			repo.Totals.Comments, repo.Totals.Complexity)
 		if hasHealth {
 			healthStr := "\u2014"
@@ -132,9 +375,46 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 			if repo.AIEstimate != nil {
 				aiPct = fmt.Sprintf("%.1f%%", repo.AIEstimate.CommitPercent)
 				aiAdd = fmt.Sprintf("%d", repo.AIEstimate.AIAdditions)
+				if repo.AIEstimate.AdditionsUnavailable {
+					aiAdd = "n/a"
+				}
 			}
 			fmt.Fprintf(w, " | %s | %s", aiPct, aiAdd)
 		}
+		if hasIssues {
+			issues := "\u2014"
+			if repo.OpenIssues != nil {
+				issues = fmt.Sprintf("%d", *repo.OpenIssues)
+			}
+			fmt.Fprintf(w, " | %s", issues)
+		}
+		if hasConventional {
+			conv := "\u2014"
+			if repo.ConventionalCommitPercent != nil {
+				conv = fmt.Sprintf("%.1f%%", *repo.ConventionalCommitPercent)
+			}
+			fmt.Fprintf(w, " | %s", conv)
+		}
+		if hasCommitCount {
+			commits := "\u2014"
+			if repo.CommitCount != nil {
+				commits = fmt.Sprintf("%d", *repo.CommitCount)
+				if repo.CommitCountTruncated {
+					commits += "+"
+				}
+			}
+			fmt.Fprintf(w, " | %s", commits)
+		}
+		if hasTests {
+			fmt.Fprintf(w, " | %d | %s", repo.TestCode, testRatio(repo.TestCode, repo.Totals.Code))
+		}
+		if hasSize {
+			size := "\u2014"
+			if repo.RepoSizeKB > 0 {
+				size = fmt.Sprintf("%d", repo.RepoSizeKB)
+			}
+			fmt.Fprintf(w, " | %s", size)
+		}
 		fmt.Fprintln(w, " |")
 	}
 	fmt.Fprintln(w)
@@ -156,7 +436,7 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 				if repo.HealthDetails == nil {
 					continue
 				}
-				for _, window := range []string{"0-6mo", "6-12mo", "12mo+"} {
+				for _, window := range detailWindows(repo.HealthDetails) {
 					cs, hasChurn := repo.HealthDetails.ChurnByWindow[window]
 					authors := repo.HealthDetails.AuthorsByWindow[window]
 					if !hasChurn && authors == 0 {
@@ -167,13 +447,69 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 				}
 			}
 			fmt.Fprintln(w)
+
+			// Velocity: recent (first window) vs previous (second window) commit pace
+			var velocityRows []string
+			for _, repo := range report.Repositories {
+				if repo.HealthDetails == nil || repo.HealthDetails.VelocityLabel == "" {
+					continue
+				}
+				trend := "—" // no commits in the previous window
+				if repo.HealthDetails.VelocityTrend > 0 {
+					trend = fmt.Sprintf("%.2fx", repo.HealthDetails.VelocityTrend)
+				}
+				velocityRows = append(velocityRows, fmt.Sprintf("| %s | %s | %s |", repo.Repository, repo.HealthDetails.VelocityLabel, trend))
+			}
+			if len(velocityRows) > 0 {
+				fmt.Fprintf(w, "| Repository | Velocity | Trend |\n")
+				fmt.Fprintf(w, "|------------|----------|------:|\n")
+				for _, row := range velocityRows {
+					fmt.Fprintln(w, row)
+				}
+				fmt.Fprintln(w)
+			}
 		}
 	}
 
-	// Code Churn
+	// Abandoned repositories, largest first (only if health ran)
+	var abandoned []model.RepoStats
+	for _, repo := range report.Repositories {
+		if repo.Health != nil && repo.Health.Category == model.HealthAbandoned {
+			abandoned = append(abandoned, repo)
+		}
+	}
+	if len(abandoned) > 0 {
+		sort.SliceStable(abandoned, func(i, j int) bool {
+			return abandoned[i].Totals.Code > abandoned[j].Totals.Code
+		})
+		fmt.Fprintf(w, "## Abandoned Repositories\n\n")
+		fmt.Fprintf(w, "| Repository | Days Since Commit | Code |\n")
+		fmt.Fprintf(w, "|------------|------------------:|-----:|\n")
+		for _, repo := range abandoned {
+			fmt.Fprintf(w, "| %s | %d | %d |\n", repo.Repository, repo.Health.DaysSinceCommit, repo.Totals.Code)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Decommission candidates: large abandoned repos (only if health ran)
+	if len(report.RiskRepos) > 0 {
+		fmt.Fprintf(w, "## Decommission Candidates\n\n")
+		fmt.Fprintf(w, "Abandoned repositories ranked by code × days since the last commit.\n\n")
+		fmt.Fprintf(w, "| Repository | Code | Days Since Commit | Risk Score |\n")
+		fmt.Fprintf(w, "|------------|-----:|------------------:|-----------:|\n")
+		for i, r := range report.RiskRepos {
+			if i == maxRiskRepos {
+				break
+			}
+			fmt.Fprintf(w, "| %s | %d | %d | %d |\n", r.Repository, r.Code, r.DaysSinceCommit, r.Score)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Code Churn (every repo churn was attempted for, even without file stats)
 	var hasChurn bool
 	for _, repo := range report.Repositories {
-		if repo.Churn != nil && len(repo.Churn.TopFiles) > 0 {
+		if repo.Churn != nil {
 			hasChurn = true
 			break
 		}
@@ -182,11 +518,40 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 	if hasChurn {
 		fmt.Fprintf(w, "## Code Churn\n\n")
 		for _, repo := range report.Repositories {
-			if repo.Churn == nil || len(repo.Churn.TopFiles) == 0 {
+			if repo.Churn == nil {
 				continue
 			}
 			fmt.Fprintf(w, "### %s\n\n", repo.Repository)
 			fmt.Fprintf(w, "**Commits scanned:** %d\n\n", repo.Churn.TotalCommits)
+			if repo.Churn.StatsUnavailable {
+				fmt.Fprintf(w, "_The provider reported no line counts for these commits; additions and deletions are unavailable, not zero._\n\n")
+			}
+			if repo.Churn.FileStatsUnavailable {
+				fmt.Fprintf(w, "_The provider doesn't report changed files per commit, so only the commit count is available._\n\n")
+				continue
+			}
+
+			if len(repo.Churn.TopFiles) == 0 {
+				if repo.Churn.TotalCommits == 0 {
+					fmt.Fprintf(w, "_No commits found, so there is no churn to report._\n\n")
+				} else {
+					fmt.Fprintf(w, "_Churn was analyzed but no per-file stats were available for the scanned commits._\n\n")
+				}
+				continue
+			}
+
+			if len(repo.Churn.ByCategory) > 0 {
+				fmt.Fprintf(w, "| Category | Files | Changes | Additions | Deletions |\n")
+				fmt.Fprintf(w, "|----------|------:|--------:|----------:|----------:|\n")
+				for _, name := range churn.Categories {
+					c, ok := repo.Churn.ByCategory[name]
+					if !ok {
+						continue
+					}
+					fmt.Fprintf(w, "| %s | %d | %d | %d | %d |\n", capitalize(name), c.Files, c.Changes, c.Additions, c.Deletions)
+				}
+				fmt.Fprintln(w)
+			}
 
 			fmt.Fprintf(w, "| File | Changes | Additions | Deletions |\n")
 			fmt.Fprintf(w, "|------|--------:|----------:|----------:|\n")
@@ -204,7 +569,132 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 				}
 				fmt.Fprintln(w)
 			}
+
+			if len(repo.Churn.Couplings) > 0 {
+				fmt.Fprintf(w, "**Change Coupling** (files changed in the same commits):\n\n")
+				fmt.Fprintf(w, "| File A | File B | Co-changes | Confidence |\n")
+				fmt.Fprintf(w, "|--------|--------|-----------:|-----------:|\n")
+				for _, c := range repo.Churn.Couplings {
+					fmt.Fprintf(w, "| %s | %s | %d | %.0f%% |\n", c.FileA, c.FileB, c.CoChanges, c.Confidence*100)
+				}
+				fmt.Fprintln(w)
+			}
+
+			if len(repo.Churn.AuthorChurn) > 0 {
+				fmt.Fprintf(w, "**Top Authors** (by lines changed):\n\n")
+				fmt.Fprintf(w, "| Author | Commits | Additions | Deletions |\n")
+				fmt.Fprintf(w, "|--------|--------:|----------:|----------:|\n")
+				for _, a := range repo.Churn.AuthorChurn {
+					fmt.Fprintf(w, "| %s | %d | %d | %d |\n", a.Author, a.Commits, a.Additions, a.Deletions)
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	// Code ownership (only if --code-ownership attributed any file)
+	var hasOwners bool
+	for _, repo := range report.Repositories {
+		if repo.Churn == nil {
+			continue
+		}
+		for _, f := range repo.Churn.TopFiles {
+			if f.Owner != "" {
+				hasOwners = true
+				break
+			}
+		}
+	}
+	if hasOwners {
+		fmt.Fprintf(w, "## Code Ownership\n\n")
+		fmt.Fprintf(w, "Dominant author of each high-churn file, by share of the commits that changed it.\n\n")
+		fmt.Fprintf(w, "| Repository | File | Owner | Share | Changes |\n")
+		fmt.Fprintf(w, "|------------|------|-------|------:|--------:|\n")
+		for _, repo := range report.Repositories {
+			if repo.Churn == nil {
+				continue
+			}
+			for _, f := range repo.Churn.TopFiles {
+				if f.Owner == "" {
+					continue
+				}
+				fmt.Fprintf(w, "| %s | %s | %s | %.0f%% | %d |\n", repo.Repository, f.Path, f.Owner, f.OwnerShare, f.Changes)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Large files (only if --large-files found any)
+	var hasLargeFiles bool
+	for _, repo := range report.Repositories {
+		if len(repo.LargeFiles) > 0 {
+			hasLargeFiles = true
+			break
+		}
+	}
+	if hasLargeFiles {
+		fmt.Fprintf(w, "## Large Files\n\n")
+		fmt.Fprintf(w, "| Repository | File | Size (MB) |\n")
+		fmt.Fprintf(w, "|------------|------|----------:|\n")
+		for _, repo := range report.Repositories {
+			for _, f := range repo.LargeFiles {
+				fmt.Fprintf(w, "| %s | %s | %.1f |\n", repo.Repository, f.Path, float64(f.Size)/(1<<20))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Co-authorship (top pairs only; the JSON report has the full list)
+	if len(report.CoAuthorship) > 0 {
+		fmt.Fprintf(w, "## Co-Authorship\n\n")
+		fmt.Fprintf(w, "| Author | Co-Author | Shared Commits |\n")
+		fmt.Fprintf(w, "|--------|-----------|---------------:|\n")
+		for i, p := range report.CoAuthorship {
+			if i == maxCoAuthorPairs {
+				break
+			}
+			fmt.Fprintf(w, "| %s | %s | %d |\n", p.AuthorA, p.AuthorB, p.Commits)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Commit activity heatmap (only if --activity-heatmap was used)
+	if h := report.ActivityHeatmap; h != nil {
+		fmt.Fprintf(w, "## Commit Activity\n\n")
+		weekend, offHours := activity.Shares(h)
+		fmt.Fprintf(w, "%d commits: %.1f%% on weekends, %.1f%% on weekdays outside 08:00-20:00.\n\n", h.Commits, weekend, offHours)
+		fmt.Fprintf(w, "| Day |")
+		for hour := 0; hour < 24; hour++ {
+			fmt.Fprintf(w, " %02d |", hour)
+		}
+		fmt.Fprintf(w, "\n|-----|%s\n", strings.Repeat("---:|", 24))
+		// Monday first, Sunday last
+		for i := 1; i <= 7; i++ {
+			day := time.Weekday(i % 7)
+			fmt.Fprintf(w, "| %s |", day.String()[:3])
+			for _, n := range h.Counts[day] {
+				fmt.Fprintf(w, " %d |", n)
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+
+	// Complexity warnings (only if thresholds were set and exceeded)
+	if len(report.ComplexityWarnings) > 0 {
+		fmt.Fprintf(w, "## Complexity Warnings\n\n")
+		fmt.Fprintf(w, "| Repository | Scope | Complexity | Threshold |\n")
+		fmt.Fprintf(w, "|------------|-------|-----------:|----------:|\n")
+		for _, cw := range report.ComplexityWarnings {
+			scope := "repository"
+			if cw.Path != "" {
+				scope = cw.Path
+			} else if cw.Language != "" {
+				scope = cw.Language
+			}
+			fmt.Fprintf(w, "| %s | %s | %d | %d |\n", cw.Repository, scope, cw.Complexity, cw.Threshold)
 		}
+		fmt.Fprintln(w)
 	}
 
 	// Errors
@@ -216,6 +706,18 @@ func WriteMarkdown(w io.Writer, report model.Report) error {
 		fmt.Fprintln(w)
 	}
 
+	// Timing (only if present)
+	if report.Timing != nil && len(report.Timing.Phases) > 0 {
+		fmt.Fprintf(w, "## Timing\n\n")
+		fmt.Fprintf(w, "| Phase | Seconds |\n")
+		fmt.Fprintf(w, "|-------|--------:|\n")
+		for _, p := range report.Timing.Phases {
+			fmt.Fprintf(w, "| %s | %.1f |\n", p.Phase, p.Seconds)
+		}
+		fmt.Fprintf(w, "| **Total** | **%.1f** |\n", report.Timing.TotalSeconds)
+		fmt.Fprintln(w)
+	}
+
 	return nil
 }
 
@@ -229,16 +731,23 @@ func WriteTrendsMarkdown(w io.Writer, report model.TrendsReport) error {
 	if report.Organization != "" {
 		fmt.Fprintf(w, "**Organization:** %s\n", report.Organization)
 	}
-	fmt.Fprintf(w, "**Period:** %s to %s (%s)\n", report.Since, report.Until, report.Interval)
+	if report.Since == "auto" && len(report.Periods) > 0 {
+		fmt.Fprintf(w, "**Period:** %s to %s (%s, each repo from its first commit)\n", report.Periods[0], report.Until, report.Interval)
+	} else {
+		fmt.Fprintf(w, "**Period:** %s to %s (%s)\n", report.Since, report.Until, report.Interval)
+	}
+	if total, perPeriod, ok := codeGrowth(report.Snapshots); ok {
+		fmt.Fprintf(w, "**Code Growth:** %+.1f%% overall, %+.1f%% per period (compounded)\n", total, perPeriod)
+	}
 	fmt.Fprintf(w, "**Generated:** %s\n\n", report.GeneratedAt)
 
 	// Summary table: one row per period
 	fmt.Fprintf(w, "## Summary\n\n")
-	fmt.Fprintf(w, "| Period | Files | Code | Comments | Blanks | Complexity | Code Delta |\n")
-	fmt.Fprintf(w, "|--------|------:|-----:|---------:|-------:|-----------:|-----------:|\n")
+	fmt.Fprintf(w, "| Period | Files | Code | Comments | Blanks | Complexity | Code Delta | Delta %% |\n")
+	fmt.Fprintf(w, "|--------|------:|-----:|---------:|-------:|-----------:|-----------:|--------:|\n")
 	var prevCode int64
 	for _, snap := range report.Snapshots {
-		delta := ""
+		delta, deltaPct := "", ""
 		if prevCode > 0 {
 			diff := snap.Totals.Code - prevCode
 			if diff >= 0 {
@@ -246,10 +755,11 @@ func WriteTrendsMarkdown(w io.Writer, report model.TrendsReport) error {
 			} else {
 				delta = fmt.Sprintf("%d", diff)
 			}
+			deltaPct = fmt.Sprintf("%+.1f%%", float64(diff)/float64(prevCode)*100)
 		}
-		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %s |\n",
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %s | %s |\n",
 			snap.Period, snap.Totals.Files, snap.Totals.Code,
-			snap.Totals.Comments, snap.Totals.Blanks, snap.Totals.Complexity, delta)
+			snap.Totals.Comments, snap.Totals.Blanks, snap.Totals.Complexity, delta, deltaPct)
 		prevCode = snap.Totals.Code
 	}
 	fmt.Fprintln(w)
@@ -323,14 +833,16 @@ func WriteTrendsMarkdown(w io.Writer, report model.TrendsReport) error {
 	for _, name := range repoNames {
 		fmt.Fprintf(w, "| %s |", name)
 		for _, snap := range report.Snapshots {
-			code := int64(0)
+			// A repo missing from a period (not yet created, or its checkout
+			// failed) gets a dash rather than a misleading 0
+			cell := "\u2014"
 			for _, repo := range snap.Repositories {
 				if repo.Repository == name {
-					code = repo.Totals.Code
+					cell = fmt.Sprintf("%d", repo.Totals.Code)
 					break
 				}
 			}
-			fmt.Fprintf(w, " %d |", code)
+			fmt.Fprintf(w, " %s |", cell)
 		}
 		fmt.Fprintln(w)
 	}
@@ -346,3 +858,49 @@ func WriteTrendsMarkdown(w io.Writer, report model.TrendsReport) error {
 
 	return nil
 }
+
+// codeGrowth returns the percentage change in total code from the first to
+// the last snapshot and the equivalent compound growth per period. ok is
+// false with fewer than two snapshots or no code in the first one.
+func codeGrowth(snapshots []model.PeriodSnapshot) (total, perPeriod float64, ok bool) {
+	if len(snapshots) < 2 {
+		return 0, 0, false
+	}
+	first := snapshots[0].Totals.Code
+	last := snapshots[len(snapshots)-1].Totals.Code
+	if first <= 0 {
+		return 0, 0, false
+	}
+	ratio := float64(last) / float64(first)
+	total = (ratio - 1) * 100
+	perPeriod = (math.Pow(ratio, 1/float64(len(snapshots)-1)) - 1) * 100
+	return total, perPeriod, true
+}
+
+// detailWindows returns the window labels present in the health details,
+// ordered from the most recent by the months they start at ("3-6mo" -> 3,
+// "12mo+" -> 12).
+func detailWindows(d *model.RepoHealthDetails) []string {
+	seen := map[string]bool{}
+	var windows []string
+	for w := range d.ChurnByWindow {
+		seen[w] = true
+		windows = append(windows, w)
+	}
+	for w := range d.AuthorsByWindow {
+		if !seen[w] {
+			windows = append(windows, w)
+		}
+	}
+	start := func(w string) int {
+		n, _ := strconv.Atoi(strings.TrimRight(strings.SplitN(w, "-", 2)[0], "mo+"))
+		return n
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if si, sj := start(windows[i]), start(windows[j]); si != sj {
+			return si < sj
+		}
+		return windows[i] < windows[j]
+	})
+	return windows
+}
@@ -0,0 +1,134 @@
+// internal/output/merge.go
+package output
+
+import (
+	"sort"
+
+	"github.com/dsablic/codemium/internal/activity"
+	"github.com/dsablic/codemium/internal/coauthor"
+	"github.com/dsablic/codemium/internal/complexity"
+	"github.com/dsablic/codemium/internal/health"
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// MergedProvider is the provider name of a report combining several
+// providers; each repository keeps its own provider.
+const MergedProvider = "all"
+
+// Merge combines reports from separate runs into one. Repositories, errors
+// and complexity warnings are concatenated; totals, language breakdowns,
+// AI estimates, health summaries, risk repos, co-authorship counts and phase
+// timings are recomputed or summed. Workspace, organization and filters are dropped since
+// they differ per input; GeneratedAt is taken from the first report.
+func Merge(reports ...model.Report) model.Report {
+	merged := model.Report{Provider: MergedProvider}
+	if len(reports) > 0 {
+		merged.GeneratedAt = reports[0].GeneratedAt
+	}
+
+	langTotals := map[string]*model.LanguageStats{}
+	var ai *model.AIEstimate
+	var timing *model.Timing
+	phaseIndex := map[string]int{}
+
+	for _, r := range reports {
+		merged.Repositories = append(merged.Repositories, r.Repositories...)
+		merged.Errors = append(merged.Errors, r.Errors...)
+		merged.ComplexityWarnings = append(merged.ComplexityWarnings, r.ComplexityWarnings...)
+
+		merged.Totals.Repos += r.Totals.Repos
+		merged.Totals.Files += r.Totals.Files
+		merged.Totals.Lines += r.Totals.Lines
+		merged.Totals.Code += r.Totals.Code
+		merged.Totals.Comments += r.Totals.Comments
+		merged.Totals.Blanks += r.Totals.Blanks
+		merged.Totals.Complexity += r.Totals.Complexity
+		merged.Totals.FilteredFiles += r.Totals.FilteredFiles
+		merged.Totals.FilteredRepos += r.Totals.FilteredRepos
+
+		for _, lang := range r.ByLanguage {
+			lt, ok := langTotals[lang.Name]
+			if !ok {
+				lt = &model.LanguageStats{Name: lang.Name}
+				langTotals[lang.Name] = lt
+			}
+			lt.Files += lang.Files
+			lt.Lines += lang.Lines
+			lt.Code += lang.Code
+			lt.Comments += lang.Comments
+			lt.Blanks += lang.Blanks
+			lt.Complexity += lang.Complexity
+		}
+
+		if r.AIEstimate != nil {
+			if ai == nil {
+				ai = &model.AIEstimate{}
+			}
+			ai.TotalCommits += r.AIEstimate.TotalCommits
+			ai.AICommits += r.AIEstimate.AICommits
+			ai.AIAdditions += r.AIEstimate.AIAdditions
+			if !r.AIEstimate.AdditionsUnavailable {
+				ai.TotalAdditions += r.AIEstimate.TotalAdditions
+			}
+			for sig, n := range r.AIEstimate.SignalCounts {
+				if ai.SignalCounts == nil {
+					ai.SignalCounts = map[model.AISignal]int64{}
+				}
+				ai.SignalCounts[sig] += n
+			}
+		}
+
+		if r.Timing != nil {
+			if timing == nil {
+				timing = &model.Timing{}
+			}
+			timing.TotalSeconds += r.Timing.TotalSeconds
+			for _, p := range r.Timing.Phases {
+				if i, ok := phaseIndex[p.Phase]; ok {
+					timing.Phases[i].Seconds += p.Seconds
+					continue
+				}
+				phaseIndex[p.Phase] = len(timing.Phases)
+				timing.Phases = append(timing.Phases, p)
+			}
+		}
+	}
+
+	for _, lt := range langTotals {
+		merged.ByLanguage = append(merged.ByLanguage, *lt)
+	}
+	sort.Slice(merged.ByLanguage, func(i, j int) bool {
+		if merged.ByLanguage[i].Code != merged.ByLanguage[j].Code {
+			return merged.ByLanguage[i].Code > merged.ByLanguage[j].Code
+		}
+		return merged.ByLanguage[i].Name < merged.ByLanguage[j].Name
+	})
+	if merged.Totals.Code > 0 {
+		for i := range merged.ByLanguage {
+			merged.ByLanguage[i].CodePercent = float64(merged.ByLanguage[i].Code) / float64(merged.Totals.Code) * 100
+		}
+	}
+
+	if ai != nil && ai.TotalCommits > 0 {
+		ai.CommitPercent = float64(ai.AICommits) / float64(ai.TotalCommits) * 100
+	}
+	if ai != nil && ai.TotalAdditions > 0 {
+		ai.AdditionPercent = float64(ai.AIAdditions) / float64(ai.TotalAdditions) * 100
+	}
+	merged.AIEstimate = ai
+	merged.Timing = timing
+
+	sort.SliceStable(merged.ComplexityWarnings, func(i, j int) bool {
+		return merged.ComplexityWarnings[i].Complexity > merged.ComplexityWarnings[j].Complexity
+	})
+
+	merged.HealthSummary = health.Summarize(merged.Repositories)
+	merged.RiskRepos = health.RiskRepos(merged.Repositories)
+	merged.Distribution = complexity.Distribution(merged.Repositories)
+	merged.ActivityHeatmap = activity.Merge(merged.Repositories)
+	if pairs := coauthor.Merge(merged.Repositories); len(pairs) > 0 {
+		merged.CoAuthorship = pairs
+	}
+
+	return merged
+}
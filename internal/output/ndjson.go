@@ -0,0 +1,49 @@
+// internal/output/ndjson.go
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// ndjsonMetadata is the first NDJSON line: everything in the report except
+// the per-repository entries. Its own zero-valued, omitempty Repositories
+// shadows the embedded report's, so new report fields are carried along.
+type ndjsonMetadata struct {
+	Type       string `json:"type"`
+	TotalRepos int    `json:"total_repos"`
+	model.Report
+	Repositories []model.RepoStats `json:"repositories,omitempty"`
+}
+
+// ndjsonRepo is one repository line; the embedded RepoStats fields are
+// inlined next to the type marker.
+type ndjsonRepo struct {
+	Type string `json:"type"`
+	model.RepoStats
+}
+
+// WriteNDJSON writes the report as newline-delimited JSON: a "metadata" line
+// carrying provider, filters and totals, followed by one "repo" line per
+// repository, so consumers can stream it without loading the whole report.
+func WriteNDJSON(w io.Writer, report model.Report) error {
+	enc := json.NewEncoder(w)
+
+	meta := ndjsonMetadata{
+		Type:       "metadata",
+		TotalRepos: len(report.Repositories),
+		Report:     report,
+	}
+	if err := enc.Encode(meta); err != nil {
+		return err
+	}
+
+	for _, r := range report.Repositories {
+		if err := enc.Encode(ndjsonRepo{Type: "repo", RepoStats: r}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
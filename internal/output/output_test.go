@@ -3,9 +3,15 @@ package output_test
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/net/html"
 
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/output"
@@ -65,6 +71,110 @@ func TestWriteJSON(t *testing.T) {
 	}
 }
 
+func TestWriteJSONCompact(t *testing.T) {
+	report := sampleReport()
+	var pretty, compact bytes.Buffer
+	if err := output.WriteJSON(&pretty, report); err != nil {
+		t.Fatalf("failed to write JSON: %v", err)
+	}
+	if err := output.WriteJSON(&compact, report, output.Compact()); err != nil {
+		t.Fatalf("failed to write compact JSON: %v", err)
+	}
+
+	if n := bytes.Count(compact.Bytes(), []byte("\n")); n != 1 {
+		t.Errorf("expected compact JSON on a single line, got %d newlines", n)
+	}
+	if compact.Len() >= pretty.Len() {
+		t.Errorf("expected compact output (%d bytes) to be smaller than pretty (%d bytes)", compact.Len(), pretty.Len())
+	}
+
+	var decoded model.Report
+	if err := json.Unmarshal(compact.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if decoded.Totals.Code != report.Totals.Code {
+		t.Errorf("expected total code %d, got %d", report.Totals.Code, decoded.Totals.Code)
+	}
+}
+
+func TestWriteJSONSummaryOnly(t *testing.T) {
+	report := sampleReport()
+	var buf bytes.Buffer
+	if err := output.WriteJSON(&buf, report, output.SummaryOnly()); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if _, ok := raw["repositories"]; ok {
+		t.Error("expected the repositories array to be omitted")
+	}
+	var decoded model.Report
+	json.Unmarshal(buf.Bytes(), &decoded)
+	if decoded.Totals != report.Totals || len(decoded.ByLanguage) != len(report.ByLanguage) || decoded.Provider != report.Provider {
+		t.Errorf("expected envelope and aggregates kept, got %+v", decoded)
+	}
+	if len(report.Repositories) == 0 {
+		t.Error("SummaryOnly modified the caller's report")
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	report := sampleReport()
+	for _, opts := range [][]output.JSONOption{nil, {output.Compact()}} {
+		var want, got bytes.Buffer
+		output.WriteJSON(&want, report, opts...)
+
+		s, err := output.NewJSONStream(&got, report, opts...)
+		if err != nil {
+			t.Fatalf("NewJSONStream: %v", err)
+		}
+		if !strings.Contains(got.String(), `"provider"`) {
+			t.Errorf("expected envelope before any repository, got %q", got.String())
+		}
+		for _, r := range report.Repositories {
+			if err := s.WriteRepo(r); err != nil {
+				t.Fatalf("WriteRepo: %v", err)
+			}
+		}
+		if strings.Contains(got.String(), `"by_language"`) {
+			t.Errorf("expected report-level fields only after Close, got:\n%s", got.String())
+		}
+		if err := s.Close(report); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		var wantReport, gotReport model.Report
+		json.Unmarshal(want.Bytes(), &wantReport)
+		if err := json.Unmarshal(got.Bytes(), &gotReport); err != nil {
+			t.Fatalf("streamed output is not valid JSON: %v\n%s", err, got.String())
+		}
+		if !reflect.DeepEqual(gotReport, wantReport) {
+			t.Errorf("streamed report differs:\ngot  %+v\nwant %+v", gotReport, wantReport)
+		}
+		if len(opts) > 0 && bytes.Count(got.Bytes(), []byte("\n")) != 1 {
+			t.Errorf("expected compact stream on a single line, got:\n%s", got.String())
+		}
+	}
+}
+
+func TestJSONStreamNoRepos(t *testing.T) {
+	var buf bytes.Buffer
+	s, _ := output.NewJSONStream(&buf, model.Report{Provider: "github"})
+	if err := s.Close(model.Report{Provider: "github"}); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if repos, ok := decoded["repositories"].([]any); !ok || len(repos) != 0 {
+		t.Errorf("expected empty repositories array, got %v", decoded["repositories"])
+	}
+}
+
 func sampleTrendsReport() model.TrendsReport {
 	return model.TrendsReport{
 		GeneratedAt:  "2026-02-19T12:00:00Z",
@@ -139,6 +249,31 @@ func TestWriteTrendsMarkdown(t *testing.T) {
 	if !strings.Contains(md, "+") {
 		t.Error("markdown should contain delta indicators")
 	}
+	if !strings.Contains(md, "| 2025-01 | 10 | 1000 | 0 | 0 | 0 |  |  |") {
+		t.Error("first period should have no delta")
+	}
+	if !strings.Contains(md, "| +200 | +20.0% |") || !strings.Contains(md, "| +300 | +25.0% |") {
+		t.Errorf("markdown should contain Delta %% per period, got:\n%s", md)
+	}
+	if !strings.Contains(md, "**Code Growth:** +50.0% overall, +22.5% per period (compounded)") {
+		t.Errorf("markdown should contain compound growth, got:\n%s", md)
+	}
+}
+
+func TestWriteTrendsMarkdownNotYetCreated(t *testing.T) {
+	report := sampleTrendsReport()
+	// "web" only appears from 2025-02 on
+	report.Snapshots[0].NotYetCreated = []string{"web"}
+	report.Snapshots[1].Repositories = append(report.Snapshots[1].Repositories, model.RepoStats{Repository: "web"})
+	report.Snapshots[2].Repositories = append(report.Snapshots[2].Repositories, model.RepoStats{Repository: "web", Totals: model.Stats{Code: 40}})
+
+	var buf bytes.Buffer
+	if err := output.WriteTrendsMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteTrendsMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| web | \u2014 | 0 | 40 |") {
+		t.Errorf("expected a dash before the repo existed and 0 once it did, got:\n%s", buf.String())
+	}
 }
 
 func TestWriteMarkdown(t *testing.T) {
@@ -163,10 +298,12 @@ func TestWriteMarkdown(t *testing.T) {
 func TestWriteMarkdownWithAIEstimate(t *testing.T) {
 	report := sampleReport()
 	report.AIEstimate = &model.AIEstimate{
-		TotalCommits:  200,
-		AICommits:     50,
-		CommitPercent: 25.0,
-		AIAdditions:   3000,
+		TotalCommits:    200,
+		AICommits:       50,
+		CommitPercent:   25.0,
+		TotalAdditions:  12000,
+		AIAdditions:     3000,
+		AdditionPercent: 25.0,
 	}
 	report.Repositories[0].AIEstimate = &model.AIEstimate{
 		TotalCommits:  100,
@@ -196,6 +333,9 @@ func TestWriteMarkdownWithAIEstimate(t *testing.T) {
 	if !strings.Contains(md, "AI Commits %") {
 		t.Error("markdown should contain AI Commits % column in repo table")
 	}
+	if !strings.Contains(md, "| Line additions | 12000 | 3000 | 25.0% |") {
+		t.Error("markdown should contain the line additions share")
+	}
 }
 
 func TestWriteMarkdownLicenseColumn(t *testing.T) {
@@ -236,3 +376,909 @@ func TestWriteMarkdownWithoutAIEstimate(t *testing.T) {
 		t.Error("markdown should NOT contain AI columns when not present")
 	}
 }
+
+func TestWriteMarkdownOpenIssuesColumn(t *testing.T) {
+	report := sampleReport()
+	issues := 12
+	report.Repositories[0].OpenIssues = &issues
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+
+	md := buf.String()
+	if !strings.Contains(md, "| Open Issues |") {
+		t.Error("markdown should contain Open Issues column header")
+	}
+	if !strings.Contains(md, "| 12 |") {
+		t.Error("markdown should contain open issue count")
+	}
+}
+
+func TestWriteMarkdownConventionalColumn(t *testing.T) {
+	report := sampleReport()
+	pct := 62.5
+	report.Repositories[1].ConventionalCommitPercent = &pct
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+
+	md := buf.String()
+	if !strings.Contains(md, "| Conventional % |") {
+		t.Error("markdown should contain Conventional % column header")
+	}
+	if !strings.Contains(md, "| 62.5% |") {
+		t.Error("markdown should contain conventional commit percentage")
+	}
+}
+
+func TestWriteMarkdownTiming(t *testing.T) {
+	report := sampleReport()
+	report.Timing = &model.Timing{
+		TotalSeconds: 42.5,
+		Phases: []model.PhaseTiming{
+			{Phase: "list", Seconds: 1.2},
+			{Phase: "clone+analyze", Seconds: 41.3},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+
+	md := buf.String()
+	if !strings.Contains(md, "## Timing") {
+		t.Error("markdown should contain Timing section")
+	}
+	if !strings.Contains(md, "| clone+analyze | 41.3 |") {
+		t.Error("markdown should contain per-phase timing row")
+	}
+	if !strings.Contains(md, "| **Total** | **42.5** |") {
+		t.Error("markdown should contain total timing row")
+	}
+
+	report.Timing = nil
+	buf.Reset()
+	output.WriteMarkdown(&buf, report)
+	if strings.Contains(buf.String(), "## Timing") {
+		t.Error("markdown should omit Timing section when timing is absent")
+	}
+}
+
+func TestWriteMarkdownByProject(t *testing.T) {
+	report := sampleReport()
+	report.Repositories = append(report.Repositories, model.RepoStats{
+		Repository: "infra",
+		Project:    "OPS",
+		Provider:   "bitbucket",
+		Totals:     model.Stats{Files: 3, Code: 90, Complexity: 1},
+	})
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+
+	md := buf.String()
+	if !strings.Contains(md, "## By Project") {
+		t.Fatal("markdown should contain By Project section")
+	}
+	proj1 := strings.Index(md, "| PROJ1 | 2 | 85 | 10180 | 600 |")
+	ops := strings.Index(md, "| OPS | 1 | 3 | 90 | 1 |")
+	if proj1 < 0 || ops < 0 {
+		t.Fatalf("expected project rows, got:\n%s", md)
+	}
+	if proj1 > ops {
+		t.Error("projects should be sorted by code descending")
+	}
+}
+
+func TestWriteMarkdownByProjectOmittedWithoutProjects(t *testing.T) {
+	report := sampleReport()
+	for i := range report.Repositories {
+		report.Repositories[i].Project = ""
+	}
+
+	var buf bytes.Buffer
+	output.WriteMarkdown(&buf, report)
+	if strings.Contains(buf.String(), "## By Project") {
+		t.Error("By Project section should be omitted when no repo has a project")
+	}
+}
+
+func TestWriteMarkdownLanguagePercent(t *testing.T) {
+	report := sampleReport()
+	report.ByLanguage[0].CodePercent = 58.94
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| TypeScript | 50 | 6000 | 58.9% |") {
+		t.Error("markdown Languages table should include the code percentage")
+	}
+}
+
+func TestWriteMarkdownRepoSizeColumn(t *testing.T) {
+	report := sampleReport()
+	var buf bytes.Buffer
+	output.WriteMarkdown(&buf, report)
+	if strings.Contains(buf.String(), "Size (KB)") {
+		t.Error("expected no size column when no repo has a size")
+	}
+
+	report.Repositories[0].RepoSizeKB = 1536
+	buf.Reset()
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| Size (KB) |") || !strings.Contains(buf.String(), " | 1536 |") || !strings.Contains(buf.String(), " | \u2014 |") {
+		t.Errorf("expected size column with a dash for the unsized repo:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownDocumentationShare(t *testing.T) {
+	report := sampleReport()
+	report.ByLanguage = append(report.ByLanguage, model.LanguageStats{Name: "Documentation", Files: 4, Code: 900, CodePercent: 8.1})
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| Documentation | 900 (8.1% of code) |") {
+		t.Errorf("expected documentation share in summary:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownOmitsLanguagesWithoutCode(t *testing.T) {
+	report := sampleReport()
+	report.ByLanguage = append(report.ByLanguage, model.LanguageStats{Name: "JSON", Files: 3, Lines: 40, Blanks: 40})
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "| JSON |") || !strings.Contains(buf.String(), "1 languages with no code omitted") {
+		t.Errorf("expected JSON row to be omitted with a note:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := output.WriteMarkdown(&buf, report, output.AllLanguages()); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| JSON | 3 | 0 |") || strings.Contains(buf.String(), "omitted") {
+		t.Errorf("expected JSON row with AllLanguages:\n%s", buf.String())
+	}
+}
+
+func TestWriteMarkdownComplexityWarnings(t *testing.T) {
+	report := sampleReport()
+	report.ComplexityWarnings = []model.ComplexityWarning{
+		{Repository: "api", Complexity: 900, Threshold: 500},
+		{Repository: "api", Language: "Go", Complexity: 700, Threshold: 300},
+		{Repository: "api", Path: "core/engine.go", Complexity: 600, Threshold: 500},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## Complexity Warnings",
+		"| api | repository | 900 | 500 |",
+		"| api | Go | 700 | 300 |",
+		"| api | core/engine.go | 600 | 500 |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+
+	buf.Reset()
+	if err := output.WriteMarkdown(&buf, sampleReport()); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "Complexity Warnings") {
+		t.Error("Complexity Warnings section should be omitted without warnings")
+	}
+}
+
+func TestWriteMarkdownVelocityLabel(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Health = &model.RepoHealth{Category: model.HealthActive}
+	report.Repositories[0].HealthDetails = &model.RepoHealthDetails{
+		ChurnByWindow: map[string]model.WindowChurnStats{"0-6mo": {Commits: 5}, "6-12mo": {Commits: 10}},
+		VelocityTrend: 0.5,
+		VelocityLabel: "slowing",
+	}
+	report.Repositories[1].Health = &model.RepoHealth{Category: model.HealthActive}
+	report.Repositories[1].HealthDetails = &model.RepoHealthDetails{
+		ChurnByWindow: map[string]model.WindowChurnStats{"0-6mo": {Commits: 3}},
+		VelocityLabel: "accelerating",
+	}
+	report.HealthSummary = &model.HealthSummary{Active: model.HealthCategorySummary{Repos: 2}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	for _, want := range []string{"| api-service | slowing | 0.50x |", "| web-app | accelerating | — |"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
+
+func TestWriteMarkdownHealthThresholds(t *testing.T) {
+	report := sampleReport()
+	report.HealthSummary = &model.HealthSummary{Active: model.HealthCategorySummary{Repos: 2}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| Active (<180d) |") || !strings.Contains(buf.String(), "| Abandoned (>365d) |") {
+		t.Errorf("expected the default thresholds without a run config, got:\n%s", buf.String())
+	}
+
+	report.RunConfig = &model.RunConfig{HealthActiveDays: 30, HealthMaintainedDays: 90}
+	buf.Reset()
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	for _, want := range []string{"| Active (<30d) |", "| Maintained (30-90d) |", "| Abandoned (>90d) |"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown missing %q", want)
+		}
+	}
+}
+
+func TestWriteMarkdownCommitCounts(t *testing.T) {
+	report := sampleReport()
+	full, capped := 42, 500
+	report.Repositories[0].CommitCount = &full
+	report.Repositories[1].CommitCount = &capped
+	report.Repositories[1].CommitCountTruncated = true
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "| Commits |") || !strings.Contains(out, " | 42 |") || !strings.Contains(out, " | 500+ |") {
+		t.Errorf("expected a Commits column with a truncation marker:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownTopLanguages(t *testing.T) {
+	report := sampleReport()
+	report.ByLanguage = []model.LanguageStats{
+		{Name: "Go", Files: 10, Code: 700, CodePercent: 70},
+		{Name: "Python", Files: 5, Code: 200, CodePercent: 20},
+		{Name: "Shell", Files: 3, Code: 60, CodePercent: 6},
+		{Name: "YAML", Files: 2, Code: 40, CodePercent: 4},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report, output.TopLanguages(2)); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "| Python | 5 | 200 | 20.0% |") {
+		t.Error("expected the top languages to be listed")
+	}
+	if strings.Contains(out, "| Shell |") || !strings.Contains(out, "| Other (2 languages) | 5 | 100 | 10.0% |") {
+		t.Errorf("expected Shell and YAML rolled into Other:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownInfraTag(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[1].IsInfraOnly = true
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if n := strings.Count(buf.String(), "`infra`"); n != 1 {
+		t.Errorf("expected one infra tag, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestWriteMarkdownDetectionSignals(t *testing.T) {
+	report := sampleReport()
+	report.AIEstimate = &model.AIEstimate{
+		TotalCommits: 100,
+		AICommits:    10,
+		SignalCounts: map[model.AISignal]int64{model.SignalCommitMessage: 3, model.SignalCoAuthor: 8},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	coAuthor := strings.Index(out, "| co-author | 8 | 80.0% |")
+	message := strings.Index(out, "| commit-message | 3 | 30.0% |")
+	if !strings.Contains(out, "### Detection Signals") || coAuthor < 0 || message < coAuthor {
+		t.Errorf("expected Detection Signals table sorted by count:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownCustomHealthWindows(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Health = &model.RepoHealth{Category: model.HealthActive}
+	report.Repositories[0].HealthDetails = &model.RepoHealthDetails{
+		AuthorsByWindow: map[string]int{"0-3mo": 1, "3-6mo": 2, "12mo+": 1},
+		ChurnByWindow:   map[string]model.WindowChurnStats{"0-3mo": {Commits: 1}, "3-6mo": {Commits: 4}, "12mo+": {Commits: 2}},
+	}
+	report.HealthSummary = &model.HealthSummary{Active: model.HealthCategorySummary{Repos: 1}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	first := strings.Index(out, "| api-service | 0-3mo | 1 | 1 |")
+	second := strings.Index(out, "| api-service | 3-6mo | 2 | 4 |")
+	last := strings.Index(out, "| api-service | 12mo+ | 1 | 2 |")
+	if first < 0 || second < 0 || last < 0 || !(first < second && second < last) {
+		t.Errorf("expected 0-3mo, 3-6mo, 12mo+ rows in order:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownLargeFiles(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].LargeFiles = []model.LargeFile{{Path: "assets/demo.mp4", Size: 15 << 20}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "## Large Files") || !strings.Contains(buf.String(), "| api-service | assets/demo.mp4 | 15.0 |") {
+		t.Error("markdown should include the Large Files table")
+	}
+}
+
+func TestWriteMarkdownChurnByCategory(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Churn = &model.ChurnStats{
+		TotalCommits: 4,
+		TopFiles:     []model.FileChurn{{Path: "main.go", Changes: 3, Additions: 40, Deletions: 8}},
+		ByCategory: map[string]model.CategoryChurn{
+			"code": {Files: 1, Changes: 3, Additions: 40, Deletions: 8},
+			"docs": {Files: 2, Changes: 2, Additions: 12, Deletions: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	code := strings.Index(out, "| Code | 1 | 3 | 40 | 8 |")
+	docs := strings.Index(out, "| Docs | 2 | 2 | 12 | 1 |")
+	if code < 0 || docs < 0 || docs < code {
+		t.Errorf("expected churn category rows in display order, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownChurnWithoutFileStats(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Churn = &model.ChurnStats{TotalCommits: 12, TopFiles: []model.FileChurn{}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "## Code Churn") || !strings.Contains(out, "### "+report.Repositories[0].Repository) {
+		t.Fatalf("expected a churn entry for the repo, got:\n%s", out)
+	}
+	if !strings.Contains(out, "no per-file stats were available") {
+		t.Errorf("expected a note about missing file stats, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownChurnCouplings(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Churn = &model.ChurnStats{
+		TotalCommits: 3,
+		TopFiles:     []model.FileChurn{{Path: "model.go", Changes: 3}, {Path: "schema.sql", Changes: 3}},
+		Couplings:    []model.FileCoupling{{FileA: "model.go", FileB: "schema.sql", CoChanges: 3, Confidence: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "**Change Coupling**") || !strings.Contains(out, "| model.go | schema.sql | 3 | 100% |") {
+		t.Errorf("expected a change coupling table, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownChurnAuthors(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Churn = &model.ChurnStats{
+		TotalCommits: 3,
+		TopFiles:     []model.FileChurn{{Path: "main.go", Changes: 3}},
+		AuthorChurn:  []model.AuthorChurn{{Author: "alice@example.com", Commits: 2, Additions: 55, Deletions: 5}},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "**Top Authors**") || !strings.Contains(out, "| alice@example.com | 2 | 55 | 5 |") {
+		t.Errorf("expected a top authors table, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownAbandonedRepositories(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Health = &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: 500}
+	report.Repositories[1].Health = &model.RepoHealth{Category: model.HealthAbandoned, DaysSinceCommit: 400}
+	report.Repositories[1].Totals.Code = report.Repositories[0].Totals.Code + 1
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	first := fmt.Sprintf("| %s | 400 | %d |", report.Repositories[1].Repository, report.Repositories[1].Totals.Code)
+	second := fmt.Sprintf("| %s | 500 | %d |", report.Repositories[0].Repository, report.Repositories[0].Totals.Code)
+	if !strings.Contains(out, "## Abandoned Repositories") || !strings.Contains(out, first) || !strings.Contains(out, second) {
+		t.Fatalf("expected abandoned repositories table, got:\n%s", out)
+	}
+	if strings.Index(out, first) > strings.Index(out, second) {
+		t.Error("expected abandoned repositories sorted by code, largest first")
+	}
+}
+
+func TestWriteMarkdownDecommissionCandidates(t *testing.T) {
+	report := sampleReport()
+	report.RiskRepos = []model.RiskRepo{{Repository: "legacy", Code: 50000, DaysSinceCommit: 400, Score: 20000000}}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "## Decommission Candidates") || !strings.Contains(out, "| legacy | 50000 | 400 | 20000000 |") {
+		t.Errorf("expected decommission candidates table, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownCodeOwnership(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Churn = &model.ChurnStats{
+		TotalCommits: 4,
+		TopFiles: []model.FileChurn{
+			{Path: "main.go", Changes: 4, Owner: "alice@example.com", OwnerShare: 75},
+			{Path: "go.mod", Changes: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	row := "| " + report.Repositories[0].Repository + " | main.go | alice@example.com | 75% | 4 |"
+	if !strings.Contains(out, "## Code Ownership") || !strings.Contains(out, row) {
+		t.Errorf("expected code ownership row %q, got:\n%s", row, out)
+	}
+	if strings.Contains(out, "| go.mod |  |") {
+		t.Error("files without an owner should be left out")
+	}
+}
+
+func TestWriteMarkdownSplitTests(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].TestFiles = 4
+	report.Repositories[0].TestCode = report.Repositories[0].Totals.Code / 2
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "| Test Files | 4 |") || !strings.Contains(out, "| Test Code | Test Ratio") {
+		t.Errorf("expected test summary and columns, got:\n%s", out)
+	}
+	if !strings.Contains(out, "| 0.50 |") {
+		t.Errorf("expected a 0.50 test ratio for the first repo, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownCoAuthorship(t *testing.T) {
+	report := sampleReport()
+	report.CoAuthorship = []model.CoAuthorPair{
+		{AuthorA: "alice@example.com", AuthorB: "bob@example.com", Commits: 4},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	if !strings.Contains(buf.String(), "## Co-Authorship") || !strings.Contains(buf.String(), "| alice@example.com | bob@example.com | 4 |") {
+		t.Error("markdown should include the Co-Authorship table")
+	}
+}
+
+func TestWriteMarkdownDistribution(t *testing.T) {
+	report := sampleReport()
+	report.Distribution = &model.Distribution{
+		Repos:      2,
+		Code:       model.Percentiles{P50: 100, P90: 900, P99: 900},
+		Complexity: model.Percentiles{P50: 5, P90: 40, P99: 40},
+	}
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "## Distribution") || !strings.Contains(out, "| Code | 100 | 900 | 900 |") || !strings.Contains(out, "| Complexity | 5 | 40 | 40 |") {
+		t.Errorf("expected distribution table, got:\n%s", out)
+	}
+}
+
+func TestWriteMarkdownActivityHeatmap(t *testing.T) {
+	report := sampleReport()
+	report.ActivityHeatmap = &model.ActivityHeatmap{Commits: 4}
+	report.ActivityHeatmap.Counts[time.Monday][9] = 3
+	report.ActivityHeatmap.Counts[time.Saturday][11] = 1
+
+	var buf bytes.Buffer
+	if err := output.WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "## Commit Activity") || !strings.Contains(out, "25.0% on weekends") {
+		t.Errorf("markdown should include the Commit Activity section, got:\n%s", out)
+	}
+	if !strings.Contains(out, "| Mon | 0 | 0 | 0 | 0 | 0 | 0 | 0 | 0 | 0 | 3 |") {
+		t.Error("expected Monday row with 3 commits at 09:00")
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	report := sampleReport()
+	report.RunConfig = &model.RunConfig{Provider: report.Provider, Phases: []string{"analyze"}, Concurrency: 5}
+	report.RiskRepos = []model.RiskRepo{{Repository: "api-service"}}
+
+	var buf bytes.Buffer
+	if err := output.WriteNDJSON(&buf, report); err != nil {
+		t.Fatalf("WriteNDJSON: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 1+len(report.Repositories) {
+		t.Fatalf("expected %d lines, got %d", 1+len(report.Repositories), len(lines))
+	}
+
+	var meta struct {
+		Type       string           `json:"type"`
+		Provider   string           `json:"provider"`
+		TotalRepos int              `json:"total_repos"`
+		Totals     model.Stats      `json:"totals"`
+		Repos      any              `json:"repositories"`
+		RunConfig  *model.RunConfig `json:"run_config"`
+		RiskRepos  []model.RiskRepo `json:"risk_repos"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &meta); err != nil {
+		t.Fatalf("metadata line is not valid JSON: %v", err)
+	}
+	if meta.Type != "metadata" || meta.Provider != report.Provider {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if meta.TotalRepos != len(report.Repositories) || meta.Totals.Code != report.Totals.Code {
+		t.Errorf("metadata totals mismatch: %+v", meta)
+	}
+	if meta.Repos != nil {
+		t.Error("metadata line should not embed repositories")
+	}
+	if meta.RunConfig == nil || meta.RunConfig.Concurrency != 5 {
+		t.Errorf("expected run_config in the metadata line, got %+v", meta.RunConfig)
+	}
+	if len(meta.RiskRepos) != 1 {
+		t.Errorf("expected risk_repos in the metadata line, got %+v", meta.RiskRepos)
+	}
+
+	for i, line := range lines[1:] {
+		var repo struct {
+			Type string `json:"type"`
+			model.RepoStats
+		}
+		if err := json.Unmarshal([]byte(line), &repo); err != nil {
+			t.Fatalf("repo line %d is not valid JSON: %v", i, err)
+		}
+		if repo.Type != "repo" || repo.Repository != report.Repositories[i].Repository {
+			t.Errorf("line %d: expected repo %s, got type %q repo %q", i, report.Repositories[i].Repository, repo.Type, repo.Repository)
+		}
+		if repo.Totals.Code != report.Repositories[i].Totals.Code {
+			t.Errorf("line %d: expected code %d, got %d", i, report.Repositories[i].Totals.Code, repo.Totals.Code)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	report := sampleReport()
+
+	var buf bytes.Buffer
+	if err := output.WriteCSV(&buf, report); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(rows) != 1+len(report.Repositories) {
+		t.Fatalf("expected %d rows, got %d", 1+len(report.Repositories), len(rows))
+	}
+	want := "repository,project,provider,license,files,code,comments,blanks,complexity"
+	if got := strings.Join(rows[0], ","); got != want {
+		t.Errorf("expected header %q, got %q", want, got)
+	}
+	if got := strings.Join(rows[1], ","); got != "api-service,PROJ1,bitbucket,,35,4180,510,510,200" {
+		t.Errorf("unexpected first row: %q", got)
+	}
+
+	report.Repositories[0].Health = &model.RepoHealth{Category: model.HealthActive, DaysSinceCommit: 3}
+	report.Repositories[1].AIEstimate = &model.AIEstimate{CommitPercent: 12.5, AIAdditions: 40}
+	buf.Reset()
+	if err := output.WriteCSV(&buf, report); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows, _ = csv.NewReader(&buf).ReadAll()
+	if got := strings.Join(rows[0][9:], ","); got != "health,days_since_commit,ai_commit_percent,ai_additions" {
+		t.Errorf("expected health and AI columns, got %q", got)
+	}
+	if got := strings.Join(rows[1][9:], ","); got != "active,3,," {
+		t.Errorf("unexpected health cells: %q", got)
+	}
+	if got := strings.Join(rows[2][9:], ","); got != ",,12.5,40" {
+		t.Errorf("unexpected AI cells: %q", got)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Repository = "api-<service>"
+	report.Repositories[0].Churn = &model.ChurnStats{
+		TotalCommits: 3,
+		TopFiles:     []model.FileChurn{{Path: "main.go", Changes: 3}},
+	}
+	report.HealthSummary = &model.HealthSummary{Active: model.HealthCategorySummary{Repos: 2}}
+
+	var buf bytes.Buffer
+	if err := output.WriteHTML(&buf, report); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	out := buf.String()
+
+	doc, err := html.Parse(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("output is not valid HTML: %v", err)
+	}
+	var links []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			for _, a := range n.Attr {
+				if a.Key == "href" {
+					links = append(links, a.Val)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if len(links) != 2 || links[1] != "https://bitbucket.org/myworkspace/web-app" {
+		t.Errorf("expected a link per repository, got %v", links)
+	}
+
+	if !strings.Contains(out, "web-app") || !strings.Contains(out, "api-&lt;service&gt;") {
+		t.Errorf("expected escaped repository names, got:\n%s", out)
+	}
+	for _, want := range []string{"<h2>Repository Health</h2>", "<h2>Languages</h2>", "<h2>Code Churn</h2>", "<style>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<link") || strings.Contains(out, "<script") {
+		t.Error("expected a self-contained page without external assets")
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	report := sampleReport()
+	report.AIEstimate = &model.AIEstimate{CommitPercent: 25}
+	report.Repositories[0].Health = &model.RepoHealth{Category: model.HealthActive, DaysSinceCommit: 12}
+	report.Repositories[1].Health = &model.RepoHealth{Category: model.HealthFailed, Error: "no commits"}
+
+	var buf bytes.Buffer
+	if err := output.WritePrometheus(&buf, report); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE codemium_repo_code_lines gauge",
+		`codemium_repo_code_lines{repository="api-service",provider="bitbucket"} 4180`,
+		"codemium_total_complexity 600",
+		"codemium_ai_commit_percent 25",
+		`codemium_language_code{language="Go"} 4000`,
+		`codemium_health_days_since_commit{repository="api-service",provider="bitbucket"} 12`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Contains(out, `codemium_health_days_since_commit{repository="`+report.Repositories[1].Repository) {
+		t.Error("expected no days-since-commit gauge for a repo whose health check failed")
+	}
+}
+
+func TestWritePrometheusEscapesLabels(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].Repository = `we"ird\name`
+
+	var buf bytes.Buffer
+	if err := output.WritePrometheus(&buf, report); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `repository="we\"ird\\name"`) {
+		t.Errorf("expected escaped label value, got:\n%s", buf.String())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	bitbucket := sampleReport()
+	github := model.Report{
+		GeneratedAt:  "2026-02-18T12:05:00Z",
+		Provider:     "github",
+		Organization: "myorg",
+		Repositories: []model.RepoStats{
+			{
+				Repository: "cli",
+				Provider:   "github",
+				Languages:  []model.LanguageStats{{Name: "Go", Files: 10, Code: 1000}},
+				Totals:     model.Stats{Files: 10, Code: 1000},
+			},
+		},
+		Totals:     model.Stats{Repos: 1, Files: 10, Code: 1000},
+		ByLanguage: []model.LanguageStats{{Name: "Go", Files: 10, Code: 1000}},
+		Errors:     []model.RepoError{{Repository: "broken", Error: "clone failed"}},
+		AIEstimate: &model.AIEstimate{TotalCommits: 10, AICommits: 5, SignalCounts: map[model.AISignal]int64{model.SignalCoAuthor: 5}},
+		Timing:     &model.Timing{TotalSeconds: 2, Phases: []model.PhaseTiming{{Phase: "list", Seconds: 1}}},
+	}
+	bitbucket.Timing = &model.Timing{TotalSeconds: 3, Phases: []model.PhaseTiming{{Phase: "list", Seconds: 0.5}}}
+
+	merged := output.Merge(bitbucket, github)
+
+	if merged.Provider != output.MergedProvider || merged.GeneratedAt != bitbucket.GeneratedAt {
+		t.Errorf("unexpected metadata: provider %q, generated_at %q", merged.Provider, merged.GeneratedAt)
+	}
+	if merged.Workspace != "" || merged.Organization != "" {
+		t.Errorf("expected workspace and organization to be dropped, got %q %q", merged.Workspace, merged.Organization)
+	}
+	if len(merged.Repositories) != 3 || merged.Repositories[2].Provider != "github" {
+		t.Fatalf("expected repositories from both reports, got %+v", merged.Repositories)
+	}
+	if merged.Totals.Repos != 3 || merged.Totals.Code != bitbucket.Totals.Code+1000 {
+		t.Errorf("unexpected totals: %+v", merged.Totals)
+	}
+	if len(merged.Errors) != 1 {
+		t.Errorf("expected 1 error, got %d", len(merged.Errors))
+	}
+
+	var goLang *model.LanguageStats
+	for i := range merged.ByLanguage {
+		if merged.ByLanguage[i].Name == "Go" {
+			goLang = &merged.ByLanguage[i]
+		}
+	}
+	if goLang == nil || goLang.Code != 5000 || goLang.Files != 40 {
+		t.Fatalf("expected Go totals summed across reports, got %+v", goLang)
+	}
+	if want := float64(5000) / float64(merged.Totals.Code) * 100; goLang.CodePercent != want {
+		t.Errorf("expected Go code percent %.2f, got %.2f", want, goLang.CodePercent)
+	}
+
+	if merged.AIEstimate == nil || merged.AIEstimate.AICommits != 5 || merged.AIEstimate.CommitPercent != 50 {
+		t.Errorf("unexpected AI estimate: %+v", merged.AIEstimate)
+	}
+	if merged.AIEstimate.SignalCounts[model.SignalCoAuthor] != 5 {
+		t.Errorf("expected signal counts to be merged, got %v", merged.AIEstimate.SignalCounts)
+	}
+	if merged.Timing == nil || merged.Timing.TotalSeconds != 5 || len(merged.Timing.Phases) != 1 || merged.Timing.Phases[0].Seconds != 1.5 {
+		t.Errorf("unexpected timing: %+v", merged.Timing)
+	}
+}
+
+func TestRedactURLs(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].ForkParent = "https://git.internal.example.com/platform/api-service.git"
+	report.Repositories[1].ForkParent = "git@git.internal.example.com:platform/web-app.git"
+
+	redacted := output.RedactURLs(report)
+	for i, want := range []string{"api-service", "web-app"} {
+		r := redacted.Repositories[i]
+		if r.URL != want || r.ForkParent != want {
+			t.Errorf("repo %d: got url %q fork_parent %q, want %q", i, r.URL, r.ForkParent, want)
+		}
+	}
+	if report.Repositories[0].URL != "https://bitbucket.org/myworkspace/api-service" {
+		t.Error("RedactURLs modified its input")
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	report := sampleReport()
+	report.Repositories[0].AIEstimate = &model.AIEstimate{
+		AICommits: 3,
+		Details: []model.AICommit{
+			{Hash: "a1", Author: "Alice <Alice@Example.com>"},
+			{Hash: "b2", Author: "alice <alice@example.com>"},
+			{Hash: "c3", Author: "dependabot[bot] <bot@github.com>"},
+		},
+	}
+	report.Repositories[0].CoAuthorship = []model.CoAuthorPair{
+		{AuthorA: "alice@example.com", AuthorB: "bob@example.com", Commits: 2},
+	}
+	report.CoAuthorship = report.Repositories[0].CoAuthorship
+	report.Repositories[0].Churn = &model.ChurnStats{
+		AuthorChurn: []model.AuthorChurn{
+			{Author: "alice@example.com", Commits: 5, Additions: 120},
+			{Author: "dependabot[bot] <bot@github.com>", Commits: 2},
+		},
+	}
+
+	anon := output.Anonymize(report, []byte("salt"))
+
+	details := anon.Repositories[0].AIEstimate.Details
+	if !strings.HasPrefix(details[0].Author, "author-") || details[0].Author != details[1].Author {
+		t.Errorf("expected one stable pseudonym for alice, got %q and %q", details[0].Author, details[1].Author)
+	}
+	if details[2].Author != "dependabot[bot] <bot@github.com>" {
+		t.Errorf("bot author should be kept, got %q", details[2].Author)
+	}
+	pair := anon.CoAuthorship[0]
+	if pair.AuthorA != details[0].Author && pair.AuthorB != details[0].Author {
+		t.Errorf("co-authorship pseudonym should match AI commit author: %+v", pair)
+	}
+	if pair.AuthorA > pair.AuthorB || pair.Commits != 2 {
+		t.Errorf("unexpected pair: %+v", pair)
+	}
+
+	authors := anon.Repositories[0].Churn.AuthorChurn
+	if authors[0].Author != details[0].Author || authors[0].Commits != 5 || authors[0].Additions != 120 {
+		t.Errorf("expected alice's churn under her pseudonym, got %+v", authors[0])
+	}
+	if authors[1].Author != "dependabot[bot] <bot@github.com>" {
+		t.Errorf("bot churn author should be kept, got %q", authors[1].Author)
+	}
+
+	if report.Repositories[0].AIEstimate.Details[0].Author != "Alice <Alice@Example.com>" {
+		t.Error("Anonymize modified the input report")
+	}
+	if report.Repositories[0].Churn.AuthorChurn[0].Author != "alice@example.com" {
+		t.Error("Anonymize modified the input churn")
+	}
+	if other := output.Anonymize(report, []byte("other salt")); other.Repositories[0].AIEstimate.Details[0].Author == details[0].Author {
+		t.Error("expected a different salt to give different pseudonyms")
+	}
+}
@@ -0,0 +1,101 @@
+// internal/output/prometheus.go
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// promLabelEscaper escapes label values per the Prometheus exposition format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promLabels renders label pairs as {k1="v1",k2="v2"}. pairs alternates keys and values.
+func promLabels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], promLabelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// promGauge writes the HELP and TYPE header for a gauge metric.
+func promGauge(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+}
+
+// WritePrometheus writes the report as Prometheus exposition-format gauges,
+// suitable for the node-exporter textfile collector.
+func WritePrometheus(w io.Writer, report model.Report) error {
+	repoLabels := func(r model.RepoStats) string {
+		return promLabels("repository", r.Repository, "provider", r.Provider)
+	}
+
+	promGauge(w, "codemium_repo_code_lines", "Lines of code per repository.")
+	for _, r := range report.Repositories {
+		fmt.Fprintf(w, "codemium_repo_code_lines%s %d\n", repoLabels(r), r.Totals.Code)
+	}
+
+	promGauge(w, "codemium_repo_comment_lines", "Comment lines per repository.")
+	for _, r := range report.Repositories {
+		fmt.Fprintf(w, "codemium_repo_comment_lines%s %d\n", repoLabels(r), r.Totals.Comments)
+	}
+
+	promGauge(w, "codemium_repo_files", "Analyzed files per repository.")
+	for _, r := range report.Repositories {
+		fmt.Fprintf(w, "codemium_repo_files%s %d\n", repoLabels(r), r.Totals.Files)
+	}
+
+	promGauge(w, "codemium_repo_complexity", "Cyclomatic complexity per repository.")
+	for _, r := range report.Repositories {
+		fmt.Fprintf(w, "codemium_repo_complexity%s %d\n", repoLabels(r), r.Totals.Complexity)
+	}
+
+	promGauge(w, "codemium_total_repos", "Number of analyzed repositories.")
+	fmt.Fprintf(w, "codemium_total_repos %d\n", report.Totals.Repos)
+
+	promGauge(w, "codemium_total_code_lines", "Lines of code across all repositories.")
+	fmt.Fprintf(w, "codemium_total_code_lines %d\n", report.Totals.Code)
+
+	promGauge(w, "codemium_total_complexity", "Cyclomatic complexity across all repositories.")
+	fmt.Fprintf(w, "codemium_total_complexity %d\n", report.Totals.Complexity)
+
+	promGauge(w, "codemium_language_code", "Lines of code per language across all repositories.")
+	for _, lang := range report.ByLanguage {
+		fmt.Fprintf(w, "codemium_language_code%s %d\n", promLabels("language", lang.Name), lang.Code)
+	}
+
+	var withHealth []model.RepoStats
+	for _, r := range report.Repositories {
+		if r.Health != nil && r.Health.Error == "" {
+			withHealth = append(withHealth, r)
+		}
+	}
+	if len(withHealth) > 0 {
+		promGauge(w, "codemium_health_days_since_commit", "Days since the last commit on the default branch per repository.")
+		for _, r := range withHealth {
+			fmt.Fprintf(w, "codemium_health_days_since_commit%s %d\n", repoLabels(r), r.Health.DaysSinceCommit)
+		}
+	}
+
+	if report.AIEstimate != nil {
+		promGauge(w, "codemium_ai_commit_percent", "Percentage of scanned commits attributed to AI.")
+		fmt.Fprintf(w, "codemium_ai_commit_percent %g\n", report.AIEstimate.CommitPercent)
+
+		promGauge(w, "codemium_repo_ai_commit_percent", "Percentage of scanned commits attributed to AI per repository.")
+		for _, r := range report.Repositories {
+			if r.AIEstimate == nil {
+				continue
+			}
+			fmt.Fprintf(w, "codemium_repo_ai_commit_percent%s %g\n", repoLabels(r), r.AIEstimate.CommitPercent)
+		}
+	}
+
+	return nil
+}
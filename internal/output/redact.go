@@ -0,0 +1,46 @@
+// internal/output/redact.go
+package output
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// RedactURLs returns a copy of report with repository URLs replaced by the
+// repository slug and fork parent clone URLs reduced to theirs, dropping the
+// host and namespace path that can reveal internal hostnames or group
+// structure.
+func RedactURLs(report model.Report) model.Report {
+	report.Repositories = append([]model.RepoStats(nil), report.Repositories...)
+	for i := range report.Repositories {
+		r := &report.Repositories[i]
+		if r.URL != "" {
+			r.URL = r.Repository
+		}
+		r.ForkParent = redactURL(r.ForkParent)
+	}
+	return report
+}
+
+// redactURL returns the last path segment of a clone URL without its .git
+// suffix, e.g. "api" for "https://git.internal/team/api.git". SCP-style
+// URLs (git@host:team/api.git) are handled too.
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	p := rawURL
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		p = u.Path
+	} else if i := strings.LastIndex(rawURL, ":"); i >= 0 {
+		p = rawURL[i+1:]
+	}
+	slug := strings.TrimSuffix(path.Base(strings.TrimRight(p, "/")), ".git")
+	if slug == "." || slug == "/" {
+		return ""
+	}
+	return slug
+}
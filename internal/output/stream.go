@@ -0,0 +1,119 @@
+// internal/output/stream.go
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// JSONStream writes a report incrementally: the envelope (generated_at,
+// provider, workspace, organization) first, then each repository as it is
+// added, and the totals and other report-level fields on Close. Until Close,
+// the output is a truncated but otherwise valid JSON document, so a crashed
+// run still leaves every finished repository on disk. The result decodes to
+// the same model.Report as WriteJSON, with keys in a different order.
+//
+// A JSONStream is not safe for concurrent use; worker.RunWithResults
+// serializes its callbacks.
+type JSONStream struct {
+	w       io.Writer
+	compact bool
+	repos   int
+}
+
+// streamHead is the envelope written before any repository.
+type streamHead struct {
+	GeneratedAt  string `json:"generated_at"`
+	Provider     string `json:"provider"`
+	Workspace    string `json:"workspace,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// streamTail is the rest of the report. Its own zero-valued, omitempty
+// fields shadow the embedded report's envelope and repositories, which were
+// already written.
+type streamTail struct {
+	model.Report
+	GeneratedAt  string            `json:"generated_at,omitempty"`
+	Provider     string            `json:"provider,omitempty"`
+	Workspace    string            `json:"workspace,omitempty"`
+	Organization string            `json:"organization,omitempty"`
+	Repositories []model.RepoStats `json:"repositories,omitempty"`
+}
+
+// NewJSONStream writes the envelope of header to w and returns a stream
+// ready for WriteRepo. Only header's envelope fields are used.
+func NewJSONStream(w io.Writer, header model.Report, opts ...JSONOption) (*JSONStream, error) {
+	cfg := applyJSONOptions(opts)
+	s := &JSONStream{w: w, compact: cfg.compact}
+
+	head, err := s.marshal(streamHead{
+		GeneratedAt:  header.GeneratedAt,
+		Provider:     header.Provider,
+		Workspace:    header.Workspace,
+		Organization: header.Organization,
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	// Reopen the object to append the repositories array
+	head = bytes.TrimSuffix(head, []byte("}"))
+	if s.compact {
+		head = append(head, `,"repositories":[`...)
+	} else {
+		head = append(bytes.TrimRight(head, "\n"), ",\n  \"repositories\": ["...)
+	}
+	if _, err := w.Write(head); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteRepo appends one repository to the report.
+func (s *JSONStream) WriteRepo(r model.RepoStats) error {
+	data, err := s.marshal(r, "    ")
+	if err != nil {
+		return err
+	}
+	var sep string
+	switch {
+	case s.repos > 0 && s.compact:
+		sep = ","
+	case s.repos > 0:
+		sep = ",\n    "
+	case !s.compact:
+		sep = "\n    "
+	}
+	s.repos++
+	_, err = s.w.Write(append([]byte(sep), data...))
+	return err
+}
+
+// Close closes the repositories array and writes everything in report except
+// its envelope and repositories, which must already have been written.
+func (s *JSONStream) Close(report model.Report) error {
+	tail, err := s.marshal(streamTail{Report: report}, "")
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if s.repos > 0 && !s.compact {
+		buf.WriteString("\n  ")
+	}
+	buf.WriteString("],")
+	buf.Write(bytes.TrimPrefix(tail, []byte("{")))
+	buf.WriteString("\n")
+	_, err = s.w.Write(buf.Bytes())
+	return err
+}
+
+// marshal encodes v, indenting nested lines with prefix unless compact.
+func (s *JSONStream) marshal(v any, prefix string) ([]byte, error) {
+	if s.compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, prefix, "  ")
+}
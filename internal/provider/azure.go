@@ -0,0 +1,358 @@
+// internal/provider/azure.go
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+const (
+	azureAPIBase    = "https://dev.azure.com"
+	azureAPIVersion = "7.1"
+	azurePageSize   = 100
+)
+
+// AzureDevOps implements Provider and ChurnLister for Azure DevOps
+// Services (or Server, with a collection URL as baseURL).
+type AzureDevOps struct {
+	token   string
+	org     string
+	baseURL string
+	client  *http.Client
+}
+
+// NewAzureDevOps creates a new Azure DevOps provider for the org
+// organization, authenticating with a personal access token. If baseURL is
+// empty, the default dev.azure.com endpoint is used.
+func NewAzureDevOps(token, org, baseURL string, client *http.Client) *AzureDevOps {
+	if baseURL == "" {
+		baseURL = azureAPIBase
+	}
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	return &AzureDevOps{
+		token:   token,
+		org:     org,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+	}
+}
+
+type azureRepo struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	WebURL        string `json:"webUrl"`
+	RemoteURL     string `json:"remoteUrl"`
+	DefaultBranch string `json:"defaultBranch"`
+	Size          int64  `json:"size"` // bytes
+	IsDisabled    bool   `json:"isDisabled"`
+	IsFork        bool   `json:"isFork"`
+	Project       struct {
+		Name string `json:"name"`
+	} `json:"project"`
+}
+
+// repo converts a repository from the API into a model.Repo. Disabled
+// repositories count as archived.
+func (r azureRepo) repo() model.Repo {
+	return model.Repo{
+		Name:          r.Name,
+		Slug:          r.Name,
+		Project:       r.Project.Name,
+		URL:           r.WebURL,
+		CloneURL:      stripUserinfo(r.RemoteURL),
+		Provider:      "azure",
+		DefaultBranch: strings.TrimPrefix(r.DefaultBranch, "refs/heads/"),
+		Archived:      r.IsDisabled,
+		Fork:          r.IsFork,
+		SizeKB:        r.Size / 1024,
+	}
+}
+
+// stripUserinfo drops the "org@" user Azure DevOps puts in remote URLs, so
+// the Cloner's basic auth (any username, the PAT as password) applies.
+func stripUserinfo(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	u.User = nil
+	return u.String()
+}
+
+// ListRepos lists the Git repositories of each project in opts.Projects, or
+// of every project in the organization when none are given.
+func (a *AzureDevOps) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, error) {
+	if a.org == "" {
+		return nil, fmt.Errorf("azure devops organization is required")
+	}
+
+	var listURLs []string
+	if len(opts.Projects) == 0 {
+		listURLs = append(listURLs, fmt.Sprintf("%s/%s/_apis/git/repositories?api-version=%s",
+			a.baseURL, url.PathEscape(a.org), azureAPIVersion))
+	}
+	for _, p := range opts.Projects {
+		listURLs = append(listURLs, fmt.Sprintf("%s/%s/%s/_apis/git/repositories?api-version=%s",
+			a.baseURL, url.PathEscape(a.org), url.PathEscape(p), azureAPIVersion))
+	}
+
+	var allRepos []model.Repo
+	for _, listURL := range listURLs {
+		var repos []azureRepo
+		if err := a.getAll(ctx, listURL, "repositories", &repos); err != nil {
+			return nil, err
+		}
+
+		for _, ar := range repos {
+			r := ar.repo()
+			if opts.skipArchived(r.Archived) {
+				continue
+			}
+			if !opts.IncludeForks && r.Fork {
+				continue
+			}
+			if len(opts.Repos) > 0 && !contains(opts.Repos, r.Slug) {
+				continue
+			}
+			if len(opts.Exclude) > 0 && contains(opts.Exclude, r.Slug) {
+				continue
+			}
+			if len(opts.ExcludeProjects) > 0 && r.Project != "" && matchesAny(opts.ExcludeProjects, r.Project) {
+				continue
+			}
+			allRepos = append(allRepos, r)
+		}
+	}
+
+	return allRepos, nil
+}
+
+type azureCommit struct {
+	CommitID string `json:"commitId"`
+	Author   struct {
+		Name  string    `json:"name"`
+		Email string    `json:"email"`
+		Date  time.Time `json:"date"`
+	} `json:"author"`
+	Comment          string `json:"comment"`
+	CommentTruncated bool   `json:"commentTruncated"`
+}
+
+// ListCommits fetches up to limit commits from the repository's default branch.
+func (a *AzureDevOps) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return a.CommitRange(ctx, repo, time.Time{}, time.Time{}, limit)
+}
+
+// CommitRange fetches up to limit commits dated between since and until,
+// filtered server-side by the commits API's fromDate/toDate criteria. The
+// listing truncates long commit messages, so those commits are fetched again
+// for the full message (and its trailers).
+func (a *AzureDevOps) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
+	apiURL, err := azureRepoAPI(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	q.Set("api-version", azureAPIVersion)
+	q.Set("searchCriteria.$top", strconv.Itoa(azurePageSize))
+	if !since.IsZero() {
+		q.Set("searchCriteria.fromDate", since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		q.Set("searchCriteria.toDate", until.UTC().Format(time.RFC3339))
+	}
+
+	var all []CommitInfo
+	nextURL := apiURL + "/commits?" + q.Encode()
+	for skip := 0; nextURL != ""; {
+		var page struct {
+			Value []azureCommit `json:"value"`
+		}
+		resp, err := a.get(ctx, nextURL, "commits", &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range page.Value {
+			message := c.Comment
+			if c.CommentTruncated {
+				full, err := a.commitMessage(ctx, apiURL, c.CommitID)
+				if err != nil {
+					return nil, err
+				}
+				message = full
+			}
+			all = append(all, CommitInfo{
+				Hash:    c.CommitID,
+				Author:  fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.Email),
+				Message: message,
+				Date:    c.Author.Date,
+			})
+			if limit > 0 && len(all) >= limit {
+				return all, nil
+			}
+		}
+
+		skip += len(page.Value)
+		nextURL = azureNextURL(nextURL, resp, len(page.Value), "searchCriteria.$skip", skip)
+	}
+
+	return all, nil
+}
+
+// commitMessage fetches the full message of a single commit.
+func (a *AzureDevOps) commitMessage(ctx context.Context, apiURL, hash string) (string, error) {
+	var c azureCommit
+	commitURL := fmt.Sprintf("%s/commits/%s?api-version=%s", apiURL, url.PathEscape(hash), azureAPIVersion)
+	if _, err := a.get(ctx, commitURL, "commit detail", &c); err != nil {
+		return "", err
+	}
+	return c.Comment, nil
+}
+
+// CommitStats always reports 0/0: Azure DevOps has no per-commit line
+// counts. AI estimates mark their additions unavailable accordingly.
+func (a *AzureDevOps) CommitStats(ctx context.Context, repo model.Repo, hash string) (int64, int64, error) {
+	return 0, 0, nil
+}
+
+type azureChange struct {
+	ChangeType string `json:"changeType"`
+	Item       struct {
+		Path          string `json:"path"`
+		IsFolder      bool   `json:"isFolder"`
+		GitObjectType string `json:"gitObjectType"`
+	} `json:"item"`
+}
+
+// CommitFileStats lists the files a commit changed. Azure DevOps reports no
+// line counts, so every file is 0/0 and churn marks its line stats
+// unavailable; change counts are still accurate. Renamed files are reported
+// under their new path, deleted files under their old one.
+func (a *AzureDevOps) CommitFileStats(ctx context.Context, repo model.Repo, hash string) ([]FileChange, error) {
+	apiURL, err := azureRepoAPI(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []FileChange
+	nextURL := fmt.Sprintf("%s/commits/%s/changes?top=%d&api-version=%s",
+		apiURL, url.PathEscape(hash), azurePageSize, azureAPIVersion)
+	for skip := 0; nextURL != ""; {
+		var page struct {
+			Changes []azureChange `json:"changes"`
+		}
+		resp, err := a.get(ctx, nextURL, "commit changes", &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range page.Changes {
+			if c.Item.IsFolder || c.Item.GitObjectType == "tree" {
+				continue
+			}
+			all = append(all, FileChange{Path: strings.TrimPrefix(c.Item.Path, "/")})
+		}
+
+		skip += len(page.Changes)
+		nextURL = azureNextURL(nextURL, resp, len(page.Changes), "skip", skip)
+	}
+
+	return all, nil
+}
+
+// azureRepoAPI returns the REST base of a repository, derived from its web
+// URL ({base}/{org}/{project}/_git/{repo}), which already carries the
+// organization and project.
+func azureRepoAPI(repo model.Repo) (string, error) {
+	i := strings.LastIndex(repo.URL, "/_git/")
+	if i < 0 {
+		return "", fmt.Errorf("cannot parse project/repository from URL: %s", repo.URL)
+	}
+	return repo.URL[:i] + "/_apis/git/repositories/" + repo.URL[i+len("/_git/"):], nil
+}
+
+// azureNextURL returns the URL of the page after currentURL, or "" after the
+// last one. It follows the x-ms-continuationtoken response header when the
+// API sends one; otherwise, for APIs paged with a skipParam, a full page is
+// followed by skipping the items seen so far.
+func azureNextURL(currentURL string, resp *http.Response, n int, skipParam string, skip int) string {
+	u, err := url.Parse(currentURL)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	if token := resp.Header.Get("x-ms-continuationtoken"); token != "" {
+		q.Set("continuationToken", token)
+	} else if skipParam != "" && n >= azurePageSize {
+		q.Set(skipParam, strconv.Itoa(skip))
+	} else {
+		return ""
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// getAll decodes the "value" array of every page of a listing into out,
+// following continuation tokens.
+func (a *AzureDevOps) getAll(ctx context.Context, nextURL, api string, out *[]azureRepo) error {
+	for nextURL != "" {
+		var page struct {
+			Value []azureRepo `json:"value"`
+		}
+		resp, err := a.get(ctx, nextURL, api, &page)
+		if err != nil {
+			return err
+		}
+		*out = append(*out, page.Value...)
+		nextURL = azureNextURL(nextURL, resp, len(page.Value), "", 0)
+	}
+	return nil
+}
+
+// get fetches reqURL and decodes the JSON body into out. The response is
+// returned (with its body closed) for its paging headers.
+func (a *AzureDevOps) get(ctx context.Context, reqURL, api string, out any) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// PATs go in basic auth with an empty user name
+	req.SetBasicAuth("", a.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("azure devops %s API: %w", api, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("azure devops %s API returned status %d: %w", api, resp.StatusCode, ErrForbidden)
+	default:
+		return nil, fmt.Errorf("azure devops %s API returned status %d", api, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode azure devops %s: %w", api, err)
+	}
+	return resp, nil
+}
+
+// ensure AzureDevOps satisfies the interfaces at compile time.
+var _ Provider = (*AzureDevOps)(nil)
+var _ ChurnLister = (*AzureDevOps)(nil)
+var _ CommitRanger = (*AzureDevOps)(nil)
@@ -0,0 +1,218 @@
+// internal/provider/azure_test.go
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func TestAzureDevOpsListRepos(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, ok := r.BasicAuth(); !ok || pass != "test-pat" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/myorg/_apis/git/repositories" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		repo := func(name string, disabled, fork bool) map[string]any {
+			return map[string]any{
+				"id":            "id-" + name,
+				"name":          name,
+				"webUrl":        fmt.Sprintf("%s/myorg/Platform/_git/%s", server.URL, name),
+				"remoteUrl":     fmt.Sprintf("https://myorg@dev.azure.com/myorg/Platform/_git/%s", name),
+				"defaultBranch": "refs/heads/main",
+				"size":          4096,
+				"isDisabled":    disabled,
+				"isFork":        fork,
+				"project":       map[string]any{"name": "Platform"},
+			}
+		}
+		if r.URL.Query().Get("continuationToken") == "" {
+			w.Header().Set("x-ms-continuationtoken", "page2")
+			json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{repo("api", false, false), repo("old", true, false)}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{repo("web", false, false), repo("fork", false, true)}})
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("test-pat", "myorg", server.URL, nil)
+	repos, err := az.ListRepos(context.Background(), provider.ListOpts{})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Slug != "api" || repos[1].Slug != "web" {
+		t.Fatalf("expected api and web across both pages, without disabled repos and forks, got %+v", repos)
+	}
+	r := repos[0]
+	if r.CloneURL != "https://dev.azure.com/myorg/Platform/_git/api" {
+		t.Errorf("expected the clone URL without the user, got %s", r.CloneURL)
+	}
+	if r.DefaultBranch != "main" || r.Project != "Platform" || r.Provider != "azure" || r.SizeKB != 4 {
+		t.Errorf("unexpected repo fields: %+v", r)
+	}
+
+	repos, err = az.ListRepos(context.Background(), provider.ListOpts{IncludeArchived: true, IncludeForks: true, Exclude: []string{"web"}})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(repos) != 3 {
+		t.Errorf("expected api, old and fork, got %+v", repos)
+	}
+}
+
+func TestAzureDevOpsListReposByProject(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{}})
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("test-pat", "myorg", server.URL, nil)
+	if _, err := az.ListRepos(context.Background(), provider.ListOpts{Projects: []string{"My Project"}}); err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/myorg/My%20Project/_apis/git/repositories" {
+		t.Errorf("expected the project's repository listing, got %v", paths)
+	}
+}
+
+func TestAzureDevOpsListCommits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/myorg/Platform/_apis/git/repositories/api/commits":
+			if r.URL.Query().Get("searchCriteria.$top") != "100" {
+				t.Errorf("expected a page size, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{
+				{
+					"commitId":         "abc123",
+					"author":           map[string]any{"name": "Dev", "email": "dev@example.com", "date": "2025-06-15T10:30:00Z"},
+					"comment":          "feat: add feature",
+					"commentTruncated": true,
+				},
+				{
+					"commitId": "def456",
+					"author":   map[string]any{"name": "Dev", "email": "dev@example.com", "date": "2025-06-14T09:00:00Z"},
+					"comment":  "fix: bug",
+				},
+			}})
+		case "/myorg/Platform/_apis/git/repositories/api/commits/abc123":
+			json.NewEncoder(w).Encode(map[string]any{
+				"commitId": "abc123",
+				"comment":  "feat: add feature\n\nCo-Authored-By: Claude <noreply@anthropic.com>",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("test-pat", "myorg", server.URL, nil)
+	repo := model.Repo{Slug: "api", URL: server.URL + "/myorg/Platform/_git/api"}
+	commits, err := az.ListCommits(context.Background(), repo, 100)
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if !strings.Contains(commits[0].Message, "Co-Authored-By") {
+		t.Errorf("expected the full message of a truncated commit, got %q", commits[0].Message)
+	}
+	if commits[0].Author != "Dev <dev@example.com>" || commits[0].Date.Day() != 15 {
+		t.Errorf("unexpected commit: %+v", commits[0])
+	}
+
+	additions, deletions, err := az.CommitStats(context.Background(), repo, "abc123")
+	if err != nil || additions != 0 || deletions != 0 {
+		t.Errorf("expected 0/0 commit stats, got %d/%d (%v)", additions, deletions, err)
+	}
+}
+
+func TestAzureDevOpsListCommitsPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		skip := r.URL.Query().Get("searchCriteria.$skip")
+		n := 100
+		if skip == "100" {
+			n = 20
+		}
+		commits := make([]map[string]any, n)
+		for i := range commits {
+			commits[i] = map[string]any{"commitId": fmt.Sprintf("c-%s-%d", skip, i)}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"value": commits})
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("test-pat", "myorg", server.URL, nil)
+	repo := model.Repo{Slug: "api", URL: server.URL + "/myorg/Platform/_git/api"}
+	commits, err := az.ListCommits(context.Background(), repo, 0)
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	if len(commits) != 120 {
+		t.Errorf("expected 120 commits over two pages, got %d", len(commits))
+	}
+
+	commits, _ = az.ListCommits(context.Background(), repo, 50)
+	if len(commits) != 50 {
+		t.Errorf("expected the limit to cap the listing at 50, got %d", len(commits))
+	}
+}
+
+func TestAzureDevOpsCommitFileStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/myorg/Platform/_apis/git/repositories/api/commits/abc123/changes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"changes": []map[string]any{
+			{"changeType": "edit", "item": map[string]any{"path": "/src", "isFolder": true, "gitObjectType": "tree"}},
+			{"changeType": "edit", "item": map[string]any{"path": "/src/main.go", "gitObjectType": "blob"}},
+			{"changeType": "rename", "item": map[string]any{"path": "/src/new.go", "gitObjectType": "blob"}},
+			{"changeType": "delete", "item": map[string]any{"path": "/old.txt", "gitObjectType": "blob"}},
+		}})
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("test-pat", "myorg", server.URL, nil)
+	files, err := az.CommitFileStats(context.Background(), model.Repo{
+		Slug: "api", URL: server.URL + "/myorg/Platform/_git/api",
+	}, "abc123")
+	if err != nil {
+		t.Fatalf("CommitFileStats: %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if got := strings.Join(paths, ","); got != "src/main.go,src/new.go,old.txt" {
+		t.Errorf("expected the changed files without folders, got %s", got)
+	}
+}
+
+func TestAzureDevOpsForbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	az := provider.NewAzureDevOps("bad", "myorg", server.URL, nil)
+	_, err := az.ListRepos(context.Background(), provider.ListOpts{})
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("expected a 401 error, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"time"
 
@@ -23,12 +24,17 @@ type Bitbucket struct {
 	client   *http.Client
 }
 
-// Project represents a Bitbucket project within a workspace.
+// Project represents a Bitbucket project within a workspace (or, on
+// Bitbucket Server, within the instance).
 type Project struct {
 	Key  string
 	Name string
 }
 
+// BitbucketRoles are the values Bitbucket Cloud accepts for ListOpts.Role,
+// from the widest to the narrowest selection.
+var BitbucketRoles = []string{"member", "contributor", "admin", "owner"}
+
 // NewBitbucket creates a new Bitbucket provider. If baseURL is empty,
 // the default Bitbucket Cloud API endpoint is used. If username is
 // non-empty, Basic Auth is used instead of Bearer token auth.
@@ -37,7 +43,7 @@ func NewBitbucket(token, username, baseURL string, client *http.Client) *Bitbuck
 		baseURL = bitbucketAPIBase
 	}
 	if client == nil {
-		client = &http.Client{}
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
 	}
 	return &Bitbucket{
 		token:    token,
@@ -54,8 +60,9 @@ func (b *Bitbucket) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo,
 
 	nextURL := b.buildListURL(opts)
 
+	skipper := &pageSkipper{opts: opts}
 	for nextURL != "" {
-		repos, next, err := b.fetchPage(ctx, nextURL)
+		repos, next, err := skipper.fetch(ctx, nextURL, b.fetchPage)
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +71,7 @@ func (b *Bitbucket) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo,
 			if !opts.IncludeForks && r.Fork {
 				continue
 			}
-			if !opts.IncludeArchived && r.Archived {
+			if opts.skipArchived(r.Archived) {
 				continue
 			}
 			if len(opts.Repos) > 0 && !contains(opts.Repos, r.Slug) {
@@ -73,6 +80,9 @@ func (b *Bitbucket) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo,
 			if len(opts.Exclude) > 0 && contains(opts.Exclude, r.Slug) {
 				continue
 			}
+			if len(opts.ExcludeProjects) > 0 && r.Project != "" && matchesAny(opts.ExcludeProjects, r.Project) {
+				continue
+			}
 			allRepos = append(allRepos, r)
 		}
 
@@ -86,6 +96,9 @@ func (b *Bitbucket) buildListURL(opts ListOpts) string {
 	u := fmt.Sprintf("%s/2.0/repositories/%s", b.baseURL, url.PathEscape(opts.Workspace))
 	params := url.Values{}
 	params.Set("pagelen", "100")
+	if opts.Role != "" {
+		params.Set("role", opts.Role)
+	}
 
 	if len(opts.Projects) > 0 {
 		clauses := make([]string, len(opts.Projects))
@@ -147,7 +160,7 @@ func (b *Bitbucket) fetchPage(ctx context.Context, pageURL string) ([]model.Repo
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("bitbucket API returned status %d", resp.StatusCode)
+		return nil, "", bitbucketStatusError("bitbucket API", resp.StatusCode)
 	}
 
 	var page bitbucketPage
@@ -178,6 +191,11 @@ func (b *Bitbucket) fetchPage(ctx context.Context, pageURL string) ([]model.Repo
 		downloadURL := fmt.Sprintf("https://bitbucket.org/%s/get/%s.tar.gz",
 			bbRepo.FullName, url.PathEscape(branch))
 
+		var parentURL string
+		if bbRepo.Parent != nil && bbRepo.Parent.FullName != "" {
+			parentURL = "https://bitbucket.org/" + bbRepo.Parent.FullName + ".git"
+		}
+
 		repos = append(repos, model.Repo{
 			Name:          bbRepo.Slug,
 			Slug:          bbRepo.Slug,
@@ -188,6 +206,7 @@ func (b *Bitbucket) fetchPage(ctx context.Context, pageURL string) ([]model.Repo
 			Provider:      "bitbucket",
 			DefaultBranch: branch,
 			Fork:          bbRepo.Parent != nil,
+			ParentURL:     parentURL,
 		})
 	}
 
@@ -217,7 +236,7 @@ func (b *Bitbucket) ListProjects(ctx context.Context, workspace string) ([]Proje
 		}
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("bitbucket projects API returned status %d", resp.StatusCode)
+			return nil, bitbucketStatusError("bitbucket projects API", resp.StatusCode)
 		}
 
 		var page struct {
@@ -258,6 +277,13 @@ type bitbucketCommit struct {
 
 // ListCommits fetches up to limit commits for a repo via the Bitbucket API.
 func (b *Bitbucket) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return b.CommitRange(ctx, repo, time.Time{}, time.Time{}, limit)
+}
+
+// CommitRange fetches up to limit commits dated between since and until. The
+// commits API has no date filter, so it pages newest first, skips commits
+// after until and stops at the first commit before since.
+func (b *Bitbucket) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
 	ws, slug := workspaceSlug(repo.URL)
 	if ws == "" {
 		return nil, fmt.Errorf("cannot parse workspace/slug from URL: %s", repo.URL)
@@ -275,7 +301,7 @@ func (b *Bitbucket) ListCommits(ctx context.Context, repo model.Repo, limit int)
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("bitbucket commits API returned status %d", resp.StatusCode)
+			return nil, bitbucketStatusError("bitbucket commits API", resp.StatusCode)
 		}
 
 		var page struct {
@@ -290,6 +316,13 @@ func (b *Bitbucket) ListCommits(ctx context.Context, repo model.Repo, limit int)
 
 		for _, c := range page.Values {
 			commitDate, _ := time.Parse(time.RFC3339Nano, c.Date)
+			ok, past := inRange(commitDate, since, until)
+			if past {
+				return all, nil
+			}
+			if !ok {
+				continue
+			}
 			all = append(all, CommitInfo{
 				Hash:    c.Hash,
 				Author:  c.Author.Raw,
@@ -340,7 +373,7 @@ func (b *Bitbucket) CommitStats(ctx context.Context, repo model.Repo, hash strin
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, 0, fmt.Errorf("bitbucket diffstat API returned status %d", resp.StatusCode)
+		return 0, 0, bitbucketStatusError("bitbucket diffstat API", resp.StatusCode)
 	}
 
 	var page struct {
@@ -377,7 +410,7 @@ func (b *Bitbucket) CommitFileStats(ctx context.Context, repo model.Repo, hash s
 		}
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("bitbucket diffstat API returned status %d", resp.StatusCode)
+			return nil, bitbucketStatusError("bitbucket diffstat API", resp.StatusCode)
 		}
 
 		var page struct {
@@ -404,6 +437,52 @@ func (b *Bitbucket) CommitFileStats(ctx context.Context, repo model.Repo, hash s
 	return all, nil
 }
 
+// OpenIssues counts new and open issues for a Bitbucket repository. Returns
+// ErrIssuesDisabled when the repository has no issue tracker (404).
+func (b *Bitbucket) OpenIssues(ctx context.Context, repo model.Repo) (int, error) {
+	ws, slug := workspaceSlug(repo.URL)
+	if ws == "" {
+		return 0, fmt.Errorf("cannot parse workspace/slug from URL: %s", repo.URL)
+	}
+
+	params := url.Values{}
+	params.Set("pagelen", "1")
+	params.Set("q", `state="new" OR state="open"`)
+	apiURL := fmt.Sprintf("%s/2.0/repositories/%s/%s/issues?%s",
+		b.baseURL, url.PathEscape(ws), url.PathEscape(slug), params.Encode())
+
+	resp, err := b.doGet(ctx, apiURL)
+	if err != nil {
+		return 0, fmt.Errorf("bitbucket issues API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, ErrIssuesDisabled
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, bitbucketStatusError("bitbucket issues API", resp.StatusCode)
+	}
+
+	var page struct {
+		Size int `json:"size"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return 0, fmt.Errorf("decode bitbucket issues: %w", err)
+	}
+
+	return page.Size, nil
+}
+
+// bitbucketStatusError reports an unexpected status from api, wrapping
+// ErrForbidden for 403s so callers can tell missing access from failures.
+func bitbucketStatusError(api string, status int) error {
+	if status == http.StatusForbidden {
+		return fmt.Errorf("%s returned status %d: %w", api, status, ErrForbidden)
+	}
+	return fmt.Errorf("%s returned status %d", api, status)
+}
+
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -412,3 +491,18 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// matchesAny reports whether item matches any of the glob patterns
+// (path.Match syntax). Malformed patterns fall back to exact comparison.
+func matchesAny(patterns []string, item string) bool {
+	for _, p := range patterns {
+		ok, err := path.Match(p, item)
+		if err != nil {
+			ok = p == item
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,430 @@
+// internal/provider/bitbucket_server.go
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// bitbucketServerPageSize is the page size requested from the Bitbucket
+// Server REST API (its default is 25).
+const bitbucketServerPageSize = 100
+
+// BitbucketServer implements Provider and ChurnLister for self-hosted
+// Bitbucket Server / Data Center, which uses the /rest/api/1.0 API instead
+// of Bitbucket Cloud's 2.0 API. There are no workspaces: repositories live
+// directly under projects.
+type BitbucketServer struct {
+	token    string
+	username string
+	baseURL  string
+	client   *http.Client
+}
+
+// NewBitbucketServer creates a Bitbucket Server provider for the instance at
+// baseURL (e.g. https://bitbucket.example.com). If username is non-empty,
+// Basic Auth is used instead of Bearer token auth (HTTP access tokens).
+func NewBitbucketServer(token, username, baseURL string, client *http.Client) *BitbucketServer {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	return &BitbucketServer{
+		token:    token,
+		username: username,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		client:   client,
+	}
+}
+
+// IsBitbucketServerURL reports whether baseURL (typically
+// CODEMIUM_BITBUCKET_URL) points at a self-hosted Bitbucket Server instance
+// rather than Bitbucket Cloud. An empty URL means Cloud.
+func IsBitbucketServerURL(baseURL string) bool {
+	if baseURL == "" {
+		return false
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return host != "bitbucket.org" && host != "api.bitbucket.org"
+}
+
+// bitbucketServerPage is the paged response envelope used by every
+// Bitbucket Server list endpoint.
+type bitbucketServerPage struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart int             `json:"nextPageStart"`
+}
+
+type bitbucketServerRepo struct {
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+	Project  struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Origin *struct {
+		Slug  string               `json:"slug"`
+		Links bitbucketServerLinks `json:"links"`
+	} `json:"origin"`
+	Links bitbucketServerLinks `json:"links"`
+}
+
+type bitbucketServerLinks struct {
+	Self []struct {
+		Href string `json:"href"`
+	} `json:"self"`
+	Clone []struct {
+		Name string `json:"name"`
+		Href string `json:"href"`
+	} `json:"clone"`
+}
+
+// httpClone returns the HTTP(S) clone URL, or "" if only SSH is offered.
+func (l bitbucketServerLinks) httpClone() string {
+	for _, c := range l.Clone {
+		if c.Name == "http" || c.Name == "https" {
+			return c.Href
+		}
+	}
+	return ""
+}
+
+// ListRepos fetches all repositories visible to the token, or only those in
+// opts.Projects when set, handling pagination automatically.
+func (s *BitbucketServer) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, error) {
+	var startURLs []string
+	if len(opts.Projects) > 0 {
+		for _, p := range opts.Projects {
+			startURLs = append(startURLs, s.pagedURL(fmt.Sprintf("/rest/api/1.0/projects/%s/repos", url.PathEscape(p)), 0))
+		}
+	} else {
+		startURLs = append(startURLs, s.pagedURL("/rest/api/1.0/repos", 0))
+	}
+
+	var allRepos []model.Repo
+	for _, nextURL := range startURLs {
+		skipper := &pageSkipper{opts: opts}
+		for nextURL != "" {
+			repos, next, err := skipper.fetch(ctx, nextURL, s.fetchPage)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, r := range repos {
+				if !opts.IncludeForks && r.Fork {
+					continue
+				}
+				if opts.skipArchived(r.Archived) {
+					continue
+				}
+				if len(opts.Repos) > 0 && !contains(opts.Repos, r.Slug) {
+					continue
+				}
+				if len(opts.Exclude) > 0 && contains(opts.Exclude, r.Slug) {
+					continue
+				}
+				if len(opts.ExcludeProjects) > 0 && r.Project != "" && matchesAny(opts.ExcludeProjects, r.Project) {
+					continue
+				}
+				allRepos = append(allRepos, r)
+			}
+
+			nextURL = next
+		}
+	}
+
+	return allRepos, nil
+}
+
+// pagedURL builds an API URL for path with explicit start/limit paging
+// parameters.
+func (s *BitbucketServer) pagedURL(path string, start int) string {
+	params := url.Values{}
+	params.Set("start", fmt.Sprint(start))
+	params.Set("limit", fmt.Sprint(bitbucketServerPageSize))
+	return s.baseURL + path + "?" + params.Encode()
+}
+
+// nextPageURL returns currentURL advanced to page.NextPageStart, or "" on
+// the last page.
+func (s *BitbucketServer) nextPageURL(currentURL string, page bitbucketServerPage) string {
+	if page.IsLastPage {
+		return ""
+	}
+	u, err := url.Parse(currentURL)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("start", fmt.Sprint(page.NextPageStart))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (s *BitbucketServer) doGet(ctx context.Context, reqURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return s.client.Do(req)
+}
+
+// getPage fetches one page of a list endpoint. what names the API in errors.
+func (s *BitbucketServer) getPage(ctx context.Context, pageURL, what string) (bitbucketServerPage, error) {
+	var page bitbucketServerPage
+	resp, err := s.doGet(ctx, pageURL)
+	if err != nil {
+		return page, fmt.Errorf("bitbucket server %s API: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("bitbucket server %s API returned status %d", what, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return page, fmt.Errorf("decode bitbucket server %s: %w", what, err)
+	}
+	return page, nil
+}
+
+func (s *BitbucketServer) fetchPage(ctx context.Context, pageURL string) ([]model.Repo, string, error) {
+	page, err := s.getPage(ctx, pageURL, "repos")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var values []bitbucketServerRepo
+	if err := json.Unmarshal(page.Values, &values); err != nil {
+		return nil, "", fmt.Errorf("decode bitbucket server repos: %w", err)
+	}
+
+	var repos []model.Repo
+	for _, r := range values {
+		webURL := ""
+		if len(r.Links.Self) > 0 {
+			webURL = r.Links.Self[0].Href
+		}
+		var parentURL string
+		if r.Origin != nil {
+			parentURL = r.Origin.Links.httpClone()
+		}
+
+		repos = append(repos, model.Repo{
+			Name:      r.Name,
+			Slug:      r.Slug,
+			Project:   r.Project.Key,
+			URL:       webURL,
+			CloneURL:  r.Links.httpClone(),
+			Provider:  "bitbucket",
+			Archived:  r.Archived,
+			Fork:      r.Origin != nil,
+			ParentURL: parentURL,
+		})
+	}
+
+	return repos, s.nextPageURL(pageURL, page), nil
+}
+
+// ListProjects fetches all projects visible to the token. The workspace
+// argument is ignored: Bitbucket Server has no workspaces.
+func (s *BitbucketServer) ListProjects(ctx context.Context, _ string) ([]Project, error) {
+	var all []Project
+	nextURL := s.pagedURL("/rest/api/1.0/projects", 0)
+
+	for nextURL != "" {
+		page, err := s.getPage(ctx, nextURL, "projects")
+		if err != nil {
+			return nil, err
+		}
+
+		var values []bitbucketProject
+		if err := json.Unmarshal(page.Values, &values); err != nil {
+			return nil, fmt.Errorf("decode bitbucket server projects: %w", err)
+		}
+		for _, p := range values {
+			all = append(all, Project{Key: p.Key, Name: p.Name})
+		}
+
+		nextURL = s.nextPageURL(nextURL, page)
+	}
+
+	return all, nil
+}
+
+// repoPath returns the API path of repo, built from its project key and slug.
+func (s *BitbucketServer) repoPath(repo model.Repo) (string, error) {
+	if repo.Project == "" || repo.Slug == "" {
+		return "", fmt.Errorf("bitbucket server repo %q has no project key", repo.Slug)
+	}
+	return fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s",
+		url.PathEscape(repo.Project), url.PathEscape(repo.Slug)), nil
+}
+
+type bitbucketServerCommit struct {
+	ID     string `json:"id"`
+	Author struct {
+		Name         string `json:"name"`
+		EmailAddress string `json:"emailAddress"`
+	} `json:"author"`
+	AuthorTimestamp int64  `json:"authorTimestamp"`
+	Message         string `json:"message"`
+}
+
+// ListCommits fetches up to limit commits on the default branch of a repo.
+func (s *BitbucketServer) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return s.CommitRange(ctx, repo, time.Time{}, time.Time{}, limit)
+}
+
+// CommitRange fetches up to limit commits on the default branch dated between
+// since and until. The commits API has no date filter, so it pages newest
+// first, skips commits after until and stops at the first commit before since.
+func (s *BitbucketServer) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
+	repoPath, err := s.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []CommitInfo
+	nextURL := s.pagedURL(repoPath+"/commits", 0)
+
+	for nextURL != "" {
+		page, err := s.getPage(ctx, nextURL, "commits")
+		if err != nil {
+			return nil, err
+		}
+
+		var commits []bitbucketServerCommit
+		if err := json.Unmarshal(page.Values, &commits); err != nil {
+			return nil, fmt.Errorf("decode bitbucket server commits: %w", err)
+		}
+
+		for _, c := range commits {
+			date := time.UnixMilli(c.AuthorTimestamp).UTC()
+			ok, past := inRange(date, since, until)
+			if past {
+				return all, nil
+			}
+			if !ok {
+				continue
+			}
+			all = append(all, CommitInfo{
+				Hash:    c.ID,
+				Author:  fmt.Sprintf("%s <%s>", c.Author.Name, c.Author.EmailAddress),
+				Message: c.Message,
+				Date:    date,
+			})
+			if limit > 0 && len(all) >= limit {
+				return all, nil
+			}
+		}
+
+		nextURL = s.nextPageURL(nextURL, page)
+	}
+
+	return all, nil
+}
+
+type bitbucketServerDiff struct {
+	Source *struct {
+		ToString string `json:"toString"`
+	} `json:"source"`
+	Destination *struct {
+		ToString string `json:"toString"`
+	} `json:"destination"`
+	Hunks []struct {
+		Segments []struct {
+			Type  string            `json:"type"`
+			Lines []json.RawMessage `json:"lines"`
+		} `json:"segments"`
+	} `json:"hunks"`
+}
+
+// CommitStats fetches addition/deletion counts for a single commit by
+// summing its per-file diff.
+func (s *BitbucketServer) CommitStats(ctx context.Context, repo model.Repo, hash string) (int64, int64, error) {
+	files, err := s.CommitFileStats(ctx, repo, hash)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var additions, deletions int64
+	for _, f := range files {
+		additions += f.Additions
+		deletions += f.Deletions
+	}
+	return additions, deletions, nil
+}
+
+// CommitFileStats fetches per-file addition/deletion counts for a single
+// commit. Bitbucket Server has no diffstat endpoint, so the lines of the
+// commit diff (without context) are counted.
+func (s *BitbucketServer) CommitFileStats(ctx context.Context, repo model.Repo, hash string) ([]FileChange, error) {
+	repoPath, err := s.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := fmt.Sprintf("%s%s/commits/%s/diff?contextLines=0",
+		s.baseURL, repoPath, url.PathEscape(hash))
+
+	resp, err := s.doGet(ctx, apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket server diff API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bitbucket server diff API returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Diffs []bitbucketServerDiff `json:"diffs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode bitbucket server diff: %w", err)
+	}
+
+	var all []FileChange
+	for _, d := range body.Diffs {
+		fc := FileChange{}
+		if d.Destination != nil {
+			fc.Path = d.Destination.ToString
+		} else if d.Source != nil {
+			fc.Path = d.Source.ToString
+		}
+		for _, h := range d.Hunks {
+			for _, seg := range h.Segments {
+				switch seg.Type {
+				case "ADDED":
+					fc.Additions += int64(len(seg.Lines))
+				case "REMOVED":
+					fc.Deletions += int64(len(seg.Lines))
+				}
+			}
+		}
+		all = append(all, fc)
+	}
+	return all, nil
+}
+
+// ensure BitbucketServer satisfies the interfaces at compile time.
+var _ Provider = (*BitbucketServer)(nil)
+var _ ChurnLister = (*BitbucketServer)(nil)
+var _ CommitRanger = (*BitbucketServer)(nil)
+var _ ProjectLister = (*BitbucketServer)(nil)
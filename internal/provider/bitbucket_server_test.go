@@ -0,0 +1,324 @@
+// internal/provider/bitbucket_server_test.go
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+func serverRepo(host, project, slug string, extra map[string]any) map[string]any {
+	r := map[string]any{
+		"slug":    slug,
+		"name":    slug,
+		"project": map[string]any{"key": project},
+		"links": map[string]any{
+			"self": []map[string]any{{"href": "http://" + host + "/projects/" + project + "/repos/" + slug + "/browse"}},
+			"clone": []map[string]any{
+				{"name": "ssh", "href": "ssh://git@" + host + "/" + project + "/" + slug + ".git"},
+				{"name": "http", "href": "http://" + host + "/scm/" + project + "/" + slug + ".git"},
+			},
+		},
+	}
+	for k, v := range extra {
+		r[k] = v
+	}
+	return r
+}
+
+func TestBitbucketServerListRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/repos" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("unexpected auth header: %q", got)
+		}
+		if r.URL.Query().Get("start") == "0" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"values": []map[string]any{
+					serverRepo(r.Host, "PRJ", "repo-1", nil),
+					serverRepo(r.Host, "PRJ", "old", map[string]any{"archived": true}),
+				},
+				"isLastPage":    false,
+				"nextPageStart": 2,
+			})
+			return
+		}
+		if r.URL.Query().Get("start") != "2" {
+			t.Errorf("expected start=2, got %q", r.URL.Query().Get("start"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{
+				serverRepo(r.Host, "~ALICE", "repo-2", nil),
+				serverRepo(r.Host, "PRJ", "a-fork", map[string]any{"origin": serverRepo(r.Host, "PRJ", "repo-1", nil)}),
+			},
+			"isLastPage": true,
+		})
+	}))
+	defer server.Close()
+
+	bbs := provider.NewBitbucketServer("test-token", "", server.URL+"/", nil)
+	repos, err := bbs.ListRepos(context.Background(), provider.ListOpts{})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("expected 2 repos (archived and fork excluded), got %d: %+v", len(repos), repos)
+	}
+	if repos[0].Slug != "repo-1" || repos[0].Project != "PRJ" {
+		t.Errorf("unexpected first repo: %+v", repos[0])
+	}
+	if repos[0].CloneURL != server.URL+"/scm/PRJ/repo-1.git" {
+		t.Errorf("expected http clone URL, got %s", repos[0].CloneURL)
+	}
+	if repos[0].URL != server.URL+"/projects/PRJ/repos/repo-1/browse" {
+		t.Errorf("unexpected URL: %s", repos[0].URL)
+	}
+	if repos[0].DownloadURL != "" {
+		t.Errorf("expected no tarball download URL, got %s", repos[0].DownloadURL)
+	}
+	if repos[0].Provider != "bitbucket" {
+		t.Errorf("expected provider bitbucket, got %s", repos[0].Provider)
+	}
+	if repos[1].Project != "~ALICE" {
+		t.Errorf("expected personal project ~ALICE, got %s", repos[1].Project)
+	}
+
+	repos, err = bbs.ListRepos(context.Background(), provider.ListOpts{IncludeForks: true})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 3 || repos[2].ParentURL != server.URL+"/scm/PRJ/repo-1.git" {
+		t.Errorf("expected fork with parent clone URL, got %+v", repos)
+	}
+}
+
+func TestBitbucketServerListReposByProject(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "test-token" {
+			t.Errorf("expected basic auth, got %q %q %v", user, pass, ok)
+		}
+		project := map[string]string{
+			"/rest/api/1.0/projects/ONE/repos": "ONE",
+			"/rest/api/1.0/projects/TWO/repos": "TWO",
+		}[r.URL.Path]
+		json.NewEncoder(w).Encode(map[string]any{
+			"values":     []map[string]any{serverRepo(r.Host, project, "svc", nil)},
+			"isLastPage": true,
+		})
+	}))
+	defer server.Close()
+
+	bbs := provider.NewBitbucketServer("test-token", "alice", server.URL, nil)
+	repos, err := bbs.ListRepos(context.Background(), provider.ListOpts{Projects: []string{"ONE", "TWO"}})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected one request per project, got %v", paths)
+	}
+	if len(repos) != 2 || repos[0].Project != "ONE" || repos[1].Project != "TWO" {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+}
+
+func TestBitbucketServerListProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{
+				{"key": "ONE", "name": "Project One"},
+				{"key": "TWO", "name": "Project Two"},
+			},
+			"isLastPage": true,
+		})
+	}))
+	defer server.Close()
+
+	bbs := provider.NewBitbucketServer("test-token", "", server.URL, nil)
+	projects, err := bbs.ListProjects(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to list projects: %v", err)
+	}
+	if len(projects) != 2 || projects[1].Key != "TWO" || projects[1].Name != "Project Two" {
+		t.Errorf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestBitbucketServerListCommits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects/PRJ/repos/svc/commits" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{
+				{
+					"id":              "abc123",
+					"author":          map[string]any{"name": "Alice", "emailAddress": "alice@example.com"},
+					"authorTimestamp": 1767225600000,
+					"message":         "Add feature",
+				},
+				{
+					"id":              "def456",
+					"author":          map[string]any{"name": "Bob", "emailAddress": "bob@example.com"},
+					"authorTimestamp": 1767139200000,
+					"message":         "Fix bug",
+				},
+			},
+			"isLastPage": true,
+		})
+	}))
+	defer server.Close()
+
+	bbs := provider.NewBitbucketServer("test-token", "", server.URL, nil)
+	repo := model.Repo{Slug: "svc", Project: "PRJ"}
+	commits, err := bbs.ListCommits(context.Background(), repo, 1)
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("expected limit of 1 commit, got %d", len(commits))
+	}
+	if commits[0].Hash != "abc123" || commits[0].Author != "Alice <alice@example.com>" {
+		t.Errorf("unexpected commit: %+v", commits[0])
+	}
+	if got := commits[0].Date.Format("2006-01-02"); got != "2026-01-01" {
+		t.Errorf("expected date 2026-01-01, got %s", got)
+	}
+
+	if _, err := bbs.ListCommits(context.Background(), model.Repo{Slug: "svc"}, 1); err == nil {
+		t.Error("expected error for repo without project key")
+	}
+}
+
+func TestBitbucketServerCommitStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/1.0/projects/PRJ/repos/svc/commits/abc123/diff" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		line := map[string]any{"line": "x"}
+		json.NewEncoder(w).Encode(map[string]any{
+			"diffs": []map[string]any{
+				{
+					"source":      map[string]any{"toString": "main.go"},
+					"destination": map[string]any{"toString": "main.go"},
+					"hunks": []map[string]any{{
+						"segments": []map[string]any{
+							{"type": "REMOVED", "lines": []any{line}},
+							{"type": "ADDED", "lines": []any{line, line, line}},
+							{"type": "CONTEXT", "lines": []any{line}},
+						},
+					}},
+				},
+				{
+					"source":      nil,
+					"destination": map[string]any{"toString": "new.go"},
+					"hunks": []map[string]any{{
+						"segments": []map[string]any{{"type": "ADDED", "lines": []any{line, line}}},
+					}},
+				},
+				{
+					"source":      map[string]any{"toString": "gone.go"},
+					"destination": nil,
+					"hunks": []map[string]any{{
+						"segments": []map[string]any{{"type": "REMOVED", "lines": []any{line, line, line, line}}},
+					}},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	bbs := provider.NewBitbucketServer("test-token", "", server.URL, nil)
+	repo := model.Repo{Slug: "svc", Project: "PRJ"}
+
+	add, del, err := bbs.CommitStats(context.Background(), repo, "abc123")
+	if err != nil {
+		t.Fatalf("CommitStats: %v", err)
+	}
+	if add != 5 || del != 5 {
+		t.Errorf("expected 5/5, got %d/%d", add, del)
+	}
+
+	files, err := bbs.CommitFileStats(context.Background(), repo, "abc123")
+	if err != nil {
+		t.Fatalf("CommitFileStats: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+	if files[1].Path != "new.go" || files[1].Additions != 2 {
+		t.Errorf("unexpected added file: %+v", files[1])
+	}
+	if files[2].Path != "gone.go" || files[2].Deletions != 4 {
+		t.Errorf("unexpected deleted file: %+v", files[2])
+	}
+}
+
+func TestBitbucketServerSkipsFailedPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("start") {
+		case "0":
+			json.NewEncoder(w).Encode(map[string]any{
+				"values":        []map[string]any{serverRepo(r.Host, "PRJ", "repo-1", nil)},
+				"isLastPage":    false,
+				"nextPageStart": 100,
+			})
+		case "100":
+			w.WriteHeader(http.StatusBadGateway)
+		case "200":
+			json.NewEncoder(w).Encode(map[string]any{
+				"values":     []map[string]any{serverRepo(r.Host, "PRJ", "repo-3", nil)},
+				"isLastPage": true,
+			})
+		default:
+			t.Errorf("unexpected start: %s", r.URL.Query().Get("start"))
+		}
+	}))
+	defer server.Close()
+
+	var skipped []string
+	bbs := provider.NewBitbucketServer("test-token", "", server.URL, nil)
+	repos, err := bbs.ListRepos(context.Background(), provider.ListOpts{
+		OnPageError: func(pageURL string, err error) { skipped = append(skipped, pageURL) },
+	})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("expected 1 skipped page, got %v", skipped)
+	}
+	if len(repos) != 2 || repos[1].Slug != "repo-3" {
+		t.Errorf("expected repos from pages 1 and 3, got %+v", repos)
+	}
+}
+
+func TestIsBitbucketServerURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"", false},
+		{"https://api.bitbucket.org", false},
+		{"https://bitbucket.org/", false},
+		{"https://bitbucket.example.com", true},
+		{"http://localhost:7990/bitbucket", true},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := provider.IsBitbucketServerURL(tt.url); got != tt.want {
+			t.Errorf("IsBitbucketServerURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -4,10 +4,12 @@ package provider_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/provider"
@@ -98,6 +100,41 @@ func TestBitbucketFilterByProject(t *testing.T) {
 	}
 }
 
+func TestBitbucketRole(t *testing.T) {
+	var role string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role = r.URL.Query().Get("role")
+		json.NewEncoder(w).Encode(map[string]any{"values": []map[string]any{}})
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+	bb.ListRepos(context.Background(), provider.ListOpts{Workspace: "myworkspace"})
+	if role != "" {
+		t.Errorf("expected no role parameter by default, got %q", role)
+	}
+	bb.ListRepos(context.Background(), provider.ListOpts{Workspace: "myworkspace", Role: "member"})
+	if role != "member" {
+		t.Errorf("expected role=member, got %q", role)
+	}
+}
+
+func TestBitbucketForbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+	if _, err := bb.ListRepos(context.Background(), provider.ListOpts{Workspace: "myworkspace"}); !errors.Is(err, provider.ErrForbidden) {
+		t.Errorf("expected listing 403 to wrap ErrForbidden, got %v", err)
+	}
+	repo := model.Repo{Slug: "repo-1", URL: "https://bitbucket.org/myworkspace/repo-1"}
+	if _, err := bb.ListCommits(context.Background(), repo, 10); !errors.Is(err, provider.ErrForbidden) {
+		t.Errorf("expected commits 403 to wrap ErrForbidden, got %v", err)
+	}
+}
+
 func TestBitbucketBasicAuth(t *testing.T) {
 	var gotAuth string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -162,6 +199,55 @@ func TestBitbucketExcludeForks(t *testing.T) {
 	if repos[0].Slug != "original" {
 		t.Errorf("expected original, got %s", repos[0].Slug)
 	}
+
+	repos, _ = bb.ListRepos(context.Background(), provider.ListOpts{
+		Workspace:    "ws",
+		IncludeForks: true,
+	})
+	if len(repos) != 2 || repos[1].ParentURL != "https://bitbucket.org/other/forked.git" {
+		t.Errorf("expected fork with parent URL, got %+v", repos)
+	}
+	if repos[0].ParentURL != "" {
+		t.Errorf("expected no parent URL for original, got %s", repos[0].ParentURL)
+	}
+}
+
+func TestBitbucketExcludeProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo := func(slug, project string) map[string]any {
+			return map[string]any{
+				"slug":      slug,
+				"full_name": "ws/" + slug,
+				"project":   map[string]any{"key": project},
+				"links": map[string]any{
+					"html":  map[string]any{"href": "https://bitbucket.org/ws/" + slug},
+					"clone": []map[string]any{{"name": "https", "href": "https://bitbucket.org/ws/" + slug + ".git"}},
+				},
+				"parent": nil,
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{
+				repo("api", "CORE"),
+				repo("sandbox-1", "SANDBOX"),
+				repo("sandbox-2", "SANDBOX-OLD"),
+			},
+		})
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+
+	repos, err := bb.ListRepos(context.Background(), provider.ListOpts{
+		Workspace:       "ws",
+		ExcludeProjects: []string{"SANDBOX*"},
+	})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Slug != "api" {
+		t.Fatalf("expected only api, got %v", repos)
+	}
 }
 
 func TestBitbucketListCommits(t *testing.T) {
@@ -245,3 +331,75 @@ func TestBitbucketCommitStats(t *testing.T) {
 		t.Errorf("expected 30 deletions, got %d", deletions)
 	}
 }
+
+func TestBitbucketOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/repositories/myworkspace/repo-1/issues") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if q := r.URL.Query().Get("q"); !strings.Contains(q, `state="open"`) {
+			t.Errorf("expected open state query, got %q", q)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"size": 7, "values": []map[string]any{{"id": 1}}})
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+	count, err := bb.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  "https://bitbucket.org/myworkspace/repo-1",
+	})
+	if err != nil {
+		t.Fatalf("OpenIssues: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("expected 7 open issues, got %d", count)
+	}
+}
+
+func TestBitbucketOpenIssuesNoTracker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+	_, err := bb.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  "https://bitbucket.org/myworkspace/repo-1",
+	})
+	if !errors.Is(err, provider.ErrIssuesDisabled) {
+		t.Errorf("expected ErrIssuesDisabled, got %v", err)
+	}
+}
+
+func TestBitbucketCommitRange(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{
+				{"hash": "new", "date": "2025-05-01T00:00:00+00:00"},
+				{"hash": "in", "date": "2025-03-01T00:00:00+00:00"},
+				{"hash": "old", "date": "2024-12-01T00:00:00+00:00"},
+			},
+			"next": "http://" + r.Host + r.URL.Path + "?page=2",
+		})
+	}))
+	defer server.Close()
+
+	bb := provider.NewBitbucket("test-token", "", server.URL, nil)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	commits, err := bb.CommitRange(context.Background(), model.Repo{URL: "https://bitbucket.org/myworkspace/repo-1"}, since, until, 0)
+	if err != nil {
+		t.Fatalf("CommitRange: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Hash != "in" {
+		t.Errorf("expected only the commit inside the range, got %+v", commits)
+	}
+	if pages != 1 {
+		t.Errorf("expected paging to stop at the first commit before since, fetched %d pages", pages)
+	}
+}
@@ -0,0 +1,110 @@
+// internal/provider/caching.go
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dsablic/codemium/internal/model"
+)
+
+// CachingCommitLister wraps a ChurnLister and memoizes CommitStats and
+// CommitFileStats by repository slug and commit hash, so analysis phases
+// that look at the same commits (AI estimation, health details, churn) fetch
+// each one once. It is safe for concurrent use; concurrent calls for the same
+// commit wait for a single request. Errors are not cached, so a later call
+// retries. ListCommits is passed through, and CommitRange is forwarded when
+// the wrapped lister implements CommitRanger.
+type CachingCommitLister struct {
+	inner ChurnLister
+
+	mu    sync.Mutex
+	stats map[commitKey]*cachedStats
+	files map[commitKey]*cachedFiles
+}
+
+type commitKey struct {
+	slug string
+	hash string
+}
+
+type cachedStats struct {
+	mu                   sync.Mutex
+	done                 bool
+	additions, deletions int64
+}
+
+type cachedFiles struct {
+	mu    sync.Mutex
+	done  bool
+	files []FileChange
+}
+
+// NewCachingCommitLister returns a CachingCommitLister wrapping cl.
+func NewCachingCommitLister(cl ChurnLister) *CachingCommitLister {
+	return &CachingCommitLister{
+		inner: cl,
+		stats: map[commitKey]*cachedStats{},
+		files: map[commitKey]*cachedFiles{},
+	}
+}
+
+func (c *CachingCommitLister) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return c.inner.ListCommits(ctx, repo, limit)
+}
+
+// CommitRange lists commits with the wrapped lister's CommitRange when it
+// has one, and otherwise the way ListCommitsInRange falls back.
+func (c *CachingCommitLister) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
+	return ListCommitsInRange(ctx, c.inner, repo, since, until, limit)
+}
+
+func (c *CachingCommitLister) CommitStats(ctx context.Context, repo model.Repo, hash string) (int64, int64, error) {
+	key := commitKey{repo.Slug, hash}
+	c.mu.Lock()
+	e, ok := c.stats[key]
+	if !ok {
+		e = &cachedStats{}
+		c.stats[key] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.done {
+		additions, deletions, err := c.inner.CommitStats(ctx, repo, hash)
+		if err != nil {
+			return 0, 0, err
+		}
+		e.additions, e.deletions, e.done = additions, deletions, true
+	}
+	return e.additions, e.deletions, nil
+}
+
+func (c *CachingCommitLister) CommitFileStats(ctx context.Context, repo model.Repo, hash string) ([]FileChange, error) {
+	key := commitKey{repo.Slug, hash}
+	c.mu.Lock()
+	e, ok := c.files[key]
+	if !ok {
+		e = &cachedFiles{}
+		c.files[key] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.done {
+		files, err := c.inner.CommitFileStats(ctx, repo, hash)
+		if err != nil {
+			return nil, err
+		}
+		e.files, e.done = files, true
+	}
+	return e.files, nil
+}
+
+var (
+	_ ChurnLister  = (*CachingCommitLister)(nil)
+	_ CommitRanger = (*CachingCommitLister)(nil)
+)
@@ -0,0 +1,95 @@
+// internal/provider/caching_test.go
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/provider"
+)
+
+type countingLister struct {
+	statsCalls map[string]*atomic.Int32
+	fileCalls  map[string]*atomic.Int32
+	fail       atomic.Bool
+}
+
+func newCountingLister(hashes ...string) *countingLister {
+	l := &countingLister{statsCalls: map[string]*atomic.Int32{}, fileCalls: map[string]*atomic.Int32{}}
+	for _, h := range hashes {
+		l.statsCalls[h] = &atomic.Int32{}
+		l.fileCalls[h] = &atomic.Int32{}
+	}
+	return l
+}
+
+func (l *countingLister) ListCommits(context.Context, model.Repo, int) ([]provider.CommitInfo, error) {
+	return nil, nil
+}
+
+func (l *countingLister) CommitStats(_ context.Context, _ model.Repo, hash string) (int64, int64, error) {
+	l.statsCalls[hash].Add(1)
+	if l.fail.Load() {
+		return 0, 0, errors.New("boom")
+	}
+	return 10, 2, nil
+}
+
+func (l *countingLister) CommitFileStats(_ context.Context, _ model.Repo, hash string) ([]provider.FileChange, error) {
+	l.fileCalls[hash].Add(1)
+	return []provider.FileChange{{Path: hash + ".go", Additions: 10, Deletions: 2}}, nil
+}
+
+func TestCachingCommitLister(t *testing.T) {
+	inner := newCountingLister("aaa", "bbb")
+	cached := provider.NewCachingCommitLister(inner)
+	repo := model.Repo{Slug: "api"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		for _, hash := range []string{"aaa", "bbb"} {
+			wg.Add(1)
+			go func(hash string) {
+				defer wg.Done()
+				if add, del, err := cached.CommitStats(context.Background(), repo, hash); err != nil || add != 10 || del != 2 {
+					t.Errorf("CommitStats(%s) = %d, %d, %v", hash, add, del, err)
+				}
+				if files, err := cached.CommitFileStats(context.Background(), repo, hash); err != nil || len(files) != 1 {
+					t.Errorf("CommitFileStats(%s) = %v, %v", hash, files, err)
+				}
+			}(hash)
+		}
+	}
+	wg.Wait()
+
+	for _, hash := range []string{"aaa", "bbb"} {
+		if n := inner.statsCalls[hash].Load(); n != 1 {
+			t.Errorf("expected CommitStats(%s) fetched once, got %d", hash, n)
+		}
+		if n := inner.fileCalls[hash].Load(); n != 1 {
+			t.Errorf("expected CommitFileStats(%s) fetched once, got %d", hash, n)
+		}
+	}
+}
+
+func TestCachingCommitListerRetriesErrors(t *testing.T) {
+	inner := newCountingLister("aaa")
+	cached := provider.NewCachingCommitLister(inner)
+	repo := model.Repo{Slug: "api"}
+
+	inner.fail.Store(true)
+	if _, _, err := cached.CommitStats(context.Background(), repo, "aaa"); err == nil {
+		t.Fatal("expected the error to be passed through")
+	}
+	inner.fail.Store(false)
+	if add, _, err := cached.CommitStats(context.Background(), repo, "aaa"); err != nil || add != 10 {
+		t.Errorf("expected a retry after the error, got %d, %v", add, err)
+	}
+	if n := inner.statsCalls["aaa"].Load(); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -29,7 +30,7 @@ func NewGitHub(token string, baseURL string, client *http.Client) *GitHub {
 		baseURL = githubAPIBase
 	}
 	if client == nil {
-		client = &http.Client{}
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
 	}
 	return &GitHub{
 		token:   token,
@@ -38,6 +39,31 @@ func NewGitHub(token string, baseURL string, client *http.Client) *GitHub {
 	}
 }
 
+// GitHubAPIURL normalizes a user-supplied GitHub URL into a NewGitHub base
+// URL. Empty, github.com and api.github.com mean GitHub.com (""); any other
+// host is GitHub Enterprise Server, whose REST API lives under /api/v3, so a
+// bare host like https://github.mycorp.com becomes
+// https://github.mycorp.com/api/v3.
+func GitHubAPIURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid GitHub URL %q: expected e.g. https://github.mycorp.com", raw)
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "github.com", "api.github.com":
+		return "", nil
+	}
+	path := strings.TrimRight(u.Path, "/")
+	if !strings.HasSuffix(path, "/api/v3") {
+		path += "/api/v3"
+	}
+	return u.Scheme + "://" + u.Host + path, nil
+}
+
 // ListRepos fetches all repositories matching the given options from
 // the GitHub API, handling Link header pagination automatically.
 func (g *GitHub) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, error) {
@@ -53,8 +79,9 @@ func (g *GitHub) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, er
 		nextURL = fmt.Sprintf("%s/orgs/%s/repos?per_page=100&type=all", g.baseURL, opts.Organization)
 	}
 
+	skipper := &pageSkipper{opts: opts}
 	for nextURL != "" {
-		repos, next, err := g.fetchPage(ctx, nextURL)
+		repos, next, err := skipper.fetch(ctx, nextURL, g.fetchPage)
 		if err != nil {
 			return nil, err
 		}
@@ -63,7 +90,7 @@ func (g *GitHub) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, er
 			if !opts.IncludeForks && r.Fork {
 				continue
 			}
-			if !opts.IncludeArchived && r.Archived {
+			if opts.skipArchived(r.Archived) {
 				continue
 			}
 			if len(opts.Repos) > 0 && !contains(opts.Repos, r.Slug) {
@@ -82,12 +109,14 @@ func (g *GitHub) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, er
 }
 
 type githubRepo struct {
-	Name     string `json:"name"`
-	FullName string `json:"full_name"`
-	HTMLURL  string `json:"html_url"`
-	CloneURL string `json:"clone_url"`
-	Archived bool   `json:"archived"`
-	Fork     bool   `json:"fork"`
+	Name     string    `json:"name"`
+	FullName string    `json:"full_name"`
+	HTMLURL  string    `json:"html_url"`
+	CloneURL string    `json:"clone_url"`
+	Archived bool      `json:"archived"`
+	Fork     bool      `json:"fork"`
+	PushedAt time.Time `json:"pushed_at"`
+	Size     int64     `json:"size"` // KiB
 }
 
 func (g *GitHub) fetchPage(ctx context.Context, pageURL string) ([]model.Repo, string, error) {
@@ -117,13 +146,15 @@ func (g *GitHub) fetchPage(ctx context.Context, pageURL string) ([]model.Repo, s
 	var repos []model.Repo
 	for _, r := range ghRepos {
 		repos = append(repos, model.Repo{
-			Name:     r.Name,
-			Slug:     r.Name,
-			URL:      r.HTMLURL,
-			CloneURL: r.CloneURL,
-			Provider: "github",
-			Archived: r.Archived,
-			Fork:     r.Fork,
+			Name:         r.Name,
+			Slug:         r.Name,
+			URL:          r.HTMLURL,
+			CloneURL:     r.CloneURL,
+			Provider:     "github",
+			Archived:     r.Archived,
+			Fork:         r.Fork,
+			LastActivity: r.PushedAt,
+			SizeKB:       r.Size,
 		})
 	}
 
@@ -176,13 +207,19 @@ type githubFileChange struct {
 
 // ListCommits fetches up to limit commits for a repo via the GitHub API.
 func (g *GitHub) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return g.CommitRange(ctx, repo, time.Time{}, time.Time{}, limit)
+}
+
+// CommitRange fetches up to limit commits dated between since and until,
+// filtered server-side by the commits API's since/until parameters.
+func (g *GitHub) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
 	owner, name := ownerRepo(repo.URL)
 	if owner == "" {
 		return nil, fmt.Errorf("cannot parse owner/repo from URL: %s", repo.URL)
 	}
 
 	var all []CommitInfo
-	nextURL := fmt.Sprintf("%s/repos/%s/%s/commits?per_page=100", g.baseURL, owner, name)
+	nextURL := fmt.Sprintf("%s/repos/%s/%s/commits?per_page=100%s", g.baseURL, owner, name, dateRangeQuery(since, until))
 
 	for nextURL != "" {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
@@ -197,6 +234,17 @@ func (g *GitHub) ListCommits(ctx context.Context, repo model.Repo, limit int) ([
 			return nil, fmt.Errorf("github commits API: %w", err)
 		}
 
+		// An empty repository has no branch to list from, so GitHub
+		// answers 409 Conflict instead of an empty page.
+		if resp.StatusCode == http.StatusConflict && emptyRepoResponse(resp.Body, "Git Repository is empty") {
+			resp.Body.Close()
+			return []CommitInfo{}, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("github commits API returned status %d", resp.StatusCode)
+		}
+
 		var commits []githubCommit
 		if err := json.NewDecoder(resp.Body).Decode(&commits); err != nil {
 			resp.Body.Close()
@@ -294,3 +342,98 @@ func (g *GitHub) CommitFileStats(ctx context.Context, repo model.Repo, hash stri
 	}
 	return changes, nil
 }
+
+// ForkParent returns the clone URL of the repository a fork was created
+// from, or "" if repo is not a fork.
+func (g *GitHub) ForkParent(ctx context.Context, repo model.Repo) (string, error) {
+	owner, name := ownerRepo(repo.URL)
+	if owner == "" {
+		return "", fmt.Errorf("cannot parse owner/repo from URL: %s", repo.URL)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s", g.baseURL, owner, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("github repo API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github repo API returned status %d", resp.StatusCode)
+	}
+
+	var detail struct {
+		Parent *struct {
+			CloneURL string `json:"clone_url"`
+		} `json:"parent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		return "", fmt.Errorf("decode github repo: %w", err)
+	}
+	if detail.Parent == nil {
+		return "", nil
+	}
+	return detail.Parent.CloneURL, nil
+}
+
+// OpenIssues counts open issues for a repo, excluding pull requests (which
+// the GitHub issues API also returns). Returns ErrIssuesDisabled when the
+// repository has issues turned off.
+func (g *GitHub) OpenIssues(ctx context.Context, repo model.Repo) (int, error) {
+	owner, name := ownerRepo(repo.URL)
+	if owner == "" {
+		return 0, fmt.Errorf("cannot parse owner/repo from URL: %s", repo.URL)
+	}
+
+	count := 0
+	nextURL := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&per_page=100", g.baseURL, owner, name)
+
+	for nextURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, nextURL, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Authorization", "Bearer "+g.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("github issues API: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusGone {
+			resp.Body.Close()
+			return 0, ErrIssuesDisabled
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return 0, fmt.Errorf("github issues API returned status %d", resp.StatusCode)
+		}
+
+		var issues []struct {
+			PullRequest *json.RawMessage `json:"pull_request"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+			resp.Body.Close()
+			return 0, fmt.Errorf("decode github issues: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, issue := range issues {
+			if issue.PullRequest == nil {
+				count++
+			}
+		}
+
+		nextURL = parseLinkNext(resp.Header.Get("Link"))
+	}
+
+	return count, nil
+}
@@ -4,11 +4,14 @@ package provider_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/provider"
@@ -107,6 +110,15 @@ func TestGitHubExcludeForksAndArchived(t *testing.T) {
 	if repos[0].Slug != "active" {
 		t.Errorf("expected active, got %s", repos[0].Slug)
 	}
+
+	repos, _ = gh.ListRepos(context.Background(), provider.ListOpts{
+		Organization:    "org",
+		IncludeArchived: true,
+		OnlyArchived:    true,
+	})
+	if len(repos) != 1 || repos[0].Slug != "archived-repo" {
+		t.Errorf("expected only archived-repo with OnlyArchived, got %+v", repos)
+	}
 }
 
 func TestGitHubListCommits(t *testing.T) {
@@ -253,3 +265,281 @@ func TestGitHubListCommitsLimit(t *testing.T) {
 		t.Errorf("expected 150 commits (limited), got %d", len(commits))
 	}
 }
+
+func TestGitHubOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/myorg/repo-1/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("state"); got != "open" {
+			t.Errorf("expected state=open, got %q", got)
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 1},
+			{"number": 2},
+			{"number": 3, "pull_request": map[string]any{"url": "https://api.github.com/pulls/3"}},
+		})
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	count, err := gh.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  "https://github.com/myorg/repo-1",
+	})
+	if err != nil {
+		t.Fatalf("OpenIssues: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 open issues (pull requests excluded), got %d", count)
+	}
+}
+
+func TestGitHubForkParent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/myorg/fork":
+			json.NewEncoder(w).Encode(map[string]any{
+				"fork":   true,
+				"parent": map[string]any{"clone_url": "https://github.com/upstream/fork.git"},
+			})
+		case "/repos/myorg/original":
+			json.NewEncoder(w).Encode(map[string]any{"fork": false})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	parent, err := gh.ForkParent(context.Background(), model.Repo{URL: "https://github.com/myorg/fork"})
+	if err != nil {
+		t.Fatalf("ForkParent: %v", err)
+	}
+	if parent != "https://github.com/upstream/fork.git" {
+		t.Errorf("unexpected parent: %s", parent)
+	}
+
+	parent, err = gh.ForkParent(context.Background(), model.Repo{URL: "https://github.com/myorg/original"})
+	if err != nil || parent != "" {
+		t.Errorf("expected no parent for non-fork, got %q, %v", parent, err)
+	}
+
+	if _, err := gh.ForkParent(context.Background(), model.Repo{URL: "https://github.com/myorg/missing"}); err == nil {
+		t.Error("expected error for 404")
+	}
+}
+
+func TestGitHubOpenIssuesDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	_, err := gh.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  "https://github.com/myorg/repo-1",
+	})
+	if !errors.Is(err, provider.ErrIssuesDisabled) {
+		t.Errorf("expected ErrIssuesDisabled, got %v", err)
+	}
+}
+
+func TestGitHubListReposPushedAt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "repo-1", "full_name": "org/repo-1", "html_url": "h", "clone_url": "c", "pushed_at": "2025-06-15T10:30:00Z", "size": 2048},
+		})
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	repos, err := gh.ListRepos(context.Background(), provider.ListOpts{Organization: "org"})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repo, got %d", len(repos))
+	}
+	want := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	if !repos[0].LastActivity.Equal(want) {
+		t.Errorf("expected LastActivity %s, got %s", want, repos[0].LastActivity)
+	}
+	if repos[0].SizeKB != 2048 {
+		t.Errorf("expected SizeKB 2048, got %d", repos[0].SizeKB)
+	}
+}
+
+func TestGitHubListReposSkipsFailedPage(t *testing.T) {
+	var page2Attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2&per_page=100>; rel="next"`, r.Host, r.URL.Path))
+			json.NewEncoder(w).Encode([]map[string]any{{"name": "repo-1", "full_name": "org/repo-1", "html_url": "h", "clone_url": "c"}})
+		case "2":
+			page2Attempts++
+			w.WriteHeader(http.StatusInternalServerError)
+		case "3":
+			json.NewEncoder(w).Encode([]map[string]any{{"name": "repo-3", "full_name": "org/repo-3", "html_url": "h", "clone_url": "c"}})
+		default:
+			json.NewEncoder(w).Encode([]map[string]any{})
+		}
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+
+	// Without OnPageError a failed page aborts the listing
+	if _, err := gh.ListRepos(context.Background(), provider.ListOpts{Organization: "org"}); err == nil {
+		t.Fatal("expected listing to fail without OnPageError")
+	}
+
+	page2Attempts = 0
+	var skipped []string
+	repos, err := gh.ListRepos(context.Background(), provider.ListOpts{
+		Organization: "org",
+		OnPageError: func(pageURL string, err error) {
+			skipped = append(skipped, pageURL)
+		},
+	})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Slug != "repo-1" || repos[1].Slug != "repo-3" {
+		t.Errorf("expected repo-1 and repo-3, got %v", repos)
+	}
+	if len(skipped) != 1 || !strings.Contains(skipped[0], "page=2") {
+		t.Errorf("expected page 2 to be reported as skipped, got %v", skipped)
+	}
+	if page2Attempts != 2 {
+		t.Errorf("expected failed page to be retried once, got %d attempts", page2Attempts)
+	}
+}
+
+func TestGitHubListReposGivesUpOnOutage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	_, err := gh.ListRepos(context.Background(), provider.ListOpts{
+		Organization: "org",
+		OnPageError:  func(string, error) {},
+	})
+	if err == nil {
+		t.Fatal("expected listing to give up after consecutive failed pages")
+	}
+}
+
+func TestGitHubCommitRange(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		json.NewEncoder(w).Encode([]map[string]any{})
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := gh.CommitRange(context.Background(), model.Repo{URL: "https://github.com/myorg/repo-1"}, since, until, 0); err != nil {
+		t.Fatalf("CommitRange: %v", err)
+	}
+	if query.Get("since") != "2025-01-01T00:00:00Z" || query.Get("until") != "2025-03-31T00:00:00Z" {
+		t.Errorf("expected since/until query parameters, got %v", query)
+	}
+}
+
+func TestGitHubListCommitsEmptyRepo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"Git Repository is empty.","documentation_url":"https://docs.github.com/rest/commits/commits#list-commits","status":"409"}`))
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	repo := model.Repo{Slug: "empty", URL: "https://github.com/myorg/empty"}
+	commits, err := gh.ListCommits(context.Background(), repo, 100)
+	if err != nil {
+		t.Fatalf("expected no error for an empty repository, got %v", err)
+	}
+	if commits == nil || len(commits) != 0 {
+		t.Errorf("expected an empty commit slice, got %v", commits)
+	}
+}
+
+func TestGitHubListCommitsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"Some other conflict"}`))
+	}))
+	defer server.Close()
+
+	gh := provider.NewGitHub("test-token", server.URL, nil)
+	_, err := gh.ListCommits(context.Background(), model.Repo{Slug: "r", URL: "https://github.com/myorg/r"}, 100)
+	if err == nil || !strings.Contains(err.Error(), "status 409") {
+		t.Errorf("expected a status error for other conflicts, got %v", err)
+	}
+}
+
+func TestGitHubAPIURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"empty is github.com", "", ""},
+		{"cloud web URL", "https://github.com", ""},
+		{"cloud API URL", "https://api.github.com/", ""},
+		{"enterprise host", "https://github.mycorp.com", "https://github.mycorp.com/api/v3"},
+		{"enterprise host with slash", "https://github.mycorp.com/", "https://github.mycorp.com/api/v3"},
+		{"enterprise API URL", "https://github.mycorp.com/api/v3", "https://github.mycorp.com/api/v3"},
+		{"enterprise with port and prefix", "http://ghe.local:8080/git", "http://ghe.local:8080/git/api/v3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := provider.GitHubAPIURL(tt.raw)
+			if err != nil {
+				t.Fatalf("GitHubAPIURL(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("GitHubAPIURL(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := provider.GitHubAPIURL("github.mycorp.com"); err == nil {
+		t.Error("expected an error for a URL without a scheme")
+	}
+}
+
+func TestGitHubEnterpriseListRepos(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/orgs/myorg/repos" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"name": "repo-1", "full_name": "myorg/repo-1", "html_url": "https://github.mycorp.com/myorg/repo-1", "clone_url": "https://github.mycorp.com/myorg/repo-1.git"},
+		})
+	}))
+	defer server.Close()
+
+	baseURL, err := provider.GitHubAPIURL(server.URL)
+	if err != nil {
+		t.Fatalf("GitHubAPIURL: %v", err)
+	}
+	gh := provider.NewGitHub("test-token", baseURL, nil)
+	repos, err := gh.ListRepos(context.Background(), provider.ListOpts{Organization: "myorg"})
+	if err != nil {
+		t.Fatalf("ListRepos: %v", err)
+	}
+	if len(repos) != 1 || repos[0].CloneURL != "https://github.mycorp.com/myorg/repo-1.git" {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,7 +16,7 @@ import (
 
 const gitlabAPIBase = "https://gitlab.com"
 
-// GitLab implements Provider and CommitLister for GitLab.
+// GitLab implements Provider and ChurnLister for GitLab.
 type GitLab struct {
 	token   string
 	baseURL string
@@ -29,7 +30,7 @@ func NewGitLab(token string, baseURL string, client *http.Client) *GitLab {
 		baseURL = gitlabAPIBase
 	}
 	if client == nil {
-		client = &http.Client{}
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
 	}
 	return &GitLab{
 		token:   token,
@@ -39,23 +40,101 @@ func NewGitLab(token string, baseURL string, client *http.Client) *GitLab {
 }
 
 type gitlabProject struct {
-	ID                int    `json:"id"`
-	Path              string `json:"path"`
-	PathWithNamespace string `json:"path_with_namespace"`
-	Name              string `json:"name"`
-	WebURL            string `json:"web_url"`
-	HTTPURLToRepo     string `json:"http_url_to_repo"`
-	DefaultBranch     string `json:"default_branch"`
-	Archived          bool   `json:"archived"`
+	ID                int       `json:"id"`
+	Path              string    `json:"path"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Name              string    `json:"name"`
+	WebURL            string    `json:"web_url"`
+	HTTPURLToRepo     string    `json:"http_url_to_repo"`
+	DefaultBranch     string    `json:"default_branch"`
+	Archived          bool      `json:"archived"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
 	ForkedFromProject *struct {
-		ID int `json:"id"`
+		ID            int    `json:"id"`
+		HTTPURLToRepo string `json:"http_url_to_repo"`
 	} `json:"forked_from_project"`
 	Namespace struct {
 		FullPath string `json:"full_path"`
 	} `json:"namespace"`
+	// Statistics is only returned with statistics=true and at least
+	// Reporter access to the project.
+	Statistics *struct {
+		RepositorySize int64 `json:"repository_size"` // bytes
+	} `json:"statistics"`
 }
 
+// repo converts a project from the API into a model.Repo.
+func (p gitlabProject) repo() model.Repo {
+	var parentURL string
+	if p.ForkedFromProject != nil {
+		parentURL = p.ForkedFromProject.HTTPURLToRepo
+	}
+	var sizeKB int64
+	if p.Statistics != nil {
+		sizeKB = p.Statistics.RepositorySize / 1024
+	}
+	return model.Repo{
+		Name:          p.Name,
+		Slug:          p.Path,
+		Project:       p.Namespace.FullPath,
+		URL:           p.WebURL,
+		CloneURL:      p.HTTPURLToRepo,
+		Provider:      "gitlab",
+		DefaultBranch: p.DefaultBranch,
+		Archived:      p.Archived,
+		Fork:          p.ForkedFromProject != nil,
+		ParentURL:     parentURL,
+		LastActivity:  p.LastActivityAt,
+		SizeKB:        sizeKB,
+	}
+}
+
+// listProjectsByPath fetches each project in opts.Projects by its full path.
+// Projects named this way are returned even when archived or forked; only
+// opts.Exclude and opts.ExcludeProjects still apply.
+func (g *GitLab) listProjectsByPath(ctx context.Context, opts ListOpts) ([]model.Repo, error) {
+	var repos []model.Repo
+	for _, path := range opts.Projects {
+		reqURL := fmt.Sprintf("%s/api/v4/projects/%s?statistics=true", g.baseURL, url.PathEscape(path))
+		resp, err := g.doGet(ctx, reqURL)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab API request: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab project %s not found (use the full path, e.g. group/subgroup/project)", path)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab API returned status %d for project %s", resp.StatusCode, path)
+		}
+		var p gitlabProject
+		err = json.NewDecoder(resp.Body).Decode(&p)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode gitlab project %s: %w", path, err)
+		}
+
+		r := p.repo()
+		if len(opts.Exclude) > 0 && contains(opts.Exclude, r.Slug) {
+			continue
+		}
+		if len(opts.ExcludeProjects) > 0 && r.Project != "" && matchesAny(opts.ExcludeProjects, r.Project) {
+			continue
+		}
+		repos = append(repos, r)
+	}
+	return repos, nil
+}
+
+// ListRepos lists the projects of the opts.Organization group, including
+// subgroups. When opts.Projects holds full project paths (group/sub/project),
+// those projects are fetched directly instead and the group is not listed.
 func (g *GitLab) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, error) {
+	if len(opts.Projects) > 0 {
+		return g.listProjectsByPath(ctx, opts)
+	}
+
 	var allRepos []model.Repo
 
 	group := opts.Organization
@@ -63,15 +142,20 @@ func (g *GitLab) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, er
 	params.Set("per_page", "100")
 	params.Set("include_subgroups", "true")
 	params.Set("with_shared", "false")
-	if !opts.IncludeArchived {
+	params.Set("statistics", "true")
+	switch {
+	case opts.OnlyArchived:
+		params.Set("archived", "true")
+	case !opts.IncludeArchived:
 		params.Set("archived", "false")
 	}
 
 	nextURL := fmt.Sprintf("%s/api/v4/groups/%s/projects?%s",
 		g.baseURL, url.PathEscape(group), params.Encode())
 
+	skipper := &pageSkipper{opts: opts}
 	for nextURL != "" {
-		repos, next, err := g.fetchPage(ctx, nextURL)
+		repos, next, err := skipper.fetch(ctx, nextURL, g.fetchPage)
 		if err != nil {
 			return nil, err
 		}
@@ -86,6 +170,9 @@ func (g *GitLab) ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, er
 			if len(opts.Exclude) > 0 && contains(opts.Exclude, r.Slug) {
 				continue
 			}
+			if len(opts.ExcludeProjects) > 0 && r.Project != "" && matchesAny(opts.ExcludeProjects, r.Project) {
+				continue
+			}
 			allRepos = append(allRepos, r)
 		}
 
@@ -122,17 +209,7 @@ func (g *GitLab) fetchPage(ctx context.Context, pageURL string) ([]model.Repo, s
 
 	var repos []model.Repo
 	for _, p := range projects {
-		repos = append(repos, model.Repo{
-			Name:          p.Name,
-			Slug:          p.Path,
-			Project:       p.Namespace.FullPath,
-			URL:           p.WebURL,
-			CloneURL:      p.HTTPURLToRepo,
-			Provider:      "gitlab",
-			DefaultBranch: p.DefaultBranch,
-			Archived:      p.Archived,
-			Fork:          p.ForkedFromProject != nil,
-		})
+		repos = append(repos, p.repo())
 	}
 
 	nextURL := g.nextPageURL(pageURL, resp)
@@ -227,6 +304,12 @@ type gitlabCommit struct {
 
 // ListCommits fetches up to limit commits for a repo via the GitLab API.
 func (g *GitLab) ListCommits(ctx context.Context, repo model.Repo, limit int) ([]CommitInfo, error) {
+	return g.CommitRange(ctx, repo, time.Time{}, time.Time{}, limit)
+}
+
+// CommitRange fetches up to limit commits dated between since and until,
+// filtered server-side by the commits API's since/until parameters.
+func (g *GitLab) CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
 	projectID := gitlabProjectID(repo.URL)
 	if projectID == "" {
 		return nil, fmt.Errorf("cannot parse project path from URL: %s", repo.URL)
@@ -237,8 +320,8 @@ func (g *GitLab) ListCommits(ctx context.Context, repo model.Repo, limit int) ([
 	if limit > 0 && limit < perPage {
 		perPage = limit
 	}
-	nextURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits?per_page=%d",
-		g.baseURL, projectID, perPage)
+	nextURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits?per_page=%d%s",
+		g.baseURL, projectID, perPage, dateRangeQuery(since, until))
 
 	for nextURL != "" {
 		resp, err := g.doGet(ctx, nextURL)
@@ -246,6 +329,12 @@ func (g *GitLab) ListCommits(ctx context.Context, repo model.Repo, limit int) ([
 			return nil, fmt.Errorf("gitlab commits API: %w", err)
 		}
 
+		// A project whose repository was never pushed to answers 404
+		// "Repository Not Found", unlike a missing project ("Project Not Found").
+		if resp.StatusCode == http.StatusNotFound && emptyRepoResponse(resp.Body, "404 Repository Not Found") {
+			resp.Body.Close()
+			return []CommitInfo{}, nil
+		}
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
 			return nil, fmt.Errorf("gitlab commits API returned status %d", resp.StatusCode)
@@ -290,13 +379,17 @@ func (g *GitLab) ListCommits(ctx context.Context, repo model.Repo, limit int) ([
 }
 
 type gitlabCommitDetail struct {
-	Stats struct {
+	ParentIDs []string `json:"parent_ids"`
+	Stats     struct {
 		Additions int64 `json:"additions"`
 		Deletions int64 `json:"deletions"`
 	} `json:"stats"`
 }
 
 // CommitStats fetches addition/deletion counts for a single GitLab commit.
+// Merge commits (more than one parent) report zero: GitLab's stats for them
+// cover only the merge itself, and the merged branch's changes are already
+// counted through its own commits.
 func (g *GitLab) CommitStats(ctx context.Context, repo model.Repo, hash string) (int64, int64, error) {
 	projectID := gitlabProjectID(repo.URL)
 	if projectID == "" {
@@ -320,10 +413,141 @@ func (g *GitLab) CommitStats(ctx context.Context, repo model.Repo, hash string)
 	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
 		return 0, 0, fmt.Errorf("decode gitlab commit detail: %w", err)
 	}
+	if len(detail.ParentIDs) > 1 {
+		return 0, 0, nil
+	}
 
 	return detail.Stats.Additions, detail.Stats.Deletions, nil
 }
 
-// ensure GitLab satisfies both interfaces at compile time.
+type gitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// CommitFileStats fetches per-file addition/deletion counts for a single
+// GitLab commit. The diff API has no per-file stats, so the added and removed
+// lines of each file's diff are counted; a diff GitLab collapsed as too large
+// comes back empty and counts as 0/0. Renamed files are reported under their
+// new path, deleted files under their old one.
+func (g *GitLab) CommitFileStats(ctx context.Context, repo model.Repo, hash string) ([]FileChange, error) {
+	projectID := gitlabProjectID(repo.URL)
+	if projectID == "" {
+		return nil, fmt.Errorf("cannot parse project path from URL: %s", repo.URL)
+	}
+
+	var all []FileChange
+	nextURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits/%s/diff?per_page=100",
+		g.baseURL, projectID, url.PathEscape(hash))
+
+	for nextURL != "" {
+		resp, err := g.doGet(ctx, nextURL)
+		if err != nil {
+			return nil, fmt.Errorf("gitlab commit diff API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("gitlab commit diff API returned status %d", resp.StatusCode)
+		}
+
+		var diffs []gitlabDiff
+		if err := json.NewDecoder(resp.Body).Decode(&diffs); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("decode gitlab commit diff: %w", err)
+		}
+		resp.Body.Close()
+
+		for _, d := range diffs {
+			fc := FileChange{Path: d.NewPath}
+			if fc.Path == "" || d.DeletedFile {
+				fc.Path = d.OldPath
+			}
+			fc.Additions, fc.Deletions = countDiffLines(d.Diff)
+			all = append(all, fc)
+		}
+
+		nextURL = g.nextPageURL(nextURL, resp)
+	}
+
+	return all, nil
+}
+
+// countDiffLines counts the added and removed lines of a unified diff body.
+// Only lines inside hunks count, so "---"/"+++" file headers are skipped
+// while removed lines that themselves start with "--" are not.
+func countDiffLines(diff string) (additions, deletions int64) {
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			additions++
+		case strings.HasPrefix(line, "-"):
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// OpenIssues counts open issues for a GitLab project using the X-Total
+// response header. Returns ErrIssuesDisabled when the project has issues
+// turned off (GitLab responds 403).
+func (g *GitLab) OpenIssues(ctx context.Context, repo model.Repo) (int, error) {
+	projectID := gitlabProjectID(repo.URL)
+	if projectID == "" {
+		return 0, fmt.Errorf("cannot parse project path from URL: %s", repo.URL)
+	}
+
+	count := 0
+	nextURL := fmt.Sprintf("%s/api/v4/projects/%s/issues?state=opened&per_page=100",
+		g.baseURL, projectID)
+
+	for nextURL != "" {
+		resp, err := g.doGet(ctx, nextURL)
+		if err != nil {
+			return 0, fmt.Errorf("gitlab issues API: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusForbidden {
+			resp.Body.Close()
+			return 0, ErrIssuesDisabled
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return 0, fmt.Errorf("gitlab issues API returned status %d", resp.StatusCode)
+		}
+
+		// X-Total is omitted for very large result sets; count pages then.
+		if total := resp.Header.Get("X-Total"); total != "" {
+			resp.Body.Close()
+			n, err := strconv.Atoi(total)
+			if err != nil {
+				return 0, fmt.Errorf("parse gitlab X-Total header: %w", err)
+			}
+			return n, nil
+		}
+
+		var issues []json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+			resp.Body.Close()
+			return 0, fmt.Errorf("decode gitlab issues: %w", err)
+		}
+		resp.Body.Close()
+
+		count += len(issues)
+		nextURL = g.nextPageURL(nextURL, resp)
+	}
+
+	return count, nil
+}
+
+// ensure GitLab satisfies the interfaces at compile time.
 var _ Provider = (*GitLab)(nil)
 var _ CommitLister = (*GitLab)(nil)
+var _ ChurnLister = (*GitLab)(nil)
+var _ CommitRanger = (*GitLab)(nil)
@@ -4,11 +4,13 @@ package provider_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/provider"
@@ -77,6 +79,48 @@ func TestGitLabListRepos(t *testing.T) {
 	}
 }
 
+func TestGitLabListReposByPath(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/platform%2Fbackend%2Fapi":
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":               7,
+				"path":             "api",
+				"name":             "API",
+				"web_url":          "https://gitlab.com/platform/backend/api",
+				"http_url_to_repo": "https://gitlab.com/platform/backend/api.git",
+				"default_branch":   "main",
+				"archived":         true,
+				"namespace":        map[string]any{"full_path": "platform/backend"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	repos, err := gl.ListRepos(context.Background(), provider.ListOpts{
+		Projects: []string{"platform/backend/api"},
+	})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Slug != "api" || repos[0].Project != "platform/backend" || !repos[0].Archived {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+	if len(paths) != 1 {
+		t.Errorf("expected one direct project request and no group listing, got %v", paths)
+	}
+
+	_, err = gl.ListRepos(context.Background(), provider.ListOpts{Projects: []string{"platform/missing"}})
+	if err == nil || !strings.Contains(err.Error(), "platform/missing not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
 func TestGitLabExcludeForksAndArchived(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode([]map[string]any{
@@ -96,7 +140,7 @@ func TestGitLabExcludeForksAndArchived(t *testing.T) {
 				"id": 3, "path": "forked-repo", "path_with_namespace": "g/forked-repo",
 				"name": "Forked", "web_url": "h", "http_url_to_repo": "c",
 				"default_branch": "main", "archived": false,
-				"forked_from_project": map[string]any{"id": 99},
+				"forked_from_project": map[string]any{"id": 99, "http_url_to_repo": "https://gitlab.com/up/forked-repo.git"},
 				"namespace":           map[string]any{"full_path": "g"},
 			},
 		})
@@ -111,6 +155,34 @@ func TestGitLabExcludeForksAndArchived(t *testing.T) {
 	if len(repos) != 2 {
 		t.Fatalf("expected 2 repos (forks excluded), got %d", len(repos))
 	}
+
+	repos, _ = gl.ListRepos(context.Background(), provider.ListOpts{
+		Organization:    "g",
+		IncludeArchived: true,
+		IncludeForks:    true,
+	})
+	if len(repos) != 3 || !repos[2].Fork || repos[2].ParentURL != "https://gitlab.com/up/forked-repo.git" {
+		t.Errorf("expected fork with parent URL, got %+v", repos)
+	}
+}
+
+func TestGitLabOnlyArchived(t *testing.T) {
+	var archived string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archived = r.URL.Query().Get("archived")
+		json.NewEncoder(w).Encode([]map[string]any{})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	gl.ListRepos(context.Background(), provider.ListOpts{Organization: "g", OnlyArchived: true})
+	if archived != "true" {
+		t.Errorf("expected archived=true with OnlyArchived, got %q", archived)
+	}
+	gl.ListRepos(context.Background(), provider.ListOpts{Organization: "g"})
+	if archived != "false" {
+		t.Errorf("expected archived=false by default, got %q", archived)
+	}
 }
 
 func TestGitLabIncludeSlugFilter(t *testing.T) {
@@ -151,6 +223,25 @@ func TestGitLabExcludeSlugFilter(t *testing.T) {
 	}
 }
 
+func TestGitLabExcludeProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{
+			{"id": 1, "path": "repo-a", "path_with_namespace": "g/repo-a", "name": "A", "web_url": "h", "http_url_to_repo": "c", "default_branch": "main", "archived": false, "forked_from_project": nil, "namespace": map[string]any{"full_path": "g"}},
+			{"id": 2, "path": "repo-b", "path_with_namespace": "g/legacy/repo-b", "name": "B", "web_url": "h", "http_url_to_repo": "c", "default_branch": "main", "archived": false, "forked_from_project": nil, "namespace": map[string]any{"full_path": "g/legacy"}},
+		})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	repos, _ := gl.ListRepos(context.Background(), provider.ListOpts{
+		Organization:    "g",
+		ExcludeProjects: []string{"g/legacy"},
+	})
+	if len(repos) != 1 || repos[0].Slug != "repo-a" {
+		t.Fatalf("expected only repo-a, got %v", repos)
+	}
+}
+
 func TestGitLabAuthHeader(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if got := r.Header.Get("Authorization"); got != "Bearer my-pat" {
@@ -262,6 +353,115 @@ func TestGitLabCommitStats(t *testing.T) {
 	}
 }
 
+func TestGitLabCommitFileStats(t *testing.T) {
+	tests := []struct {
+		name string
+		diff map[string]any
+		want provider.FileChange
+	}{
+		{
+			name: "modified file",
+			diff: map[string]any{"old_path": "main.go", "new_path": "main.go", "diff": "@@ -1,3 +1,4 @@\n package main\n-var a = 1\n+var a = 2\n+var b = 3\n"},
+			want: provider.FileChange{Path: "main.go", Additions: 2, Deletions: 1},
+		},
+		{
+			name: "renamed file uses the new path",
+			diff: map[string]any{"old_path": "old.go", "new_path": "new.go", "renamed_file": true, "diff": "@@ -1 +1 @@\n-x\n+y\n"},
+			want: provider.FileChange{Path: "new.go", Additions: 1, Deletions: 1},
+		},
+		{
+			name: "deleted file uses the old path",
+			diff: map[string]any{"old_path": "gone.sql", "new_path": "gone.sql", "deleted_file": true, "diff": "@@ -1,2 +0,0 @@\n--- a SQL comment\n-SELECT 1;\n"},
+			want: provider.FileChange{Path: "gone.sql", Deletions: 2},
+		},
+		{
+			name: "missing new path falls back to the old path",
+			diff: map[string]any{"old_path": "legacy.txt", "diff": "@@ -1 +0,0 @@\n-bye\n"},
+			want: provider.FileChange{Path: "legacy.txt", Deletions: 1},
+		},
+		{
+			name: "collapsed diff counts as zero",
+			diff: map[string]any{"old_path": "big.json", "new_path": "big.json", "diff": "", "too_large": true},
+			want: provider.FileChange{Path: "big.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/repository/commits/abc123/diff") {
+					json.NewEncoder(w).Encode([]map[string]any{tt.diff})
+					return
+				}
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			gl := provider.NewGitLab("test-token", server.URL, nil)
+			files, err := gl.CommitFileStats(context.Background(), model.Repo{
+				Slug: "repo-1",
+				URL:  server.URL + "/mygroup/repo-1",
+			}, "abc123")
+			if err != nil {
+				t.Fatalf("CommitFileStats: %v", err)
+			}
+			if len(files) != 1 || files[0] != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, files)
+			}
+		})
+	}
+}
+
+func TestGitLabCommitFileStatsPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("X-Next-Page", "2")
+			json.NewEncoder(w).Encode([]map[string]any{{"old_path": "a.go", "new_path": "a.go", "diff": "@@ -0,0 +1 @@\n+a\n"}})
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{{"old_path": "b.go", "new_path": "b.go", "diff": "@@ -0,0 +1 @@\n+b\n"}})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	files, err := gl.CommitFileStats(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  server.URL + "/mygroup/repo-1",
+	}, "abc123")
+	if err != nil {
+		t.Fatalf("CommitFileStats: %v", err)
+	}
+	if len(files) != 2 || files[1].Path != "b.go" {
+		t.Errorf("expected files from both pages, got %+v", files)
+	}
+}
+
+func TestGitLabCommitStatsSkipsMerges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"id":         "merge1",
+			"parent_ids": []string{"p1", "p2"},
+			"stats": map[string]any{
+				"additions": 900,
+				"deletions": 400,
+			},
+		})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	additions, deletions, err := gl.CommitStats(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  server.URL + "/mygroup/repo-1",
+	}, "merge1")
+	if err != nil {
+		t.Fatalf("CommitStats: %v", err)
+	}
+	if additions != 0 || deletions != 0 {
+		t.Errorf("expected merge commit to report 0/0, got %d/%d", additions, deletions)
+	}
+}
+
 func TestGitLabListCommitsLimit(t *testing.T) {
 	page := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -294,3 +494,108 @@ func TestGitLabListCommitsLimit(t *testing.T) {
 		t.Errorf("expected 150 commits (limited), got %d", len(commits))
 	}
 }
+
+func TestGitLabOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/issues") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if got := r.URL.Query().Get("state"); got != "opened" {
+			t.Errorf("expected state=opened, got %q", got)
+		}
+		w.Header().Set("X-Total", "42")
+		json.NewEncoder(w).Encode([]map[string]any{{"iid": 1}})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	count, err := gl.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  server.URL + "/mygroup/repo-1",
+	})
+	if err != nil {
+		t.Fatalf("OpenIssues: %v", err)
+	}
+	if count != 42 {
+		t.Errorf("expected 42 open issues from X-Total, got %d", count)
+	}
+}
+
+func TestGitLabOpenIssuesDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	_, err := gl.OpenIssues(context.Background(), model.Repo{
+		Slug: "repo-1",
+		URL:  server.URL + "/mygroup/repo-1",
+	})
+	if !errors.Is(err, provider.ErrIssuesDisabled) {
+		t.Errorf("expected ErrIssuesDisabled, got %v", err)
+	}
+}
+
+func TestGitLabListReposLastActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("statistics") != "true" {
+			t.Errorf("expected statistics=true, got %s", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode([]map[string]any{
+			{
+				"id":               1,
+				"path":             "repo-1",
+				"name":             "Repo 1",
+				"http_url_to_repo": "https://gitlab.com/mygroup/repo-1.git",
+				"last_activity_at": "2025-06-15T10:30:00.000Z",
+				"namespace":        map[string]any{"full_path": "mygroup"},
+				"statistics":       map[string]any{"repository_size": 3 * 1024 * 1024},
+			},
+		})
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	repos, err := gl.ListRepos(context.Background(), provider.ListOpts{Organization: "mygroup"})
+	if err != nil {
+		t.Fatalf("failed to list repos: %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("expected 1 repo, got %d", len(repos))
+	}
+	want := time.Date(2025, 6, 15, 10, 30, 0, 0, time.UTC)
+	if !repos[0].LastActivity.Equal(want) {
+		t.Errorf("expected LastActivity %s, got %s", want, repos[0].LastActivity)
+	}
+	if repos[0].SizeKB != 3072 {
+		t.Errorf("expected SizeKB 3072 from repository_size bytes, got %d", repos[0].SizeKB)
+	}
+}
+
+func TestGitLabListCommitsEmptyRepo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"404 Repository Not Found"}`))
+	}))
+	defer server.Close()
+
+	gl := provider.NewGitLab("test-token", server.URL, nil)
+	repo := model.Repo{Slug: "empty", URL: server.URL + "/mygroup/empty"}
+	commits, err := gl.ListCommits(context.Background(), repo, 100)
+	if err != nil {
+		t.Fatalf("expected no error for an empty repository, got %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("expected no commits, got %v", commits)
+	}
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"404 Project Not Found"}`))
+	})
+	if _, err := gl.ListCommits(context.Background(), repo, 100); err == nil {
+		t.Error("expected a missing project to stay an error")
+	}
+}
@@ -3,6 +3,12 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dsablic/codemium/internal/model"
@@ -16,16 +22,117 @@ type ListOpts struct {
 	Projects        []string
 	Repos           []string
 	Exclude         []string
+	ExcludeProjects []string // glob patterns matched against Repo.Project
 	IncludeArchived bool
+	OnlyArchived    bool // list archived repos only (takes precedence over IncludeArchived)
 	IncludeForks    bool
+	// Role, for Bitbucket Cloud, lists only repositories the caller has at
+	// least this role on (one of BitbucketRoles); empty lists every
+	// repository in the workspace.
+	Role string
+
+	// OnPageError, when set, makes ListRepos tolerate a failed listing page:
+	// the page is retried once, then skipped and reported through this
+	// callback. When nil, a failed page aborts the listing.
+	OnPageError func(pageURL string, err error)
+}
+
+// skipArchived reports whether the archived filter leaves out a repo with
+// the given archived state.
+func (o ListOpts) skipArchived(archived bool) bool {
+	if o.OnlyArchived {
+		return !archived
+	}
+	return archived && !o.IncludeArchived
+}
+
+// maxConsecutivePageFailures bounds how many listing pages in a row may be
+// skipped before ListRepos gives up, so an outage doesn't loop forever.
+const maxConsecutivePageFailures = 3
+
+// pageFetcher fetches one page of a repository listing and returns the repos
+// and the next page URL ("" when done).
+type pageFetcher func(ctx context.Context, pageURL string) ([]model.Repo, string, error)
+
+// pageSkipper applies ListOpts.OnPageError to a provider's pagination loop.
+type pageSkipper struct {
+	opts        ListOpts
+	consecutive int
+}
+
+// fetch fetches pageURL. Without OnPageError it behaves exactly like fetch.
+// With it, a failed page is retried once and then skipped: the failure is
+// reported and the returned next URL is the following page number.
+func (p *pageSkipper) fetch(ctx context.Context, pageURL string, fetch pageFetcher) ([]model.Repo, string, error) {
+	repos, next, err := fetch(ctx, pageURL)
+	if err == nil || p.opts.OnPageError == nil || ctx.Err() != nil {
+		p.consecutive = 0
+		return repos, next, err
+	}
+
+	if repos, next, err = fetch(ctx, pageURL); err == nil {
+		p.consecutive = 0
+		return repos, next, nil
+	}
+
+	p.consecutive++
+	if p.consecutive > maxConsecutivePageFailures {
+		return nil, "", fmt.Errorf("%w (gave up after %d consecutive failed pages)", err, maxConsecutivePageFailures)
+	}
+	following, ok := followingPageURL(pageURL)
+	if !ok {
+		return nil, "", err
+	}
+	p.opts.OnPageError(pageURL, err)
+	return nil, following, nil
+}
+
+// followingPageURL returns pageURL with its "page" query parameter
+// incremented; a missing parameter counts as page 1. Offset-paged URLs
+// (Bitbucket Server's "start"/"limit") advance "start" by "limit" instead.
+func followingPageURL(pageURL string) (string, bool) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", false
+	}
+	q := u.Query()
+	if v := q.Get("start"); v != "" {
+		start, err := strconv.Atoi(v)
+		if err != nil {
+			return "", false
+		}
+		limit, err := strconv.Atoi(q.Get("limit"))
+		if err != nil || limit <= 0 {
+			return "", false
+		}
+		q.Set("start", strconv.Itoa(start+limit))
+		u.RawQuery = q.Encode()
+		return u.String(), true
+	}
+	page := 1
+	if v := q.Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil {
+			return "", false
+		}
+	}
+	q.Set("page", strconv.Itoa(page+1))
+	u.RawQuery = q.Encode()
+	return u.String(), true
 }
 
-// Provider is the interface that Bitbucket, GitHub, and GitLab implement
-// for listing repositories.
+// Provider is the interface that Bitbucket, Bitbucket Server, GitHub, and
+// GitLab implement for listing repositories.
 type Provider interface {
 	ListRepos(ctx context.Context, opts ListOpts) ([]model.Repo, error)
 }
 
+// ProjectLister is implemented by providers that group repositories into
+// projects (Bitbucket Cloud and Server), for the interactive project picker.
+type ProjectLister interface {
+	ListProjects(ctx context.Context, workspace string) ([]Project, error)
+}
+
 // CommitInfo represents a commit returned from a provider API.
 type CommitInfo struct {
 	Hash    string
@@ -40,6 +147,75 @@ type CommitLister interface {
 	CommitStats(ctx context.Context, repo model.Repo, hash string) (additions, deletions int64, err error)
 }
 
+// CommitRanger is implemented by providers that can list the commits made
+// within a date range in one paged sweep, newest first. A zero since or until
+// leaves that end of the range open; limit caps the result (0 = unlimited).
+type CommitRanger interface {
+	CommitRange(ctx context.Context, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error)
+}
+
+// ListCommitsInRange lists up to limit commits of repo dated between since
+// and until (inclusive; zero bounds are open). It uses CommitRange when cl
+// implements CommitRanger, and otherwise filters the full ListCommits history.
+func ListCommitsInRange(ctx context.Context, cl CommitLister, repo model.Repo, since, until time.Time, limit int) ([]CommitInfo, error) {
+	if r, ok := cl.(CommitRanger); ok {
+		return r.CommitRange(ctx, repo, since, until, limit)
+	}
+	if since.IsZero() && until.IsZero() {
+		return cl.ListCommits(ctx, repo, limit)
+	}
+	commits, err := cl.ListCommits(ctx, repo, 0)
+	if err != nil {
+		return nil, err
+	}
+	var in []CommitInfo
+	for _, c := range commits {
+		if ok, _ := inRange(c.Date, since, until); ok {
+			in = append(in, c)
+			if limit > 0 && len(in) >= limit {
+				break
+			}
+		}
+	}
+	return in, nil
+}
+
+// dateRangeQuery returns the "&since=...&until=..." query suffix shared by the
+// GitHub and GitLab commits APIs, omitting open bounds.
+func dateRangeQuery(since, until time.Time) string {
+	q := url.Values{}
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		q.Set("until", until.UTC().Format(time.RFC3339))
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "&" + q.Encode()
+}
+
+// inRange reports whether a commit dated d falls within [since, until], and
+// whether d is before since, so a newest-first listing can stop paging.
+func inRange(d, since, until time.Time) (ok, past bool) {
+	if !since.IsZero() && d.Before(since) {
+		return false, true
+	}
+	if !until.IsZero() && d.After(until) {
+		return false, false
+	}
+	return true, false
+}
+
+// emptyRepoResponse reports whether an error response body carries the
+// provider's message for a repository without commits. Commit listings treat
+// that as an empty result rather than a failure.
+func emptyRepoResponse(body io.Reader, message string) bool {
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	return strings.Contains(strings.ToLower(string(data)), strings.ToLower(message))
+}
+
 // FileChange represents a file modified in a commit.
 type FileChange struct {
 	Path      string
@@ -52,3 +228,22 @@ type ChurnLister interface {
 	CommitLister
 	CommitFileStats(ctx context.Context, repo model.Repo, hash string) ([]FileChange, error)
 }
+
+// ForkParentResolver is implemented by providers whose repository listing
+// does not include a fork's parent (GitHub), to look up Repo.ParentURL.
+type ForkParentResolver interface {
+	ForkParent(ctx context.Context, repo model.Repo) (cloneURL string, err error)
+}
+
+// ErrIssuesDisabled is returned by OpenIssues when a repository has its
+// issue tracker turned off.
+var ErrIssuesDisabled = errors.New("issues disabled")
+
+// ErrForbidden is wrapped by provider errors for a 403 response, when the
+// token cannot read a repository or listing.
+var ErrForbidden = errors.New("access denied")
+
+// IssueCounter extends Provider with open issue counts.
+type IssueCounter interface {
+	OpenIssues(ctx context.Context, repo model.Repo) (int, error)
+}
@@ -1,19 +1,32 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 const defaultMaxRetries = 5
 
-// RateLimitTransport wraps an http.RoundTripper with rate limiting and 429 retry.
+// DefaultHTTPTimeout bounds a single provider API request attempt, from
+// sending it to reading the last byte of the response body.
+const DefaultHTTPTimeout = 60 * time.Second
+
+// RateLimitTransport wraps an http.RoundTripper with rate limiting and retry
+// on 429 and on GitHub's secondary rate limit 403s.
 type RateLimitTransport struct {
 	ReqPerSec float64           // 0 = unlimited (retry-only)
 	Base      http.RoundTripper // nil = http.DefaultTransport
+	// Timeout is a deadline applied to each attempt separately, so rate
+	// limit backoff between retries doesn't count against it. 0 = none.
+	Timeout time.Duration
 
 	once    sync.Once
 	limiter chan struct{}
@@ -43,7 +56,31 @@ func (t *RateLimitTransport) base() http.RoundTripper {
 	return http.DefaultTransport
 }
 
-// RoundTrip implements http.RoundTripper with rate limiting and 429 retry.
+// shouldRetry reports whether resp is a rate limit response worth retrying.
+// GitHub signals secondary (abuse) limits with a 403 carrying Retry-After or
+// a body mentioning "secondary rate limit"; other 403s are real permission
+// errors and are returned unchanged (with the body restored for the caller).
+func shouldRetry(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if resp.Header.Get("Retry-After") != "" {
+			return true
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return false
+		}
+		return strings.Contains(strings.ToLower(string(body)), "secondary rate limit")
+	default:
+		return false
+	}
+}
+
+// RoundTrip implements http.RoundTripper with rate limiting and retry.
 func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	t.once.Do(t.init)
 
@@ -57,18 +94,32 @@ func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error
 			}
 		}
 
-		resp, err := t.base().RoundTrip(req)
+		attemptReq, cancel := req, context.CancelFunc(func() {})
+		if t.Timeout > 0 {
+			var ctx context.Context
+			ctx, cancel = context.WithTimeout(req.Context(), t.Timeout)
+			attemptReq = req.WithContext(ctx)
+		}
+
+		resp, err := t.base().RoundTrip(attemptReq)
 		if err != nil {
+			cancel()
+			if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+				return nil, fmt.Errorf("no response within %s: %w", t.Timeout, err)
+			}
 			return nil, err
 		}
 
-		if resp.StatusCode != http.StatusTooManyRequests || attempt >= defaultMaxRetries {
+		if attempt >= defaultMaxRetries || !shouldRetry(resp) {
+			// The deadline keeps covering the body until the caller closes it
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
 			return resp, nil
 		}
 
 		// Drain and close body before retry
 		io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
+		cancel()
 
 		// Backoff: use Retry-After header or exponential (1s, 2s, 4s...)
 		delay := time.Duration(1<<uint(attempt)) * time.Second
@@ -85,3 +136,16 @@ func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error
 		}
 	}
 }
+
+// cancelOnClose releases an attempt's deadline when the response body is
+// closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,142 @@
+// internal/serve/serve.go
+
+// Package serve exposes a report file over HTTP for `codemium serve`.
+package serve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dsablic/codemium/internal/model"
+	"github.com/dsablic/codemium/internal/output"
+)
+
+// Handler serves the report at path: the raw JSON at /api/report and a
+// rendered page at /. The file is stat'ed on every request and re-read when
+// its modification time or size changes, so a report rewritten by a
+// scheduled analyze run shows up without restarting. If a rewrite can't be
+// parsed (e.g. it is caught half-written), the last good version is served.
+type Handler struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	data    []byte
+	page    []byte
+}
+
+// New returns a Handler for the report file at path. The file is loaded once
+// up front so a missing or invalid report fails at startup.
+func New(path string) (*Handler, error) {
+	h := &Handler{path: path}
+	if err := h.reload(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/report":
+		data, _ := h.current()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	case "/":
+		_, page := h.current()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// current returns the latest report JSON and rendered page, reloading the
+// file first if it changed on disk.
+func (h *Handler) current() (data, page []byte) {
+	if err := h.reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: keeping previous report: %v\n", err)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.data, h.page
+}
+
+// reload re-reads and re-renders the report if its modification time or
+// size differs from the loaded version.
+func (h *Handler) reload() error {
+	info, err := os.Stat(h.path)
+	if err != nil {
+		return fmt.Errorf("stat report: %w", err)
+	}
+
+	h.mu.Lock()
+	unchanged := h.data != nil && info.ModTime().Equal(h.modTime) && info.Size() == h.size
+	h.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return fmt.Errorf("read report: %w", err)
+	}
+	page, err := renderPage(data)
+	if err != nil {
+		return fmt.Errorf("render %s: %w", h.path, err)
+	}
+
+	h.mu.Lock()
+	h.modTime, h.size, h.data, h.page = info.ModTime(), info.Size(), data, page
+	h.mu.Unlock()
+	return nil
+}
+
+var trendsTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>codemium report</title>
+<style>body{font-family:sans-serif;margin:2rem}pre{white-space:pre-wrap}</style>
+</head>
+<body>
+<p>Generated {{.GeneratedAt}} &middot; <a href="/api/report">JSON</a></p>
+<pre>{{.Markdown}}</pre>
+</body>
+</html>
+`))
+
+// renderPage renders an analyze report with the HTML report renderer, and a
+// trends report, which has no HTML rendering, as its markdown summary
+// wrapped in a page.
+func renderPage(data []byte) ([]byte, error) {
+	var page bytes.Buffer
+
+	var trends model.TrendsReport
+	if err := json.Unmarshal(data, &trends); err == nil && len(trends.Snapshots) > 0 {
+		var md bytes.Buffer
+		if err := output.WriteTrendsMarkdown(&md, trends); err != nil {
+			return nil, err
+		}
+		err := trendsTemplate.Execute(&page, struct {
+			GeneratedAt string
+			Markdown    string
+		}{trends.GeneratedAt, md.String()})
+		return page.Bytes(), err
+	}
+
+	var report model.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse JSON report: %w", err)
+	}
+	if err := output.WriteHTML(&page, report); err != nil {
+		return nil, err
+	}
+	return page.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+package serve_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dsablic/codemium/internal/serve"
+)
+
+func get(t *testing.T, srv *httptest.Server, path string) (string, string) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.Header.Get("Content-Type"), string(body)
+}
+
+func TestHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	first := `{"generated_at":"2026-01-01T00:00:00Z","provider":"github","repositories":[{"repository":"api","provider":"github","url":"u","languages":[],"totals":{"code":10}}],"totals":{"code":10},"by_language":[]}`
+	if err := os.WriteFile(path, []byte(first), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := serve.New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ct, body := get(t, srv, "/api/report")
+	if ct != "application/json" || body != first {
+		t.Errorf("unexpected /api/report: %s %q", ct, body)
+	}
+	ct, body = get(t, srv, "/")
+	if !strings.HasPrefix(ct, "text/html") || !strings.Contains(body, "api") || !strings.Contains(body, "2026-01-01T00:00:00Z") {
+		t.Errorf("unexpected page: %s\n%s", ct, body)
+	}
+	if !strings.Contains(body, "<h1>Code Statistics Report</h1>") || strings.Contains(body, "<pre>") {
+		t.Errorf("expected the HTML report renderer for an analyze report, got:\n%s", body)
+	}
+
+	// A rewritten report is picked up without restarting
+	second := strings.Replace(first, `"api"`, `"billing"`, 1)
+	os.WriteFile(path, []byte(second), 0644)
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Minute))
+	if _, body = get(t, srv, "/"); !strings.Contains(body, "billing") {
+		t.Errorf("expected reloaded report, got:\n%s", body)
+	}
+
+	// An unparsable rewrite keeps the last good report
+	os.WriteFile(path, []byte(`{"repositories": [`), 0644)
+	os.Chtimes(path, time.Now(), time.Now().Add(2*time.Minute))
+	if _, body = get(t, srv, "/api/report"); body != second {
+		t.Errorf("expected previous report to be kept, got %q", body)
+	}
+}
+
+func TestNewInvalidReport(t *testing.T) {
+	if _, err := serve.New(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing report")
+	}
+}
@@ -0,0 +1,12 @@
+// internal/worker/export_test.go
+package worker
+
+import "time"
+
+// SetRetryBaseDelay shortens the retry backoff for tests and returns a
+// function restoring the previous value.
+func SetRetryBaseDelay(d time.Duration) func() {
+	old := retryBaseDelay
+	retryBaseDelay = d
+	return func() { retryBaseDelay = old }
+}
@@ -3,11 +3,87 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/dsablic/codemium/internal/model"
 )
 
+// Bound describes what limits a phase's throughput, which determines its
+// default concurrency.
+type Bound int
+
+const (
+	// NetworkBound phases spend most of their time waiting on clones or API calls.
+	NetworkBound Bound = iota
+	// CPUBound phases spend most of their time checking out and scanning files.
+	CPUBound
+)
+
+// DefaultNetworkConcurrency is the default worker count for network-bound phases.
+const DefaultNetworkConcurrency = 5
+
+// DefaultConcurrency returns the default worker count for a phase of the given kind.
+func DefaultConcurrency(b Bound) int {
+	if b == CPUBound {
+		return runtime.NumCPU()
+	}
+	return DefaultNetworkConcurrency
+}
+
+// ErrorPolicy controls what RunWithProgress does when processing a
+// repository fails.
+type ErrorPolicy string
+
+const (
+	// Skip records the error in the repo's Result and carries on. This is
+	// the default, and also what the zero value does.
+	Skip ErrorPolicy = "skip"
+	// FailFast cancels the remaining work on the first error. Repos still in
+	// flight finish with a context error; repos not yet started are dropped.
+	FailFast ErrorPolicy = "fail-fast"
+	// Retry retries a failed repo with exponential backoff before recording
+	// the last error.
+	Retry ErrorPolicy = "retry"
+)
+
+// retryAttempts is the total number of tries per repo under the Retry policy.
+const retryAttempts = 3
+
+// retryBaseDelay is the wait before the first retry; it doubles each time.
+var retryBaseDelay = 2 * time.Second
+
+// ParseErrorPolicy parses an --on-error value.
+func ParseErrorPolicy(s string) (ErrorPolicy, error) {
+	switch p := ErrorPolicy(s); p {
+	case Skip, FailFast, Retry:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid error policy %q (use fail-fast, skip, or retry)", s)
+}
+
+// FirstError returns the first repository error in results, ignoring the
+// context errors of repos interrupted by a FailFast cancellation, or nil.
+func FirstError(results []Result) error {
+	var interrupted error
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+		err := fmt.Errorf("%s: %w", r.Repo.Slug, r.Err)
+		if !errors.Is(r.Err, context.Canceled) {
+			return err
+		}
+		if interrupted == nil {
+			interrupted = err
+		}
+	}
+	return interrupted
+}
+
 // Result holds the outcome of processing a single repository.
 type Result struct {
 	Repo  model.Repo
@@ -23,15 +99,34 @@ type ProcessFunc func(ctx context.Context, repo model.Repo) (*model.RepoStats, e
 
 // Run processes repos concurrently using a bounded worker pool.
 func Run(ctx context.Context, repos []model.Repo, concurrency int, process ProcessFunc) []Result {
-	return RunWithProgress(ctx, repos, concurrency, process, nil)
+	return RunWithProgress(ctx, repos, concurrency, process, nil, Skip)
 }
 
-// RunWithProgress processes repos concurrently with an optional progress callback.
-func RunWithProgress(ctx context.Context, repos []model.Repo, concurrency int, process ProcessFunc, onProgress ProgressFunc) []Result {
+// ResultFunc is called with each repository's final Result as soon as it
+// completes (after any retries). Calls are serialized and made in the order
+// results are appended to the returned slice.
+type ResultFunc func(Result)
+
+// RunWithProgress processes repos concurrently with an optional progress
+// callback, handling failures according to policy.
+func RunWithProgress(ctx context.Context, repos []model.Repo, concurrency int, process ProcessFunc, onProgress ProgressFunc, policy ErrorPolicy) []Result {
+	return RunWithResults(ctx, repos, concurrency, process, onProgress, nil, policy)
+}
+
+// RunWithResults is RunWithProgress with an additional onResult callback,
+// letting callers persist each repository's outcome while the rest are
+// still running.
+func RunWithResults(ctx context.Context, repos []model.Repo, concurrency int, process ProcessFunc, onProgress ProgressFunc, onResult ResultFunc, policy ErrorPolicy) []Result {
 	if concurrency < 1 {
 		concurrency = 1
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if policy == Retry {
+		process = withRetry(process)
+	}
+
 	var (
 		mu        sync.Mutex
 		results   []Result
@@ -47,6 +142,10 @@ func RunWithProgress(ctx context.Context, repos []model.Repo, concurrency int, p
 		}
 
 		sem <- struct{}{} // acquire
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
 		wg.Add(1)
 
 		go func(r model.Repo) {
@@ -56,11 +155,19 @@ func RunWithProgress(ctx context.Context, repos []model.Repo, concurrency int, p
 			stats, err := process(ctx, r)
 
 			mu.Lock()
-			results = append(results, Result{Repo: r, Stats: stats, Err: err})
+			result := Result{Repo: r, Stats: stats, Err: err}
+			results = append(results, result)
 			completed++
 			c := completed
+			if onResult != nil {
+				onResult(result)
+			}
 			mu.Unlock()
 
+			if err != nil && policy == FailFast {
+				cancel()
+			}
+
 			if onProgress != nil {
 				onProgress(c, len(repos), r)
 			}
@@ -71,10 +178,31 @@ func RunWithProgress(ctx context.Context, repos []model.Repo, concurrency int, p
 	return results
 }
 
+// withRetry wraps process so that failures are retried up to retryAttempts
+// times in total, waiting retryBaseDelay, then twice that, and so on between
+// tries. Cancellation stops retrying immediately.
+func withRetry(process ProcessFunc) ProcessFunc {
+	return func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		delay := retryBaseDelay
+		for attempt := 1; ; attempt++ {
+			stats, err := process(ctx, repo)
+			if err == nil || attempt >= retryAttempts || ctx.Err() != nil {
+				return stats, err
+			}
+			select {
+			case <-ctx.Done():
+				return stats, err
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+	}
+}
+
 // TrendsResult holds the outcome of processing a single repository across time periods.
 type TrendsResult struct {
 	Repo      model.Repo
-	Snapshots map[string]*model.RepoStats // period label -> stats
+	Snapshots map[string]*model.RepoStats // period label -> stats (nil: repo didn't exist yet)
 	Err       error
 }
 
@@ -3,9 +3,13 @@ package worker_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
+	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/dsablic/codemium/internal/model"
 	"github.com/dsablic/codemium/internal/worker"
@@ -93,3 +97,120 @@ func TestPoolRespectsContext(t *testing.T) {
 		t.Error("expected cancellation to prevent processing all repos")
 	}
 }
+
+func TestDefaultConcurrency(t *testing.T) {
+	if got := worker.DefaultConcurrency(worker.NetworkBound); got != worker.DefaultNetworkConcurrency {
+		t.Errorf("expected network-bound default %d, got %d", worker.DefaultNetworkConcurrency, got)
+	}
+	if got := worker.DefaultConcurrency(worker.CPUBound); got != runtime.NumCPU() {
+		t.Errorf("expected CPU-bound default %d, got %d", runtime.NumCPU(), got)
+	}
+}
+
+func TestPoolFailFastStopsOnFirstError(t *testing.T) {
+	repos := make([]model.Repo, 10)
+	for i := range repos {
+		repos[i] = model.Repo{Slug: fmt.Sprintf("repo-%d", i)}
+	}
+
+	var processed atomic.Int32
+	results := worker.RunWithProgress(context.Background(), repos, 1, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		processed.Add(1)
+		if repo.Slug == "repo-2" {
+			return nil, fmt.Errorf("HTTP 404")
+		}
+		return &model.RepoStats{Repository: repo.Slug}, nil
+	}, nil, worker.FailFast)
+
+	if got := processed.Load(); got != 3 {
+		t.Errorf("expected processing to stop after the failing repo (3 processed), got %d", got)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected 3 results, got %d", len(results))
+	}
+	err := worker.FirstError(results)
+	if err == nil || !strings.Contains(err.Error(), "repo-2: HTTP 404") {
+		t.Errorf("expected first error from repo-2, got %v", err)
+	}
+}
+
+func TestPoolRetryRecoversTransientErrors(t *testing.T) {
+	defer worker.SetRetryBaseDelay(time.Millisecond)()
+
+	var attempts atomic.Int32
+	repos := []model.Repo{{Slug: "flaky"}, {Slug: "broken"}}
+	results := worker.RunWithProgress(context.Background(), repos, 2, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		if repo.Slug == "broken" {
+			return nil, fmt.Errorf("always fails")
+		}
+		if attempts.Add(1) < 3 {
+			return nil, fmt.Errorf("transient")
+		}
+		return &model.RepoStats{Repository: repo.Slug}, nil
+	}, nil, worker.Retry)
+
+	for _, r := range results {
+		switch r.Repo.Slug {
+		case "flaky":
+			if r.Err != nil {
+				t.Errorf("expected flaky repo to succeed on retry, got %v", r.Err)
+			}
+		case "broken":
+			if r.Err == nil {
+				t.Error("expected broken repo to fail after retries")
+			}
+		}
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("expected 3 attempts for flaky repo, got %d", got)
+	}
+}
+
+func TestFirstErrorPrefersRealErrors(t *testing.T) {
+	results := []worker.Result{
+		{Repo: model.Repo{Slug: "ok"}},
+		{Repo: model.Repo{Slug: "interrupted"}, Err: context.Canceled},
+		{Repo: model.Repo{Slug: "bad"}, Err: errors.New("boom")},
+	}
+	if err := worker.FirstError(results); err == nil || err.Error() != "bad: boom" {
+		t.Errorf("expected bad: boom, got %v", err)
+	}
+	if err := worker.FirstError(results[:1]); err != nil {
+		t.Errorf("expected nil without errors, got %v", err)
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for _, s := range []string{"skip", "fail-fast", "retry"} {
+		p, err := worker.ParseErrorPolicy(s)
+		if err != nil || string(p) != s {
+			t.Errorf("ParseErrorPolicy(%q) = %q, %v", s, p, err)
+		}
+	}
+	if _, err := worker.ParseErrorPolicy("ignore"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestRunWithResultsReportsEachCompletion(t *testing.T) {
+	repos := []model.Repo{{Slug: "a"}, {Slug: "b"}, {Slug: "c"}}
+
+	var seen []string
+	results := worker.RunWithResults(context.Background(), repos, 2, func(ctx context.Context, repo model.Repo) (*model.RepoStats, error) {
+		if repo.Slug == "b" {
+			return nil, errors.New("boom")
+		}
+		return &model.RepoStats{Repository: repo.Slug}, nil
+	}, nil, func(r worker.Result) {
+		seen = append(seen, r.Repo.Slug) // calls are serialized
+	}, worker.Skip)
+
+	if len(seen) != len(results) {
+		t.Fatalf("expected %d callbacks, got %d", len(results), len(seen))
+	}
+	for i, r := range results {
+		if seen[i] != r.Repo.Slug {
+			t.Errorf("callback %d: got %s, want %s (result order)", i, seen[i], r.Repo.Slug)
+		}
+	}
+}